@@ -0,0 +1,170 @@
+package gconfig
+
+import (
+	"fmt"
+	s "strings"
+)
+
+// ActuatorEnv is the document served by the env endpoint of Spring Boot Actuator,
+// /actuator/env, so tooling built for Java services reads Go services unchanged.
+type ActuatorEnv struct {
+	ActiveProfiles  []string `json:"activeProfiles"`
+	DefaultProfiles []string `json:"defaultProfiles"`
+	// PropertySources lists the layers defining keys, from the highest precedence to the
+	// lowest.
+	PropertySources []ActuatorPropertySource `json:"propertySources"`
+}
+
+// ActuatorPropertySource is a layer of the configuration, or one of its files, named as
+// Spring names its property sources, eg: systemEnvironment or
+// "Config resource 'file [application.properties]'".
+type ActuatorPropertySource struct {
+	Name       string                      `json:"name"`
+	Properties map[string]ActuatorProperty `json:"properties"`
+}
+
+// ActuatorProperty is the raw value of a key in a property source and where it's defined.
+type ActuatorProperty struct {
+	Value  string `json:"value"`
+	Origin string `json:"origin,omitempty"`
+}
+
+// ActuatorConfigProps is the document served by the configprops endpoint of Spring Boot
+// Actuator, /actuator/configprops. Keys are grouped into a bean per first segment,
+// standing for the @ConfigurationProperties class the key would bind to in Spring.
+type ActuatorConfigProps struct {
+	Contexts map[string]ActuatorContext `json:"contexts"`
+}
+
+// ActuatorContext holds the configuration property beans of an application context.
+type ActuatorContext struct {
+	Beans    map[string]ActuatorBean `json:"beans"`
+	ParentID *string                 `json:"parentId"`
+}
+
+// ActuatorBean holds the keys under Prefix, nested by segment: Properties holds the
+// effective values and Inputs the value and origin of each.
+type ActuatorBean struct {
+	Prefix     string                 `json:"prefix"`
+	Properties map[string]interface{} `json:"properties"`
+	Inputs     map[string]interface{} `json:"inputs"`
+}
+
+// ActuatorEnv returns the configuration in the shape of the Spring Boot Actuator env
+// endpoint: the raw value of every key in every layer defining it, with sensitive values
+// masked. Origins of properties files read "file [name] - line:1", keys starting their
+// line.
+func (c *GConfig) ActuatorEnv() ActuatorEnv {
+	env := ActuatorEnv{ActiveProfiles: []string{}, DefaultProfiles: []string{"default"}}
+	if c.Profile != "" {
+		env.ActiveProfiles = append(env.ActiveProfiles, c.Profile)
+	}
+
+	prefix := ""
+	if o := c.base().opts; o != nil {
+		prefix = o.envPrefix
+	}
+	vs := c.current()
+	sources := make(map[string]*ActuatorPropertySource)
+	layers := make(map[string]Layer)
+	var order []string
+	for _, k := range c.keys() {
+		for _, d := range vs.definitions(c.prefix+k, c.Profile) {
+			name, origin := actuatorSource(d, prefix)
+			src, ok := sources[name]
+			if !ok {
+				src = &ActuatorPropertySource{Name: name, Properties: make(map[string]ActuatorProperty)}
+				sources[name] = src
+				layers[name] = d.Layer
+				order = append(order, name)
+			}
+			src.Properties[d.Key] = ActuatorProperty{Value: c.base().mask(d.Key, d.Value), Origin: origin}
+		}
+	}
+
+	// sources come in the order keys first use them, sort them by precedence
+	env.PropertySources = make([]ActuatorPropertySource, 0, len(order))
+	for l := LayerSet; l <= LayerDefaults; l++ {
+		for _, name := range order {
+			if layers[name] == l {
+				env.PropertySources = append(env.PropertySources, *sources[name])
+			}
+		}
+	}
+	return env
+}
+
+// actuatorSource returns the Spring name of the property source holding a definition and
+// the origin of its value.
+func actuatorSource(d Definition, envPrefix string) (name, origin string) {
+	switch d.Layer {
+	case LayerSet:
+		return "gconfigOverrides", ""
+	case LayerFlag:
+		return "commandLineArgs", fmt.Sprintf("\"%s\" from property source \"commandLineArgs\"", d.Key)
+	case LayerEnv:
+		return "systemEnvironment", fmt.Sprintf("System Environment Property \"%s\"", envName(envPrefix, d.Key))
+	case LayerSource:
+		return fmt.Sprintf("gconfigSource [%s]", d.Source), ""
+	case LayerDotEnv:
+		return fmt.Sprintf("Config resource 'file [%s]'", d.Source), fmt.Sprintf("file [%s]", d.Source)
+	case LayerProfileFile, LayerDefaultFile:
+		return fmt.Sprintf("Config resource 'file [%s]'", d.Source), fmt.Sprintf("file [%s] - %d:1", d.Source, d.Line)
+	}
+	return "defaultProperties", ""
+}
+
+// ActuatorConfigProps returns the configuration in the shape of the Spring Boot Actuator
+// configprops endpoint, with placeholders resolved and sensitive values masked, including
+// where a value embeds one through a placeholder, in a context named application.
+func (c *GConfig) ActuatorConfigProps() ActuatorConfigProps {
+	prefix := ""
+	if o := c.base().opts; o != nil {
+		prefix = o.envPrefix
+	}
+	beans := make(map[string]ActuatorBean)
+	for _, k := range c.keys() {
+		segments := s.Split(k, ".")
+		bean, ok := beans[segments[0]]
+		if !ok {
+			bean = ActuatorBean{Prefix: segments[0], Properties: make(map[string]interface{}), Inputs: make(map[string]interface{})}
+			beans[segments[0]] = bean
+		}
+
+		//sensitive values embedded through placeholders are scrubbed as in log lines
+		v := c.redact(c.mask(k, c.replaceSysVars(k)))
+		input := map[string]interface{}{"value": v}
+		if o, ok := c.Origin(k); ok {
+			if _, origin := actuatorSource(Definition{Origin: o, Key: k}, prefix); origin != "" {
+				input["origin"] = origin
+			}
+		}
+		rest := segments[1:]
+		if len(rest) == 0 {
+			// a key without dots is its own bean
+			rest = segments
+		}
+		nest(bean.Properties, rest, v)
+		nest(bean.Inputs, rest, input)
+	}
+	return ActuatorConfigProps{Contexts: map[string]ActuatorContext{"application": {Beans: beans}}}
+}
+
+// nest sets the value at the path of segments in m, creating the maps in between. When a
+// segment already holds a value rather than a map, eg: db.pool is set before
+// db.pool.size, the rest of the path is kept as a single dotted name.
+func nest(m map[string]interface{}, segments []string, v interface{}) {
+	for i, seg := range segments[:len(segments)-1] {
+		child, ok := m[seg].(map[string]interface{})
+		if !ok {
+			if _, taken := m[seg]; taken {
+				m[s.Join(segments[i:], ".")] = v
+				return
+			}
+			child = make(map[string]interface{})
+			m[seg] = child
+		}
+		m = child
+	}
+	m[segments[len(segments)-1]] = v
+}
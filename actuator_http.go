@@ -0,0 +1,40 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"path"
+)
+
+// ActuatorHandler returns an http.Handler serving ActuatorEnv and ActuatorConfigProps as
+// JSON on the paths ending in /env and /configprops, the endpoints of Spring Boot
+// Actuator, so dashboards and tooling written for Java services work against Go services
+// unchanged. Other paths are answered with 404 Not Found.
+//
+//	http.Handle("/actuator/", cfg.ActuatorHandler())
+func (c *GConfig) ActuatorHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var doc interface{}
+		switch path.Base(r.URL.Path) {
+		case "env":
+			doc = c.ActuatorEnv()
+		case "configprops":
+			doc = c.ActuatorConfigProps()
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		b, err := json.MarshalIndent(doc, "", "  ")
+		if err != nil {
+			c.logf("Error encoding actuator %s: %s\n", r.URL.Path, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(append(b, '\n')); err != nil {
+			c.logf("Error writing actuator %s: %s\n", r.URL.Path, err)
+		}
+	})
+}
@@ -0,0 +1,42 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestActuatorHandler(t *testing.T) {
+	c, err := NewFromMap(map[string]string{"app.name": "gconfig"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := c.ActuatorHandler()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/actuator/env", nil))
+	var env map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &env); err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{"activeProfiles", "defaultProfiles", "propertySources"} {
+		if _, ok := env[field]; !ok {
+			t.Errorf("Expected the %s field, got %s", field, rec.Body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/actuator/configprops", nil))
+	if !strings.Contains(rec.Body.String(), `"parentId": null`) {
+		t.Errorf("Unexpected configprops %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/actuator/beans", nil))
+	if rec.Code != 404 {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,75 @@
+package gconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestActuatorEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\nserver.port=8080\ndb.password=hunter22\n")
+	writeConfig(t, dir, "application-prod.properties", "app.name=prod\n")
+	os.Setenv("GCACT_SERVER_PORT", "9090")
+	defer os.Unsetenv("GCACT_SERVER_PORT")
+
+	c, err := Load(WithPath(dir), WithProfile("prod"), WithoutFlags(), WithEnvPrefix("GCACT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env := c.ActuatorEnv()
+	if len(env.ActiveProfiles) != 1 || env.ActiveProfiles[0] != "prod" {
+		t.Errorf("Unexpected active profiles %v", env.ActiveProfiles)
+	}
+
+	var names []string
+	for _, src := range env.PropertySources {
+		names = append(names, src.Name)
+	}
+	want := "systemEnvironment,Config resource 'file [application-prod.properties]',Config resource 'file [application.properties]'"
+	if got := strings.Join(names, ","); got != want {
+		t.Fatalf("Expected property sources %s, got %s", want, got)
+	}
+	if p := env.PropertySources[0].Properties["server.port"]; p.Value != "9090" || p.Origin != `System Environment Property "GCACT_SERVER_PORT"` {
+		t.Errorf("Unexpected environment property %+v", p)
+	}
+	if p := env.PropertySources[2].Properties["app.name"]; p.Value != "gconfig" || p.Origin != "file [application.properties] - 1:1" {
+		t.Errorf("Unexpected file property %+v", p)
+	}
+	if p := env.PropertySources[2].Properties["db.password"]; p.Value != MaskedValue {
+		t.Errorf("Expected the password to be masked, got %q", p.Value)
+	}
+}
+
+func TestActuatorConfigProps(t *testing.T) {
+	c, err := NewFromMap(map[string]string{
+		"db.host":      "localhost",
+		"db.url":       "postgres://${db.host}/app",
+		"db.pool":      "on",
+		"db.pool.size": "10",
+		"db.password":  "hunter22",
+		"db.dsn":       "app:${db.password}@db",
+		"port":         "8080",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	beans := c.ActuatorConfigProps().Contexts["application"].Beans
+	if dsn := beans["db"].Properties["dsn"]; dsn != "app:"+MaskedValue+"@db" {
+		t.Errorf("A secret embedded through a placeholder should be masked but got %v", dsn)
+	}
+
+	db := beans["db"]
+	if db.Prefix != "db" || db.Properties["url"] != "postgres://localhost/app" || db.Properties["password"] != MaskedValue {
+		t.Errorf("Unexpected db bean %+v", db)
+	}
+	if db.Properties["pool"] != "on" || db.Properties["pool.size"] != "10" {
+		t.Errorf("Expected a key and its children to be kept, got %+v", db.Properties)
+	}
+	if input, _ := db.Inputs["host"].(map[string]interface{}); input["value"] != "localhost" {
+		t.Errorf("Unexpected inputs %+v", db.Inputs)
+	}
+	if beans["port"].Properties["port"] != "8080" {
+		t.Errorf("Unexpected port bean %+v", beans["port"])
+	}
+}
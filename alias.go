@@ -0,0 +1,110 @@
+package gconfig
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	deprecationsMu sync.RWMutex
+	// aliasTo maps an old key name to the new one, aliasFrom a new key to its old names
+	aliasTo    = make(map[string]string)
+	aliasFrom  = make(map[string][]string)
+	deprecated = make(map[string]string)
+)
+
+// RegisterAlias keeps the renamed key oldKey working while it's migrated to newKey:
+// reading newKey returns the value of oldKey when a layer defines only the old name, and
+// reading oldKey the value of newKey, or of oldKey when a layer defines only the old
+// name. Files still defining oldKey and code still reading it are warned about once
+// through the logger. It's meant to be called from an init function and panics if oldKey
+// is aliased twice.
+func RegisterAlias(oldKey, newKey string) {
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+	if _, dup := aliasTo[oldKey]; dup {
+		panic("gconfig: RegisterAlias called twice for key " + oldKey)
+	}
+	aliasTo[oldKey] = newKey
+	aliasFrom[newKey] = append(aliasFrom[newKey], oldKey)
+	atomic.AddUint64(&aliasGeneration, 1)
+}
+
+// Deprecate marks key as deprecated: a configuration defining it, or code reading it, is
+// warned about once through the logger, with message explaining what to do instead.
+func Deprecate(key, message string) {
+	deprecationsMu.Lock()
+	defer deprecationsMu.Unlock()
+	deprecated[key] = message
+}
+
+// aliasesOf returns the other names of key, the new one first.
+func aliasesOf(key string) []string {
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+	if len(aliasTo) == 0 {
+		return nil
+	}
+	var alts []string
+	if nk, ok := aliasTo[key]; ok {
+		alts = append(alts, nk)
+	}
+	return append(alts, aliasFrom[key]...)
+}
+
+// lookupNames returns the names key is looked up under in each layer, in order: key then
+// its old names, or for a renamed key its new name first, so a layer defining both
+// names serves the new one whichever is read.
+func lookupNames(key string) []string {
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+	if nk, ok := aliasTo[key]; ok {
+		return append([]string{nk, key}, aliasFrom[key]...)
+	}
+	return append([]string{key}, aliasFrom[key]...)
+}
+
+// findAlias looks the names of a key, see lookupNames, up in m as findKey does, so a layer
+// defining a key under another name wins over the lower layers.
+func findAlias[V any](m map[string]V, names []string, normalize bool) (string, bool) {
+	for _, name := range names {
+		if k, ok := findKey(m, name, normalize); ok {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// deprecation returns the warning about the absolute key, if it's deprecated.
+func deprecation(key string) (string, bool) {
+	deprecationsMu.RLock()
+	defer deprecationsMu.RUnlock()
+	if nk, ok := aliasTo[key]; ok {
+		return "renamed to " + nk, true
+	}
+	msg, ok := deprecated[key]
+	return msg, ok
+}
+
+// warnDeprecated warns once about the deprecated keys the configuration defines.
+func (c *GConfig) warnDeprecated() {
+	for _, k := range c.keys() {
+		if msg, ok := deprecation(c.prefix + k); ok {
+			c.warnOnce(c.prefix+k, "Configuration key %s is deprecated: %s\n", c.prefix+k, msg)
+		}
+	}
+}
+
+// warnDeprecatedRead warns once about code reading a deprecated key.
+func (c *GConfig) warnDeprecatedRead(key string) {
+	if msg, ok := deprecation(c.prefix + key); ok {
+		c.warnOnce("read "+c.prefix+key, "Configuration key %s is read but deprecated: %s\n", c.prefix+key, msg)
+	}
+}
+
+// warnOnce logs the warning identified by id the first time only.
+func (c *GConfig) warnOnce(id, format string, v ...interface{}) {
+	if _, done := c.base().warned.LoadOrStore(id, true); !done {
+		c.logf(format, v...)
+	}
+}
@@ -0,0 +1,79 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func withDeprecations(t *testing.T) {
+	deprecationsMu.Lock()
+	savedTo, savedFrom, savedDeprecated := aliasTo, aliasFrom, deprecated
+	aliasTo, aliasFrom, deprecated = make(map[string]string), make(map[string][]string), make(map[string]string)
+	deprecationsMu.Unlock()
+	t.Cleanup(func() {
+		deprecationsMu.Lock()
+		aliasTo, aliasFrom, deprecated = savedTo, savedFrom, savedDeprecated
+		deprecationsMu.Unlock()
+	})
+}
+
+func TestRegisterAlias(t *testing.T) {
+	withDeprecations(t)
+	RegisterAlias("db.pool", "db.pool-size")
+	RegisterAlias("cache.expiry", "cache.ttl")
+	Deprecate("app.legacy", "use app.mode instead")
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.pool=10\ncache.expiry=1m\ncache.ttl=5m\napp.legacy=true\n")
+	l := new(recordingLogger)
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv(), WithLogger(l),
+		WithDefaults(map[string]string{"db.pool-size": "4"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p := gcg.GetInt("db.pool-size"); p != 10 {
+		t.Errorf("The old key in the files should win over the default of the new one but got %d", p)
+	}
+	if ttl := gcg.GetString("cache.expiry"); ttl != "5m" {
+		t.Errorf("Reading the old key should return the new one when both are defined but got %s", ttl)
+	}
+	if d := gcg.Explain("cache.expiry").Definitions; len(d) == 0 || d[0].Key != "cache.ttl" {
+		t.Errorf("The old key should resolve to the new one but is defined by %+v", d)
+	}
+	if ttl := gcg.GetString("cache.ttl"); ttl != "5m" {
+		t.Errorf("The new key should win when both are defined but got %s", ttl)
+	}
+	gcg.Set("db.pool-size", "20")
+	if p := gcg.Sub("db").GetInt("pool"); p != 20 {
+		t.Errorf("Reading the old key should fall back to the new one but got %d", p)
+	}
+	gcg.GetString("app.legacy")
+	gcg.GetString("app.legacy")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	out := strings.Join(l.lines, "")
+	for _, w := range []string{
+		"Configuration key db.pool is deprecated: renamed to db.pool-size",
+		"Configuration key app.legacy is deprecated: use app.mode instead",
+		"Configuration key app.legacy is read but deprecated: use app.mode instead",
+		"Configuration key db.pool is read but deprecated: renamed to db.pool-size",
+	} {
+		if strings.Count(out, w) != 1 {
+			t.Errorf("Expected the warning %q once in:\n%s", w, out)
+		}
+	}
+}
+
+func TestRegisterAliasTwice(t *testing.T) {
+	withDeprecations(t)
+	RegisterAlias("a", "b")
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterAlias should panic when a key is aliased twice")
+		}
+	}()
+	RegisterAlias("a", "c")
+}
@@ -0,0 +1,60 @@
+package gconfig
+
+import s "strings"
+
+// standardBaseName is the base name of the properties files shared by every
+// application: application.properties and application-{profile}.properties.
+const standardBaseName = "application"
+
+// WithAppName reads the files of the application name alongside the shared ones, so the
+// binaries of a monorepo keep their configuration in one directory without colliding on
+// application.properties: with WithAppName("billing") the default layer is
+// application.properties then billing.properties, and profile prod reads
+// application-prod.properties then billing-prod.properties, the files of the application
+// winning over the shared ones of the same level. Hierarchical profiles interleave them
+// level by level, eg: application-prod, billing-prod, application-prod-eu then
+// billing-prod-eu, and so do ProfileFragments and drop-in directories. The name also
+// replaces the program name in the default search paths, eg: /etc/billing.
+func WithAppName(name string) Option {
+	return func(o *options) {
+		o.appName = name
+	}
+}
+
+// configBases returns the base names of the properties files read, from the lowest
+// precedence to the highest.
+func (o *options) configBases() []string {
+	if o == nil || o.appName == "" || o.appName == standardBaseName {
+		return []string{standardBaseName}
+	}
+	return []string{standardBaseName, o.appName}
+}
+
+// ownFile reports whether the file at the slash separated path rel, matching a pattern
+// set with WithPath, belongs to the application: without an application name every file
+// does, and with one only the files named after a base name, eg: billing.properties or
+// application-prod.properties, or held by a directory named after one, eg:
+// billing.properties.d/10-db.properties, so the files of the other applications sharing
+// the directory are left out.
+func (o *options) ownFile(rel string) bool {
+	if o.appName == "" {
+		return true
+	}
+	for _, seg := range s.Split(rel, "/") {
+		for _, base := range o.configBases() {
+			if seg == base || s.HasPrefix(seg, base+".") || s.HasPrefix(seg, base+"-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defaultFiles returns the files of the default layer of bases.
+func defaultFiles(bases []string) []string {
+	names := make([]string, len(bases))
+	for i, base := range bases {
+		names[i] = base + PropertiesExtension
+	}
+	return names
+}
@@ -0,0 +1,159 @@
+package gconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	s "strings"
+	"testing"
+)
+
+func TestAppName(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "application.properties", "app.region=eu\napp.name=shared\ndb.pool=5\n")
+	writeConfig(t, dir, "application-prod.properties", "db.pool=10\nlog.level=warn\n")
+	writeConfig(t, dir, "billing.properties", "app.name=billing\ndb.pool=2\n")
+	writeConfig(t, dir, "billing-prod.properties", "log.level=error\n")
+	writeConfig(t, dir, "orders.properties", "app.name=orders\norders.only=true\n")
+
+	c, err := Load(WithPath(dir), WithProfile("prod"), WithAppName("billing"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"app.region": "eu",
+		"app.name":   "billing",
+		// profile files win over the defaults of the application
+		"db.pool":   "10",
+		"log.level": "error",
+	} {
+		if got := c.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if c.Exists("orders.only") {
+		t.Error("read the configuration of another application")
+	}
+	if o, _ := c.Origin("log.level"); o.Source != "billing-prod.properties" {
+		t.Errorf("log.level comes from %q", o.Source)
+	}
+
+	c, err = Load(WithPath(dir), WithAppName("orders"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("app.name"); got != "orders" {
+		t.Errorf("app.name = %q, want orders", got)
+	}
+	if c.GetString("db.pool") != "5" {
+		t.Errorf("db.pool = %q, want the shared 5", c.GetString("db.pool"))
+	}
+}
+
+func TestAppNameProfiles(t *testing.T) {
+	bases := []string{"application", "billing"}
+	want := []string{
+		"application-prod.properties", "billing-prod.properties",
+		"application-prod-eu.properties", "billing-prod-eu.properties",
+	}
+	if got := profileFiles(bases, "prod-eu"); !reflect.DeepEqual(got, want) {
+		t.Errorf("profileFiles = %v, want %v", got, want)
+	}
+
+	dir := t.TempDir()
+	writeConfig(t, dir, "application.properties", "a=default\n")
+	writeConfig(t, dir, "application-prod-eu.properties", "a=shared-eu\nb=shared-eu\n")
+	writeConfig(t, dir, "billing-prod.properties", "a=billing\nb=billing\nc=billing\n")
+	c, err := Load(WithPath(dir), WithProfile("prod-eu"), WithAppName("billing"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the more specific level wins, whichever file holds it
+	for key, want := range map[string]string{"a": "shared-eu", "b": "shared-eu", "c": "billing"} {
+		if got := c.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestAppNameGlob(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "accounts.properties", "name=accounts\n")
+	writeConfig(t, dir, "application.properties", "name=shared\n")
+	writeConfig(t, dir, "accounts-prod.properties", "level=prod\n")
+	writeConfig(t, dir, "application-dev.properties", "level=dev\n")
+
+	c, err := Load(WithPath(filepath.Join(dir, "*.properties")), WithProfile("prod"), WithAppName("accounts"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetString("name"); got != "accounts" {
+		t.Errorf("name = %q, want accounts", got)
+	}
+	if got := c.GetString("level"); got != "prod" {
+		t.Errorf("level = %q, want prod", got)
+	}
+}
+
+func TestAppNameMissingProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "billing.properties", "a=1\n")
+	r, err := LoadDetailed(WithPath(dir), WithProfile("qa"), WithAppName("billing"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, w := range r.Warnings {
+		if w.Kind == WarningMissingProfile {
+			if !s.Contains(w.Message, "billing-qa.properties") {
+				t.Errorf("warning %q doesn't name the application file", w.Message)
+			}
+			return
+		}
+	}
+	t.Error("missing profile not reported")
+}
+
+func TestAppNameSearchPaths(t *testing.T) {
+	dirs := defaultSearchPaths("billing")
+	if last := dirs[len(dirs)-1]; filepath.Base(last) != "billing" {
+		t.Errorf("search paths %v don't use the application name", dirs)
+	}
+}
+
+func TestAppNameGlobSharedDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "application.properties", "name=shared\n")
+	writeConfig(t, dir, "billing.properties", "name=billing\n")
+	writeConfig(t, dir, "orders.properties", "name=orders\norders.only=true\n")
+	writeConfig(t, dir, "orders-prod.properties", "orders.prod=true\n")
+	if err := os.Mkdir(filepath.Join(dir, "billing.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(dir, "billing.d"), "10-db.properties", "db.pool=3\n")
+
+	c, err := Load(WithPath(filepath.Join(dir, "**", "*.properties")), WithProfile("prod"), WithAppName("billing"), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Exists("orders.only") || c.Exists("orders.prod") {
+		t.Errorf("read the files of another application: %v", c.AllSettings())
+	}
+	if c.GetString("db.pool") != "3" {
+		t.Error("the files of a directory named after the application should be read")
+	}
+}
+
+func TestAppNameRequiredEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, "application.properties", RequiresEnvKey+"=GC_TEST_HOME\n")
+	writeConfig(t, dir, "billing-prod.properties", RequiresEnvKey+"=GC_TEST_BILLING_KEY\n")
+	writeConfig(t, dir, "orders-prod.properties", RequiresEnvKey+"=GC_TEST_ORDERS_KEY\n")
+
+	names, err := RequiredEnvInDir(dir, "prod", WithAppName("billing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GC_TEST_HOME", "GC_TEST_BILLING_KEY"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("RequiredEnvInDir = %v, want %v", names, want)
+	}
+}
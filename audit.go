@@ -0,0 +1,180 @@
+package gconfig
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Operations recorded in the audit log.
+const (
+	AuditLoad     = "load"
+	AuditReload   = "reload"
+	AuditSet      = "set"
+	AuditFallback = "fallback" // load from the last known good snapshot
+)
+
+// AuditChange is the change of a single key in an AuditEntry. Values of sensitive keys
+// are masked.
+type AuditChange struct {
+	Key string `json:"key"`
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// AuditEntry records one configuration operation. Entries are chained: Prev holds the
+// Hash of the previous entry and Hash the SHA-256 of the entry itself, computed with an
+// empty Hash, so editing or removing an entry breaks the chain, see VerifyAuditLog.
+type AuditEntry struct {
+	Seq     int64         `json:"seq"`
+	Time    time.Time     `json:"time"`
+	Op      string        `json:"op"`
+	Actor   string        `json:"actor"`
+	Profile string        `json:"profile"`
+	Changes []AuditChange `json:"changes,omitempty"`
+	Prev    string        `json:"prev"`
+	Hash    string        `json:"hash"`
+}
+
+func (e AuditEntry) hash() string {
+	e.Hash = ""
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditLog appends hash-chained AuditEntry values, one JSON object per line, to a sink.
+// It's safe for concurrent use and can be shared between configurations.
+type AuditLog struct {
+	// Actor identifies who performs the operations, user@host by default.
+	Actor string
+
+	mu   sync.Mutex
+	w    io.Writer
+	seq  int64
+	prev string
+}
+
+// NewAuditLog returns an AuditLog starting a new chain on w.
+func NewAuditLog(w io.Writer) *AuditLog {
+	return &AuditLog{Actor: defaultActor(), w: w}
+}
+
+// OpenAuditLog opens, or creates, the audit log file at path for appending, continuing
+// the chain of the entries already in it. The chain is verified first.
+func OpenAuditLog(path string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error opening audit log %s", path)
+	}
+	l := NewAuditLog(f)
+	last, err := verifyAuditLog(f)
+	if err != nil {
+		f.Close()
+		return nil, errors.Wrapf(err, "Audit log %s is corrupt", path)
+	}
+	l.seq, l.prev = last.Seq, last.Hash
+	return l, nil
+}
+
+// WithAudit records the load and every later Reload and Set of the configuration in log.
+func WithAudit(log *AuditLog) Option {
+	return func(o *options) {
+		o.audit = log
+	}
+}
+
+// Append adds an entry for op to the log, filling in its sequence, time, actor and hashes.
+func (l *AuditLog) Append(op, profile string, changes []AuditChange) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := AuditEntry{
+		Seq:     l.seq + 1,
+		Time:    time.Now().UTC(),
+		Op:      op,
+		Actor:   l.Actor,
+		Profile: profile,
+		Changes: changes,
+		Prev:    l.prev,
+	}
+	e.Hash = e.hash()
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := l.w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	l.seq, l.prev = e.Seq, e.Hash
+	return nil
+}
+
+// Close closes the sink if it's an io.Closer, such as the file opened by OpenAuditLog.
+func (l *AuditLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if c, ok := l.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// VerifyAuditLog checks the hash chain of the entries read from r and returns how many
+// there are. It fails on the first entry that was modified, removed or reordered.
+func VerifyAuditLog(r io.Reader) (int, error) {
+	last, err := verifyAuditLog(r)
+	return int(last.Seq), err
+}
+
+func verifyAuditLog(r io.Reader) (AuditEntry, error) {
+	var last AuditEntry
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		var e AuditEntry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return last, fmt.Errorf("entry %d is not valid JSON: %s", last.Seq+1, err)
+		}
+		if e.Seq != last.Seq+1 || e.Prev != last.Hash {
+			return last, fmt.Errorf("entry %d doesn't follow entry %d", e.Seq, last.Seq)
+		}
+		if e.hash() != e.Hash {
+			return last, fmt.Errorf("entry %d was modified", e.Seq)
+		}
+		last = e
+	}
+	return last, sc.Err()
+}
+
+// audit records the changes published by op in the audit log, if any.
+func (c *GConfig) audit(op string, events []Event) {
+	if c.opts == nil || c.opts.audit == nil {
+		return
+	}
+	changes := make([]AuditChange, len(events))
+	for i, e := range events {
+		changes[i] = AuditChange{Key: e.Key, Old: c.mask(e.Key, e.Old), New: c.mask(e.Key, e.New)}
+	}
+	if err := c.opts.audit.Append(op, c.Profile, changes); err != nil {
+		c.logf("Error writing audit log: %s\n", err)
+	}
+}
+
+func defaultActor() string {
+	name := os.Getenv("USER")
+	if u, err := user.Current(); err == nil {
+		name = u.Username
+	}
+	host, _ := os.Hostname()
+	return name + "@" + host
+}
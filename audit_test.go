@@ -0,0 +1,79 @@
+package gconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditLog(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.password=hunter22\n")
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	log, err := OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Actor = "deployer@ci"
+	gcg, err := Load(WithPath(dir), WithAudit(log))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg.Set("app.name", "tuned")
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.password=hunter23\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	//reopening continues the chain
+	log.Close()
+	log, err = OpenAuditLog(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Append(AuditSet, "", nil)
+	log.Close()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(content, []byte("hunter2")) {
+		t.Error("Sensitive values should be masked in the audit log")
+	}
+	n, err := VerifyAuditLog(bytes.NewReader(content))
+	if err != nil || n != 4 {
+		t.Fatalf("Expected a valid chain of 4 entries but got %d, %v", n, err)
+	}
+
+	var entries []AuditEntry
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		var e AuditEntry
+		json.Unmarshal(sc.Bytes(), &e)
+		entries = append(entries, e)
+	}
+	if entries[0].Op != AuditLoad || len(entries[0].Changes) != 2 || entries[0].Actor != "deployer@ci" {
+		t.Errorf("Unexpected load entry %+v", entries[0])
+	}
+	if c := entries[1].Changes; entries[1].Op != AuditSet || len(c) != 1 || c[0].Old != "gconfig" || c[0].New != "tuned" {
+		t.Errorf("Unexpected set entry %+v", entries[1])
+	}
+	if c := entries[2].Changes; entries[2].Op != AuditReload || len(c) != 1 || c[0].Key != "db.password" || c[0].New != MaskedValue {
+		t.Errorf("Unexpected reload entry %+v", entries[2])
+	}
+
+	tampered := strings.Replace(string(content), `"new":"tuned"`, `"new":"other"`, 1)
+	if _, err := VerifyAuditLog(strings.NewReader(tampered)); err == nil {
+		t.Error("Modified entries should break the chain")
+	}
+	lines := strings.SplitAfter(string(content), "\n")
+	if _, err := VerifyAuditLog(strings.NewReader(lines[0] + lines[2])); err == nil {
+		t.Error("Removed entries should break the chain")
+	}
+}
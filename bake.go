@@ -0,0 +1,25 @@
+package gconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Bake writes the effective configuration as a single flat properties file: the
+// profile values are layered over the defaults and every placeholder is resolved
+// against the environment. When keepPlaceholders is true the raw ${...} references
+// are written as-is instead, so secrets injected through the environment at runtime
+// are never frozen into the artifact.
+func (c *GConfig) Bake(w io.Writer, keepPlaceholders bool) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# baked by gconfig for profile '%s'\n", c.Profile)
+	for _, k := range c.keys() {
+		v, _ := c.getValue(k).(string)
+		if !keepPlaceholders {
+			v = c.replaceSysVars(k)
+		}
+		writeProperty(bw, k, v)
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,45 @@
+package gconfig
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestBake(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Setenv("CAPI_API_KEY", "baked-key")
+	defer os.Unsetenv("CAPI_API_KEY")
+
+	gcg, err := Load(WithPath(wd+"/config"), WithProfile("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := gcg.Bake(&buf, false); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, line := range []string{
+		"app.name=gconfig dev profile\n",
+		"app.version=1.0\n",
+		"myEnv.variable.withDefault=baked-key\n",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("Baked output is missing %q:\n%s", line, out)
+		}
+	}
+
+	buf.Reset()
+	if err := gcg.Bake(&buf, true); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "myEnv.variable.withDefault=${CAPI_API_KEY|default}\n") {
+		t.Errorf("Baked output should keep placeholders:\n%s", buf.String())
+	}
+}
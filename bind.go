@@ -0,0 +1,196 @@
+package gconfig
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Bindable lists the types of the variables a key can be bound to.
+type Bindable interface {
+	string | int | int64 | bool | float64 | time.Duration
+}
+
+// binding keeps a variable set from a key of the global configuration.
+type binding struct {
+	key string
+	// set stores the value of key in c, or the variable's default when it's missing,
+	// and reset stores the default
+	set   func(c *GConfig) error
+	reset func()
+	// unsubscribe cancels the subscription to the current global configuration
+	unsubscribe func()
+}
+
+var (
+	bindingsMu sync.Mutex
+	bindings   []*binding
+)
+
+// BindString sets *p to the value of key in the global configuration whenever Load or
+// SetGlobal installs one, and again every time Set, Reload or WatchSources change it, so
+// components get hot reloaded settings without calling Get* themselves:
+//
+//	var name = "anonymous"
+//
+//	func init() { gconfig.BindString(&name, "app.name") }
+//
+// The value *p holds when it's bound is kept while the key isn't defined, and restored
+// when the key is removed. The variable is written on the goroutine changing the
+// configuration: bind variables read concurrently with BindAtomic instead. Without a
+// global configuration, eg: after SetGlobal(nil), the variable holds its default.
+//
+// The returned function releases the binding, leaving *p as it is, so a binding to a
+// variable that doesn't live as long as the program doesn't keep it alive.
+func BindString(p *string, key string) (cancel func()) {
+	return bindVar(p, key)
+}
+
+// BindInt is BindString for an int key. A value that doesn't parse is logged and the
+// variable keeps its value.
+func BindInt(p *int, key string) (cancel func()) {
+	return bindVar(p, key)
+}
+
+// BindBool is BindString for a bool key, see BindInt.
+func BindBool(p *bool, key string) (cancel func()) {
+	return bindVar(p, key)
+}
+
+// BindFloat is BindString for a float key, see BindInt.
+func BindFloat(p *float64, key string) (cancel func()) {
+	return bindVar(p, key)
+}
+
+// BindDuration is BindString for a duration key, eg: 1.5s, see BindInt.
+func BindDuration(p *time.Duration, key string) (cancel func()) {
+	return bindVar(p, key)
+}
+
+func bindVar[T Bindable](p *T, key string) func() {
+	def := *p
+	return bind(key, def, func(v T) { *p = v })
+}
+
+// Atomic holds a value bound to a key with BindAtomic, safe to read from any goroutine
+// while the configuration changes. The zero value holds the zero value of T.
+type Atomic[T Bindable] struct {
+	v atomic.Value
+}
+
+// NewAtomic returns an Atomic holding v, the default kept while its key isn't defined.
+func NewAtomic[T Bindable](v T) *Atomic[T] {
+	a := new(Atomic[T])
+	a.v.Store(v)
+	return a
+}
+
+// Load returns the current value.
+func (a *Atomic[T]) Load() T {
+	v, _ := a.v.Load().(T)
+	return v
+}
+
+// BindAtomic is BindString for an Atomic, eg:
+//
+//	var timeout = gconfig.NewAtomic(5 * time.Second)
+//
+//	func init() { gconfig.BindAtomic(timeout, "http.timeout") }
+//
+//	ctx, cancel := context.WithTimeout(ctx, timeout.Load())
+func BindAtomic[T Bindable](a *Atomic[T], key string) (cancel func()) {
+	return bind(key, a.Load(), func(v T) { a.v.Store(v) })
+}
+
+// bind registers the binding of key, storing its value with store, and applies it to
+// the global configuration if there is one. It returns the function releasing it.
+func bind[T Bindable](key string, def T, store func(T)) func() {
+	b := &binding{key: key, reset: func() { store(def) }, set: func(c *GConfig) error {
+		if !c.Exists(key) {
+			store(def)
+			return nil
+		}
+		v, err := parseBindable[T](c.GetString(key))
+		if err != nil {
+			return err
+		}
+		store(v)
+		return nil
+	}}
+
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	bindings = append(bindings, b)
+	if c, err := TryGlobal(); err == nil {
+		b.attach(c)
+	}
+	return func() {
+		bindingsMu.Lock()
+		defer bindingsMu.Unlock()
+		for i, other := range bindings {
+			if other == b {
+				bindings = append(bindings[:i], bindings[i+1:]...)
+				b.detach()
+				return
+			}
+		}
+	}
+}
+
+// attach sets the variable of b from c and keeps it up to date until detach is called.
+func (b *binding) attach(c *GConfig) {
+	if err := b.set(c); err != nil {
+		c.logf("Error binding key %s: %s\n", b.key, err)
+	}
+	b.unsubscribe = c.Subscribe(b.key, func(Event) {
+		if err := b.set(c); err != nil {
+			c.logf("Keeping the bound value of %s, the new one is invalid: %s\n", b.key, err)
+		}
+	})
+}
+
+// detach stops following the configuration b is attached to, if any.
+func (b *binding) detach() {
+	if b.unsubscribe != nil {
+		b.unsubscribe()
+		b.unsubscribe = nil
+	}
+}
+
+// rebind moves every binding to the current global configuration, or restores the
+// defaults of the variables when there is none.
+func rebind() {
+	bindingsMu.Lock()
+	defer bindingsMu.Unlock()
+	c, err := TryGlobal()
+	for _, b := range bindings {
+		b.detach()
+		if err != nil {
+			b.reset()
+			continue
+		}
+		b.attach(c)
+	}
+}
+
+// parseBindable parses s as a T.
+func parseBindable[T Bindable](s string) (T, error) {
+	var v T
+	var err error
+	switch p := any(&v).(type) {
+	case *string:
+		*p = s
+	case *int:
+		*p, err = strconv.Atoi(s)
+	case *int64:
+		*p, err = strconv.ParseInt(s, 10, 64)
+	case *bool:
+		*p, err = strconv.ParseBool(s)
+	case *float64:
+		*p, err = strconv.ParseFloat(s, 64)
+	case *time.Duration:
+		*p, err = time.ParseDuration(s)
+	}
+	return v, err
+}
@@ -0,0 +1,130 @@
+package gconfig
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBind(t *testing.T) {
+	prev, _ := TryGlobal()
+	defer SetGlobal(prev)
+	SetGlobal(nil)
+
+	name, port, debug, ratio := "anonymous", 8080, false, 0.5
+	timeout := 5 * time.Second
+	for _, cancel := range []func(){
+		BindString(&name, "gctest.bind.name"),
+		BindInt(&port, "gctest.bind.port"),
+		BindBool(&debug, "gctest.bind.debug"),
+		BindFloat(&ratio, "gctest.bind.ratio"),
+		BindDuration(&timeout, "gctest.bind.timeout"),
+	} {
+		defer cancel()
+	}
+	if name != "anonymous" || port != 8080 {
+		t.Fatal("Bound variables should keep their value until a configuration is loaded")
+	}
+
+	c, err := NewFromMap(map[string]string{
+		"gctest.bind.host":    "db",
+		"gctest.bind.name":    "app-${gctest.bind.host}",
+		"gctest.bind.port":    "9090",
+		"gctest.bind.debug":   "true",
+		"gctest.bind.timeout": "1.5s",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetGlobal(c)
+	if name != "app-db" || port != 9090 || !debug || ratio != 0.5 || timeout != 1500*time.Millisecond {
+		t.Fatalf("Unexpected bound values %s %d %t %v %s", name, port, debug, ratio, timeout)
+	}
+
+	c.Set("gctest.bind.port", "9191")
+	c.Set("gctest.bind.host", "replica")
+	if port != 9191 || name != "app-replica" {
+		t.Errorf("Bound variables should follow changes, got %d and %s", port, name)
+	}
+	c.Set("gctest.bind.port", "not a port")
+	if port != 9191 {
+		t.Errorf("An invalid value should be ignored, got %d", port)
+	}
+	c.Set("gctest.bind.ratio", "0.9")
+	if ratio != 0.9 {
+		t.Errorf("Expected the value set, got %v", ratio)
+	}
+	c.Unset("gctest.bind.ratio")
+	if ratio != 0.5 {
+		t.Errorf("A removed key should restore the default, got %v", ratio)
+	}
+
+	// the bindings follow the global configuration
+	other, _ := NewFromMap(map[string]string{"gctest.bind.port": "7070"}, "")
+	SetGlobal(other)
+	c.Set("gctest.bind.port", "9292")
+	if port != 7070 || name != "anonymous" {
+		t.Errorf("Expected the values of the new global configuration, got %d and %s", port, name)
+	}
+
+	SetGlobal(nil)
+	if port != 8080 || timeout != 5*time.Second || debug {
+		t.Errorf("Without a global configuration the defaults should be restored, got %d %s %t", port, timeout, debug)
+	}
+}
+
+func TestBindCancel(t *testing.T) {
+	prev, _ := TryGlobal()
+	defer SetGlobal(prev)
+
+	c, err := NewFromMap(map[string]string{"gctest.cancel.port": "9090"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetGlobal(c)
+	port := 8080
+	cancel := BindInt(&port, "gctest.cancel.port")
+	if port != 9090 {
+		t.Fatalf("Expected the bound value, got %d", port)
+	}
+	cancel()
+	cancel()
+	c.Set("gctest.cancel.port", "9191")
+	SetGlobal(nil)
+	if port != 9090 {
+		t.Errorf("A released binding shouldn't change the variable, got %d", port)
+	}
+}
+
+func TestBindAtomic(t *testing.T) {
+	prev, _ := TryGlobal()
+	defer SetGlobal(prev)
+
+	c, err := NewFromMap(map[string]string{"gctest.atomic.timeout": "2s"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetGlobal(c)
+	timeout := NewAtomic(5 * time.Second)
+	defer BindAtomic(timeout, "gctest.atomic.timeout")()
+	var workers Atomic[int]
+	defer BindAtomic(&workers, "gctest.atomic.workers")()
+	if timeout.Load() != 2*time.Second || workers.Load() != 0 {
+		t.Fatalf("Unexpected bound values %s %d", timeout.Load(), workers.Load())
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = timeout.Load() + time.Duration(workers.Load())
+		}
+	}()
+	c.Set("gctest.atomic.timeout", "3s")
+	c.Set("gctest.atomic.workers", "4")
+	wg.Wait()
+	if timeout.Load() != 3*time.Second || workers.Load() != 4 {
+		t.Errorf("Unexpected bound values %s %d", timeout.Load(), workers.Load())
+	}
+}
@@ -0,0 +1,182 @@
+package gconfig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	s "strings"
+
+	"github.com/pkg/errors"
+)
+
+// SignatureExtension is appended to the path of a bundle to find its detached signature,
+// eg: config.zip.sig.
+const SignatureExtension = ".sig"
+
+// ErrInvalidSignature is returned by Load when the signature of a bundle doesn't verify
+// against the key set with WithVerificationKey.
+var ErrInvalidSignature = errors.New("gconfig: invalid bundle signature")
+
+// WithBundle reads the properties files from a single bundle file instead of a
+// directory: a zip, tar or gzipped tar archive, so the configuration can be distributed
+// through channels the application doesn't trust, eg: an artifact store or a shared
+// bucket. With WithVerificationKey its detached signature, at path+".sig", is checked
+// before anything is read, and with WithDecryptionKey the bundle is decrypted first. The
+// properties files are read from the root of the archive, and files in its sub
+// directories when imported. The bundle is read again on every Reload.
+func WithBundle(path string) Option {
+	return func(o *options) {
+		o.bundle = path
+	}
+}
+
+// WithVerificationKey makes Load fail unless the bundle, as distributed, has a detached
+// ed25519 signature by the private key of pub, see SignBundle. The signature file holds
+// the 64 bytes of the signature, raw or base64 encoded. Verification fails closed: Load
+// returns ErrInvalidSignature when pub isn't a valid key or no bundle is set with
+// WithBundle, rather than reading unsigned files.
+func WithVerificationKey(pub ed25519.PublicKey) Option {
+	return func(o *options) {
+		o.verify = true
+		o.verificationKey = pub
+	}
+}
+
+// WithDecryptionKey decrypts the bundle with AES-GCM under key, 16, 24 or 32 bytes long,
+// see EncryptBundle. A bundle that is both signed and encrypted is verified first.
+func WithDecryptionKey(key []byte) Option {
+	return func(o *options) {
+		o.decryptionKey = key
+	}
+}
+
+// SignBundle returns the detached signature of a bundle, to store next to it with the
+// SignatureExtension.
+func SignBundle(bundle []byte, priv ed25519.PrivateKey) []byte {
+	return ed25519.Sign(priv, bundle)
+}
+
+// EncryptBundle encrypts a bundle with AES-GCM under key, for WithDecryptionKey. The
+// random nonce is prepended to the result.
+func EncryptBundle(bundle, key []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, bundle, nil), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "Invalid bundle key")
+	}
+	return cipher.NewGCM(block)
+}
+
+// checkVerifiable fails when a signature is required but there is no bundle to verify.
+func (o *options) checkVerifiable() error {
+	if o.verify && o.bundle == "" {
+		return errors.Wrap(ErrInvalidSignature, "A verification key is set but no bundle, see WithBundle")
+	}
+	return nil
+}
+
+// withBundle returns a copy of o reading the files from the verified and decrypted
+// bundle of o.
+func (o *options) withBundle() (*options, error) {
+	data, err := os.ReadFile(o.bundle)
+	if err != nil {
+		return nil, errors.Wrapf(fileError(err), "Error reading config bundle %s", o.bundle)
+	}
+
+	if o.verify {
+		if len(o.verificationKey) != ed25519.PublicKeySize {
+			return nil, errors.Wrapf(ErrInvalidSignature, "Invalid verification key of %d bytes", len(o.verificationKey))
+		}
+		sig, err := os.ReadFile(o.bundle + SignatureExtension)
+		if err != nil {
+			return nil, errors.Wrapf(fileError(err), "Error reading the signature of config bundle %s", o.bundle)
+		}
+		if len(sig) != ed25519.SignatureSize {
+			if sig, err = base64.StdEncoding.DecodeString(s.TrimSpace(string(sig))); err != nil {
+				return nil, errors.Wrapf(ErrInvalidSignature, "Malformed signature of config bundle %s", o.bundle)
+			}
+		}
+		if !ed25519.Verify(o.verificationKey, data, sig) {
+			return nil, errors.Wrapf(ErrInvalidSignature, "Config bundle %s", o.bundle)
+		}
+	}
+
+	if o.decryptionKey != nil {
+		gcm, err := newGCM(o.decryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		n := gcm.NonceSize()
+		if len(data) < n {
+			return nil, errors.Errorf("Config bundle %s is too short to be encrypted", o.bundle)
+		}
+		if data, err = gcm.Open(nil, data[:n], data[n:], nil); err != nil {
+			return nil, errors.Wrapf(err, "Error decrypting config bundle %s", o.bundle)
+		}
+	}
+
+	fsys, err := openArchive(data)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error opening config bundle %s", o.bundle)
+	}
+	bo := *o
+	bo.fsys, bo.path = fsys, "."
+	return &bo, nil
+}
+
+// openArchive returns the files of a zip, tar or gzipped tar archive.
+func openArchive(data []byte) (fs.FS, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		return zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		r = gz
+	}
+	files := make(memFS)
+	tr := tar.NewReader(r)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		b, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, err
+		}
+		files[path.Clean(s.TrimPrefix(h.Name, "./"))] = b
+	}
+	return files, nil
+}
@@ -0,0 +1,147 @@
+package gconfig
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+var bundleFiles = map[string]string{
+	StandardPropFileName:                        "app.name=bundled\ngconfig.import=shared/db.properties\n",
+	"application-prod.properties":               "app.name=bundled-prod\n",
+	"shared/db.properties":                      "db.host=db.internal\n",
+	"application.properties.d/10-db.properties": "db.port=6432\n",
+}
+
+func zipBundle(t *testing.T) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range bundleFiles {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func tarGzBundle(t *testing.T) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range bundleFiles {
+		tw.WriteHeader(&tar.Header{Name: "./" + name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func writeBundle(t *testing.T, data, sig []byte) string {
+	p := filepath.Join(t.TempDir(), "config.bundle")
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if sig != nil {
+		if err := os.WriteFile(p+SignatureExtension, sig, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func TestBundle(t *testing.T) {
+	for name, data := range map[string][]byte{"zip": zipBundle(t), "tar.gz": tarGzBundle(t)} {
+		p := writeBundle(t, data, nil)
+		c, err := Load(WithBundle(p), WithProfile("prod"), WithoutFlags(), WithoutEnv())
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+		if c.GetString("app.name") != "bundled-prod" || c.GetString("db.host") != "db.internal" || c.GetString("db.port") != "6432" {
+			t.Errorf("%s: unexpected configuration %v", name, c.AllSettings())
+		}
+		if c.Exists("shared/db.properties") {
+			t.Errorf("%s: files of sub directories shouldn't be read unless imported", name)
+		}
+		if err := c.Reload(); err != nil {
+			t.Errorf("%s: reload failed: %s", name, err)
+		}
+	}
+}
+
+func TestSignedEncryptedBundle(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := bytes.Repeat([]byte{7}, 32)
+	sealed, err := EncryptBundle(zipBundle(t), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := SignBundle(sealed, priv)
+
+	p := writeBundle(t, sealed, []byte(base64.StdEncoding.EncodeToString(sig)+"\n"))
+	c, err := Load(WithBundle(p), WithVerificationKey(pub), WithDecryptionKey(key), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.GetString("app.name") != "bundled" {
+		t.Errorf("Unexpected configuration %v", c.AllSettings())
+	}
+
+	if _, err := Load(WithBundle(p), WithVerificationKey(pub), WithDecryptionKey(bytes.Repeat([]byte{8}, 32)), WithoutFlags(), WithoutEnv()); err == nil {
+		t.Error("A wrong decryption key should fail the load")
+	}
+
+	tampered := append([]byte{}, sealed...)
+	tampered[len(tampered)-1] ^= 1
+	p = writeBundle(t, tampered, sig)
+	_, err = Load(WithBundle(p), WithVerificationKey(pub), WithDecryptionKey(key), WithoutFlags(), WithoutEnv())
+	if errors.Cause(err) != ErrInvalidSignature {
+		t.Errorf("Expected ErrInvalidSignature for a tampered bundle, got %v", err)
+	}
+
+	p = writeBundle(t, sealed, nil)
+	if _, err := Load(WithBundle(p), WithVerificationKey(pub), WithDecryptionKey(key), WithoutFlags(), WithoutEnv()); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("Expected a missing signature to fail with ErrFileNotFound, got %v", err)
+	}
+}
+
+func TestVerificationFailsClosed(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := zipBundle(t)
+	p := writeBundle(t, data, SignBundle(data, priv))
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=unsigned\n")
+
+	for name, opts := range map[string][]Option{
+		"short key": {WithBundle(p), WithVerificationKey(pub[:3])},
+		"nil key":   {WithBundle(p), WithVerificationKey(nil)},
+		"no bundle": {WithPath(dir), WithVerificationKey(pub)},
+	} {
+		_, err := Load(append(opts, WithoutFlags(), WithoutEnv())...)
+		if errors.Cause(err) != ErrInvalidSignature {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+		if !permanent(err) {
+			t.Errorf("%s: an unverifiable configuration shouldn't be retried", name)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package gconfig
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TagTunable marks the schema keys WithChaos may perturb.
+const TagTunable = "tunable"
+
+// chaosSource names the values set by WithChaos in Origin and DebugInfo.
+const chaosSource = "chaos"
+
+// WithChaos perturbs the defined keys tagged TagTunable in the schema on every load and
+// reload, to exercise how an application copes with configuration changing under it, eg:
+// in a staging soak test. Keys with an Enum get one of its values, bool keys a random
+// bool, and int and float keys a value within their Min and Max; other keys are left
+// alone. Perturbed values are logged and take the precedence of a source. The seed makes
+// runs reproducible. Never enable it in production.
+func WithChaos(seed int64) Option {
+	return func(o *options) {
+		o.chaos = &chaos{rnd: rand.New(rand.NewSource(seed))}
+	}
+}
+
+// chaos draws the perturbed values; reloads may run concurrently, hence the lock.
+type chaos struct {
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// perturb layers a random value over every tunable key of the schema.
+func (c *GConfig) perturb(ch *chaos) {
+	if c.v.sourced == nil {
+		c.v.sourced = make(map[string]string)
+		c.v.sourcedFrom = make(map[string]string)
+	}
+	for _, k := range c.KeysByTag(TagTunable) {
+		if !c.Exists(k) {
+			continue
+		}
+		v, ok := ch.value(c.schema[k])
+		if !ok {
+			continue
+		}
+		c.v.sourced[k] = v
+		c.v.sourcedFrom[k] = chaosSource
+		c.logf("Chaos set %s to %s\n", k, v)
+	}
+	c.v.sources[chaosSource] = time.Now()
+}
+
+// value returns a random value satisfying r, or false if r doesn't bound the key.
+func (ch *chaos) value(r Rule) (string, bool) {
+	ch.mu.Lock()
+	defer ch.mu.Unlock()
+	switch {
+	case len(r.Enum) > 0:
+		return r.Enum[ch.rnd.Intn(len(r.Enum))], true
+	case r.Type == TypeBool:
+		return strconv.FormatBool(ch.rnd.Intn(2) == 1), true
+	case r.Min == nil || r.Max == nil || *r.Min > *r.Max:
+		return "", false
+	case r.Type == TypeInt:
+		min, max := int64(math.Ceil(*r.Min)), int64(math.Floor(*r.Max))
+		if min > max {
+			return "", false
+		}
+		return strconv.FormatInt(min+ch.rnd.Int63n(max-min+1), 10), true
+	case r.Type == TypeFloat:
+		return strconv.FormatFloat(*r.Min+ch.rnd.Float64()*(*r.Max-*r.Min), 'g', -1, 64), true
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package gconfig
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestWithChaos(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "pool.size=10\ncache.ratio=0.5\nlog.level=info\nfeature.on=false\napp.name=gconfig\nretry.count=3\n")
+	schema := Schema{
+		"pool.size":   {Type: TypeInt, Min: Bound(1), Max: Bound(20), Tags: []string{TagTunable}},
+		"cache.ratio": {Type: TypeFloat, Min: Bound(0.1), Max: Bound(0.9), Tags: []string{TagTunable}},
+		"log.level":   {Enum: []string{"debug", "info", "warn"}, Tags: []string{TagTunable}},
+		"feature.on":  {Type: TypeBool, Tags: []string{TagTunable}},
+		"app.name":    {Tags: []string{TagTunable}},
+		"retry.count": {Type: TypeInt, Min: Bound(1), Max: Bound(5)},
+		"missing.key": {Type: TypeInt, Min: Bound(1), Max: Bound(5), Tags: []string{TagTunable}},
+	}
+	gcg, err := Load(WithPath(dir), WithSchema(schema), WithChaos(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sizes := make(map[int]bool)
+	for i := 0; i < 20; i++ {
+		if err := gcg.Validate(); err != nil {
+			t.Fatalf("Perturbed values should stay within the schema: %s", err)
+		}
+		sizes[gcg.GetInt("pool.size")] = true
+		if o, _ := gcg.Origin("pool.size"); o.Source != chaosSource {
+			t.Errorf("Perturbed keys should come from the chaos source but got %s", o)
+		}
+		if err := gcg.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(sizes) < 2 {
+		t.Errorf("Reloads should perturb the values but pool.size was always %v", sizes)
+	}
+
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("Keys without bounds should be left alone but app.name was %s", n)
+	}
+	if r := gcg.GetInt("retry.count"); r != 3 {
+		t.Errorf("Keys not tagged tunable should be left alone but retry.count was %d", r)
+	}
+	if gcg.Exists("missing.key") {
+		t.Error("Undefined keys shouldn't be perturbed")
+	}
+
+	again, err := Load(WithPath(dir), WithSchema(schema), WithChaos(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := Load(WithPath(dir), WithSchema(schema), WithChaos(42))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := again.GetString("cache.ratio"), first.GetString("cache.ratio"); a != b {
+		t.Errorf("The same seed should perturb alike but got %s and %s", a, b)
+	}
+	if _, err := strconv.ParseFloat(again.GetString("cache.ratio"), 64); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,65 @@
+package gconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// ChecksumPrefix starts every checksum, naming the hash it's computed with.
+const ChecksumPrefix = "sha256:"
+
+// ChecksumError is returned by Load when the checksum of the configuration isn't the
+// expected one, see WithChecksum.
+type ChecksumError struct {
+	Expected, Actual string
+}
+
+func (e *ChecksumError) Error() string {
+	return fmt.Sprintf("Configuration checksum %s doesn't match the expected %s", e.Actual, e.Expected)
+}
+
+// Checksum returns a hash of the effective configuration: every key and its value, with
+// placeholders resolved, sensitive ones included. Two instances booted with the same
+// configuration have the same checksum, whatever the files, sources or environment
+// variables it was merged from, unless values call instance specific functions, eg:
+// ${hostname} or ${random.uuid}. It changes with Set and Reload.
+func (c *GConfig) Checksum() string {
+	h := sha256.New()
+	for _, k := range c.keys() {
+		fmt.Fprintf(h, "%s=%s\n", strconv.Quote(k), strconv.Quote(c.replaceSysVars(k)))
+	}
+	return ChecksumPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// WithChecksum makes Load fail with a *ChecksumError unless the configuration has the
+// expected checksum, so deployment tooling can guarantee every instance of a fleet boots
+// with the same configuration, eg: the one `gconfig checksum` printed when the release
+// was built. The expected checksum can also be passed with the 'config-checksum' flag or
+// the 'GC_CHECKSUM' environment variable. Reloads aren't checked.
+func WithChecksum(expected string) Option {
+	return func(o *options) {
+		o.checksum = expected
+	}
+}
+
+// verifyChecksum checks the checksum of c against the one expected by o, if any.
+func (c *GConfig) verifyChecksum(o *options) error {
+	expected := o.checksum
+	if expected == "" && !o.noFlags {
+		expected = flagValue("config-checksum")
+	}
+	if expected == "" && !o.noEnv {
+		expected = os.Getenv("GC_CHECKSUM")
+	}
+	if expected == "" {
+		return nil
+	}
+	if actual := c.Checksum(); actual != expected {
+		return &ChecksumError{Expected: expected, Actual: actual}
+	}
+	c.logf("Configuration checksum %s verified\n", expected)
+	return nil
+}
@@ -0,0 +1,67 @@
+package gconfig
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	a, err := NewFromMap(map[string]string{"db.host": "localhost", "db.url": "pg://${db.host}/app"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewFromMap(map[string]string{"db.url": "pg://localhost/app", "db.host": "localhost"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(a.Checksum(), ChecksumPrefix) {
+		t.Errorf("Unexpected checksum %s", a.Checksum())
+	}
+	if a.Checksum() != b.Checksum() {
+		t.Error("Configurations with the same effective values should have the same checksum")
+	}
+
+	b.Set("db.host", "db.prod")
+	if a.Checksum() == b.Checksum() {
+		t.Error("Changing a value should change the checksum")
+	}
+
+	// a value can't be moved to the next key without changing the checksum
+	c, _ := NewFromMap(map[string]string{"a": "b=c"}, "")
+	d, _ := NewFromMap(map[string]string{"a=b": "c"}, "")
+	if c.Checksum() == d.Checksum() {
+		t.Error("Different keys should have different checksums")
+	}
+}
+
+func TestWithChecksum(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+	c, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := c.Checksum()
+
+	if _, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv(), WithChecksum(sum)); err != nil {
+		t.Fatalf("The expected checksum should be accepted: %s", err)
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "app.name=drifted\n")
+	_, err = Load(WithPath(dir), WithoutFlags(), WithoutEnv(), WithChecksum(sum))
+	cerr, ok := err.(*ChecksumError)
+	if !ok || cerr.Expected != sum || cerr.Actual == sum {
+		t.Fatalf("Expected a *ChecksumError, got %v", err)
+	}
+	if _, err := WaitForLoad(context.Background(), WithPath(dir), WithoutFlags(), WithoutEnv(), WithChecksum(sum)); err == nil {
+		t.Error("A checksum mismatch shouldn't be retried")
+	}
+
+	os.Setenv("GC_CHECKSUM", sum)
+	defer os.Unsetenv("GC_CHECKSUM")
+	if _, err := Load(WithPath(dir), WithoutFlags()); err == nil {
+		t.Error("The checksum should be read from GC_CHECKSUM")
+	}
+}
@@ -0,0 +1,15 @@
+//go:build unix
+
+package main
+
+import "syscall"
+
+// run replaces the current process with the command, so it receives the signals sent
+// to the container directly.
+func run(args, env []string) error {
+	path, err := lookPath(args[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, args, env)
+}
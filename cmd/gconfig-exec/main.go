@@ -0,0 +1,140 @@
+// Command gconfig-exec loads a gconfig configuration, exports its keys as environment
+// variables and executes a command in its place, so processes that aren't written in Go
+// get the same layered resolution. It's meant as a container entrypoint:
+//
+//	ENTRYPOINT ["gconfig-exec", "-path", "/app/config", "-keys", "db.url,log.level", "--"]
+//	CMD ["python", "app.py"]
+//
+// Keys are exported as gconfig.WithEnvPrefix reads them, after the -env-prefix if set:
+// db.url becomes DB_URL and db.max_conns DB_MAX__CONNS, so a Go child loading with the
+// same prefix gets the keys back. Keys that can't be named as a variable, eg: db.maxConns,
+// are skipped with a warning, or fail when listed in -keys: -map NAME=key exports a key
+// under a chosen name. The profile and path are read from GC_PROFILE and GC_PATH unless
+// given as flags.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/narup/gconfig"
+)
+
+// mappings collects the repeated -map flags.
+type mappings map[string]string
+
+func (m mappings) String() string {
+	return fmt.Sprint(map[string]string(m))
+}
+
+func (m mappings) Set(v string) error {
+	i := strings.Index(v, "=")
+	if i <= 0 || i == len(v)-1 {
+		return fmt.Errorf("%q is not a NAME=key mapping", v)
+	}
+	m[v[:i]] = v[i+1:]
+	return nil
+}
+
+func main() {
+	fs := flag.NewFlagSet("gconfig-exec", flag.ExitOnError)
+	path := fs.String("path", "", "directory holding the properties files")
+	profile := fs.String("profile", "", "profile to load")
+	keys := fs.String("keys", "", "comma separated keys to export, all keys if empty")
+	prefix := fs.String("env-prefix", "", "prefix of the exported variable names")
+	maps := make(mappings)
+	fs.Var(maps, "map", "export a key under a given variable name, NAME=key, repeatable")
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: gconfig-exec [-path dir] [-profile name] [-keys k1,k2] [-env-prefix prefix] [-map NAME=key] -- command [args...]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(os.Args[1:])
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	opts := []gconfig.Option{gconfig.WithoutFlags()}
+	if *path != "" {
+		opts = append(opts, gconfig.WithPath(*path))
+	}
+	if *profile != "" {
+		opts = append(opts, gconfig.WithProfile(*profile))
+	}
+	c, err := gconfig.Load(opts...)
+	if err != nil {
+		fatal(err)
+	}
+
+	env, err := environ(c, *keys, *prefix, maps)
+	if err != nil {
+		fatal(err)
+	}
+	if err := run(fs.Args(), env); err != nil {
+		fatal(err)
+	}
+}
+
+// environ returns the process environment with the selected keys added, overriding
+// variables of the same name.
+func environ(c *gconfig.GConfig, keys, prefix string, maps mappings) ([]string, error) {
+	all, err := c.Environ(prefix)
+	exported := make(map[string]string, len(all))
+	for _, kv := range all {
+		i := strings.Index(kv, "=")
+		exported[kv[:i]] = kv[i+1:]
+	}
+	if keys == "" {
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "gconfig-exec: %s, export them with -map\n", err)
+		}
+	} else {
+		selected := make(map[string]string)
+		for _, k := range strings.Split(keys, ",") {
+			k = strings.TrimSpace(k)
+			if !c.Exists(k) {
+				return nil, fmt.Errorf("key %s is not defined", k)
+			}
+			name := gconfig.EnvName(prefix, k)
+			v, ok := exported[name]
+			if !ok {
+				return nil, fmt.Errorf("key %s can't be named as an environment variable, export it with -map", k)
+			}
+			selected[name] = v
+		}
+		exported = selected
+	}
+	for name, k := range maps {
+		if !c.Exists(k) {
+			return nil, fmt.Errorf("key %s is not defined", k)
+		}
+		exported[name] = c.GetString(k)
+	}
+
+	var env []string
+	for _, kv := range os.Environ() {
+		if _, ok := exported[kv[:strings.Index(kv+"=", "=")]]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for name, v := range exported {
+		env = append(env, name+"="+v)
+	}
+	return env, nil
+}
+
+// lookPath resolves the command to execute.
+func lookPath(name string) (string, error) {
+	if strings.ContainsRune(name, os.PathSeparator) {
+		return name, nil
+	}
+	return exec.LookPath(name)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "gconfig-exec: %s\n", err)
+	os.Exit(1)
+}
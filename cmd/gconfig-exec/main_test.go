@@ -0,0 +1,85 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/narup/gconfig"
+)
+
+func TestEnviron(t *testing.T) {
+	c, err := gconfig.NewFromMap(map[string]string{
+		"db.host":      "localhost",
+		"db.url":       "postgres://${db.host}/app",
+		"db.max_conns": "10",
+		"db.maxIdle":   "2",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DB_HOST", "overridden")
+
+	tests := []struct {
+		name, keys, prefix string
+		maps               mappings
+		want               []string
+		fails              bool
+	}{
+		{name: "all keys", want: []string{"DB_HOST=localhost", "DB_MAX__CONNS=10", "DB_URL=postgres://localhost/app"}},
+		{name: "prefix", keys: "db.max_conns", prefix: "APP", want: []string{"APP_DB_MAX__CONNS=10", "DB_HOST=overridden"}},
+		{name: "selected", keys: "db.url, db.host", want: []string{"DB_HOST=localhost", "DB_URL=postgres://localhost/app"}},
+		{name: "mapped", keys: "db.host", maps: mappings{"IDLE": "db.maxIdle"}, want: []string{"DB_HOST=localhost", "IDLE=2"}},
+		{name: "unnamable key", keys: "db.maxIdle", fails: true},
+		{name: "undefined key", keys: "db.port", fails: true},
+		{name: "undefined mapping", maps: mappings{"PORT": "db.port"}, fails: true},
+	}
+	for _, tt := range tests {
+		env, err := environ(c, tt.keys, tt.prefix, tt.maps)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %s", tt.name, err)
+			continue
+		}
+
+		var got []string
+		for _, kv := range env {
+			if strings.HasPrefix(kv, "DB_") || strings.HasPrefix(kv, "APP_") || strings.HasPrefix(kv, "IDLE=") {
+				got = append(got, kv)
+			}
+		}
+		sort.Strings(got)
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	c, err := gconfig.NewFromMap(map[string]string{"db.max_conns": "10", "log.level": "warn"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := environ(c, "", "GCEXEC", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range env {
+		if i := strings.Index(kv, "="); strings.HasPrefix(kv, "GCEXEC_") {
+			t.Setenv(kv[:i], kv[i+1:])
+		}
+	}
+
+	child, err := gconfig.Load(gconfig.EnvOnly("GCEXEC"), gconfig.WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if child.GetString("db.max_conns") != "10" || child.GetString("log.level") != "warn" {
+		t.Errorf("A child loading with the prefix should read the keys back, got %v", child.AllSettings())
+	}
+}
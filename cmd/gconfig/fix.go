@@ -0,0 +1,272 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// importPath is the import path of the package call sites are rewritten for.
+const importPath = "github.com/narup/gconfig"
+
+// renames maps the methods of GConfig kept for compatibility to the one replacing them.
+var renames = map[string]string{
+	"GetStringOrDefault":                 "GetString",
+	"GetStringOrDefaultInCommaSeparator": "GetString",
+}
+
+// constructors are the functions of the package returning a *GConfig, first, and methods
+// the methods of GConfig returning one.
+var (
+	constructors = map[string]bool{
+		"Load": true, "LoadContext": true, "LoadOnce": true, "LoadLastKnownGood": true,
+		"WaitForLoad": true, "NewFromMap": true, "Global": true, "TryGlobal": true,
+		"FromContext": true, "Provide": true, "ProvideWithOptions": true,
+	}
+	methods = map[string]bool{"Sub": true, "Snapshot": true}
+)
+
+// fix rewrites the call sites of deprecated APIs in Go files: reads of gconfig.Gcg become
+// gconfig.Global(), assignments to it gconfig.SetGlobal and the GetStringOrDefault
+// variants GetString. It lists the files needing changes, or rewrites them with -w.
+// The files are parsed but not type checked: a variant is renamed when its receiver is
+// known to be a *gconfig.GConfig from the file alone, eg: a variable assigned the result
+// of gconfig.Load or a parameter of type *gconfig.GConfig, and the other calls, eg: on a
+// struct field, are reported on stderr to be checked by hand.
+func fix(args []string) error {
+	fs := flag.NewFlagSet("fix", flag.ExitOnError)
+	write := fs.Bool("w", false, "rewrite the files instead of listing them")
+	fs.Parse(args)
+	paths := fs.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	files, err := goFiles(paths)
+	if err != nil {
+		return err
+	}
+	changed := 0
+	for _, name := range files {
+		src, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		out, unknown, err := fixSource(name, src)
+		if err != nil {
+			return err
+		}
+		for _, u := range unknown {
+			fmt.Fprintf(os.Stderr, "%s: receiver not known to be a *gconfig.GConfig, left unchanged\n", u)
+		}
+		if bytes.Equal(src, out) {
+			continue
+		}
+		changed++
+		fmt.Println(name)
+		if *write {
+			if err := os.WriteFile(name, out, 0644); err != nil {
+				return err
+			}
+		}
+	}
+	if changed > 0 && !*write {
+		return fmt.Errorf("%d files use deprecated APIs, run with -w to rewrite them", changed)
+	}
+	return nil
+}
+
+// goFiles returns the Go files of paths, walking directories, written dir or dir/...,
+// but skipping vendor, testdata and hidden ones.
+func goFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, p := range paths {
+		p = strings.TrimSuffix(p, "/...")
+		if p == "" || p == "..." {
+			p = "."
+		}
+		err := filepath.WalkDir(p, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			name := d.Name()
+			if d.IsDir() {
+				if path != p && (name == "vendor" || name == "testdata" || strings.HasPrefix(name, ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if strings.HasSuffix(name, ".go") {
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// edit replaces the source between two offsets.
+type edit struct {
+	start, end int
+	text       string
+}
+
+// fixSource returns src with the deprecated call sites rewritten, or src itself if the
+// file doesn't import gconfig, and the positions of the calls of the GetStringOrDefault
+// variants left unchanged as their receiver may not be a *gconfig.GConfig.
+func fixSource(name string, src []byte) ([]byte, []token.Position, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, nil, err
+	}
+	pkg := ""
+	for _, spec := range f.Imports {
+		if p, _ := strconv.Unquote(spec.Path.Value); p == importPath {
+			pkg = "gconfig"
+			if spec.Name != nil {
+				pkg = spec.Name.Name
+			}
+		}
+	}
+	if pkg == "" || pkg == "." || pkg == "_" {
+		return src, nil, nil
+	}
+
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	isPkg := func(e ast.Expr, names ...string) bool {
+		sel, ok := e.(*ast.SelectorExpr)
+		if !ok {
+			return false
+		}
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || id.Name != pkg || id.Obj != nil {
+			return false
+		}
+		for _, name := range names {
+			if sel.Sel.Name == name {
+				return true
+			}
+		}
+		return false
+	}
+	isGcg := func(e ast.Expr) bool { return isPkg(e, "Gcg") }
+	isConfig := configExpr(isPkg)
+
+	var edits []edit
+	var unknown []token.Position
+	assigned := make(map[ast.Expr]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok == token.ASSIGN && len(n.Lhs) == 1 && len(n.Rhs) == 1 && isGcg(n.Lhs[0]) {
+				assigned[n.Lhs[0]] = true
+				edits = append(edits, edit{offset(n.Pos()), offset(n.Rhs[0].Pos()), pkg + ".SetGlobal("})
+				edits = append(edits, edit{offset(n.End()), offset(n.End()), ")"})
+			}
+		case *ast.SelectorExpr:
+			to, renamed := renames[n.Sel.Name]
+			switch {
+			case isGcg(n) && !assigned[n]:
+				edits = append(edits, edit{offset(n.Pos()), offset(n.End()), pkg + ".Global()"})
+			case !renamed || isPkg(n, n.Sel.Name):
+			case isConfig(n.X):
+				edits = append(edits, edit{offset(n.Sel.Pos()), offset(n.Sel.End()), to})
+			default:
+				unknown = append(unknown, fset.Position(n.Sel.Pos()))
+			}
+		}
+		return true
+	})
+	if len(edits) == 0 {
+		return src, unknown, nil
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+	out := append([]byte(nil), src...)
+	for _, e := range edits {
+		out = append(out[:e.start], append([]byte(e.text), out[e.end:]...)...)
+	}
+	out, err = format.Source(out)
+	return out, unknown, err
+}
+
+// configExpr returns a function reporting whether an expression is a *gconfig.GConfig,
+// as far as the file tells: gconfig.Gcg, the result of a constructor or of a method
+// returning one, or a variable declared with the type or assigned one of them. isPkg
+// reports whether an expression is one of the names of the package.
+func configExpr(isPkg func(e ast.Expr, names ...string) bool) func(ast.Expr) bool {
+	isType := func(e ast.Expr) bool {
+		star, ok := e.(*ast.StarExpr)
+		return ok && isPkg(star.X, "GConfig")
+	}
+	var isConfig func(e ast.Expr) bool
+	isConfig = func(e ast.Expr) bool {
+		switch e := e.(type) {
+		case *ast.ParenExpr:
+			return isConfig(e.X)
+		case *ast.SelectorExpr:
+			return isPkg(e, "Gcg")
+		case *ast.CallExpr:
+			if sel, ok := e.Fun.(*ast.SelectorExpr); ok && methods[sel.Sel.Name] && isConfig(sel.X) {
+				return true
+			}
+			for name := range constructors {
+				if isPkg(e.Fun, name) {
+					return true
+				}
+			}
+		case *ast.Ident:
+			if e.Obj == nil || e.Obj.Kind != ast.Var {
+				return false
+			}
+			switch d := e.Obj.Decl.(type) {
+			case *ast.Field:
+				return isType(d.Type)
+			case *ast.ValueSpec:
+				if d.Type != nil {
+					return isType(d.Type)
+				}
+				return isConfig(value(d.Names, d.Values, e.Name))
+			case *ast.AssignStmt:
+				lhs := make([]*ast.Ident, len(d.Lhs))
+				for i, l := range d.Lhs {
+					lhs[i], _ = l.(*ast.Ident)
+				}
+				return isConfig(value(lhs, d.Rhs, e.Name))
+			}
+		}
+		return false
+	}
+	return isConfig
+}
+
+// value returns the expression assigned to the variable name among names, the first result
+// of a call assigned to several variables, or nil.
+func value(names []*ast.Ident, values []ast.Expr, name string) ast.Expr {
+	for i, id := range names {
+		if id == nil || id.Name != name {
+			continue
+		}
+		switch {
+		case len(values) == len(names):
+			return values[i]
+		case len(values) == 1 && i == 0:
+			return values[0]
+		}
+	}
+	return nil
+}
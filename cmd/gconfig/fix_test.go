@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFixSource(t *testing.T) {
+	for _, tc := range []struct {
+		name, src, want string
+		unknown         int
+	}{
+		{
+			name: "global",
+			src:  "c := gconfig.Gcg\ngconfig.Gcg = c\n",
+			want: "c := gconfig.Global()\ngconfig.SetGlobal(c)\n",
+		},
+		{
+			name: "loaded",
+			src:  "c, _ := gconfig.Load()\n_ = c.GetStringOrDefault(\"a\")\n_ = gconfig.Gcg.GetStringOrDefaultInCommaSeparator(\"b\")\n",
+			want: "c, _ := gconfig.Load()\n_ = c.GetString(\"a\")\n_ = gconfig.Global().GetString(\"b\")\n",
+		},
+		{
+			name: "sub",
+			src:  "db := gconfig.Global().Sub(\"db\")\n_ = db.GetStringOrDefault(\"host\")\n",
+			want: "db := gconfig.Global().Sub(\"db\")\n_ = db.GetString(\"host\")\n",
+		},
+		{
+			name: "parameter",
+			src:  "_ = func(c *gconfig.GConfig) string { return c.GetStringOrDefault(\"a\") }\n",
+			want: "_ = func(c *gconfig.GConfig) string { return c.GetString(\"a\") }\n",
+		},
+		{
+			name: "declared",
+			src:  "var c *gconfig.GConfig\n_ = c.GetStringOrDefault(\"a\")\n",
+			want: "var c *gconfig.GConfig\n_ = c.GetString(\"a\")\n",
+		},
+		{
+			name:    "other type",
+			src:     "var o other\n_ = o.GetStringOrDefault(\"a\")\n",
+			want:    "var o other\n_ = o.GetStringOrDefault(\"a\")\n",
+			unknown: 1,
+		},
+		{
+			name:    "field",
+			src:     "var s struct{ c *gconfig.GConfig }\n_ = s.c.GetStringOrDefault(\"a\")\n",
+			want:    "var s struct{ c *gconfig.GConfig }\n_ = s.c.GetStringOrDefault(\"a\")\n",
+			unknown: 1,
+		},
+		{
+			name: "shadowed package",
+			src:  "gconfig := struct{ Gcg int }{}\n_ = gconfig.Gcg\n",
+			want: "gconfig := struct{ Gcg int }{}\n_ = gconfig.Gcg\n",
+		},
+	} {
+		src := "package p\n\nimport \"github.com/narup/gconfig\"\n\ntype other struct{}\n\nfunc (other) GetStringOrDefault(string) string { return \"\" }\n\nfunc f() {\n" + tc.src + "}\n"
+		out, unknown, err := fixSource(tc.name+".go", []byte(src))
+		if err != nil {
+			t.Errorf("%s: %v", tc.name, err)
+			continue
+		}
+		body := string(out[strings.Index(string(out), "func f() {\n")+len("func f() {\n") : len(out)-len("}\n")])
+		if got := strings.ReplaceAll(body, "\t", ""); got != tc.want {
+			t.Errorf("%s: expected\n%s\ngot\n%s", tc.name, tc.want, got)
+		}
+		if len(unknown) != tc.unknown {
+			t.Errorf("%s: expected %d unknown receivers, got %v", tc.name, tc.unknown, unknown)
+		}
+	}
+}
+
+func TestFixSourceWithoutImport(t *testing.T) {
+	src := []byte("package p\n\nvar Gcg int\n\nfunc f() { _ = Gcg }\n")
+	for _, imp := range []string{"", "import _ \"github.com/narup/gconfig\"\n"} {
+		src := append([]byte(nil), src...)
+		if imp != "" {
+			src = []byte(strings.Replace(string(src), "\n\n", "\n\n"+imp+"\n", 1))
+		}
+		out, _, err := fixSource("p.go", src)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(out) != string(src) {
+			t.Errorf("A file not using the package should be left unchanged but got\n%s", out)
+		}
+	}
+}
+
+func TestGoFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.go", "doc.txt", "sub/b.go", "vendor/v.go", "testdata/t.go", ".git/g.go"} {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := goFiles([]string{dir + "/..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{filepath.Join(dir, "a.go"), filepath.Join(dir, "sub", "b.go")}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("expected %v, got %v", want, files)
+	}
+}
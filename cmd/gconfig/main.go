@@ -0,0 +1,494 @@
+// Command gconfig works with gconfig configuration directories from the command line.
+//
+// Usage:
+//
+//	gconfig get -profile prod db.url
+//	gconfig explain -profile prod db.url
+//	gconfig dump -profile prod -format json
+//	gconfig diff prod staging
+//	gconfig convert application.yaml application.properties
+//	gconfig sync -from dir:./config?profile=prod -to consul://myapp -dry-run
+//	gconfig validate -profile prod
+//	gconfig checksum -profile prod
+//	gconfig bake -profile prod -path ./config -o application-baked.properties
+//	gconfig lint -path ./config -max-depth 4
+//	gconfig env -path ./config -format k8s
+//	gconfig required-env -profile prod -check
+//	gconfig k8s -profile prod -name myapp -env-prefix MYAPP
+//	gconfig fix -w ./...
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/narup/gconfig"
+)
+
+// command is a single gconfig subcommand. run receives the arguments following
+// the subcommand name.
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"get":          {"get [-path dir] [-profile name] key", get},
+	"explain":      {"explain [-path dir] [-profile name] [-json] key", explain},
+	"dump":         {"dump [-path dir] [-profile name] [-format properties|json|yaml] [-o file]", dump},
+	"diff":         {"diff [-path dir] [-check] profile other", diff},
+	"convert":      {"convert in.(properties|json|yaml) [out.(properties|json|yaml)]", convert},
+	"sync":         {"sync -from url -to url [-dry-run]", sync},
+	"validate":     {"validate [-path dir] [-profile name]", validate},
+	"checksum":     {"checksum [-path dir] [-profile name]", checksum},
+	"bake":         {"bake [-path dir] [-profile name] [-o file] [-keep-placeholders]", bake},
+	"env":          {"env [-path dir] [-format list|k8s|systemd] [-check]", env},
+	"required-env": {"required-env [-path dir] [-profile name] [-check]", requiredEnv},
+	"k8s":          {"k8s -name name [-path dir] [-profile name] [-namespace ns] [-env-prefix prefix] [-keep-placeholders] [-env-from] [-o file]", k8s},
+	"lint":         {"lint [-path dir] [-max-depth n] [-allow-camel-case] [-reserved prefix,...]", lint},
+	"fix":          {"fix [-w] [path ...]", fix},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "gconfig: unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gconfig %s: %s\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for n := range commands {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "usage:")
+	for _, n := range names {
+		fmt.Fprintf(os.Stderr, "  gconfig %s\n", commands[n].usage)
+	}
+}
+
+// loadFlags registers the flags shared by every subcommand that loads a configuration.
+func loadFlags(fs *flag.FlagSet) (path, profile *string) {
+	path = fs.String("path", "config", "directory holding the properties files")
+	profile = fs.String("profile", "", "profile to load")
+	return path, profile
+}
+
+// load loads the configuration of a profile, without parsing the process flags.
+func load(path, profile string) (*gconfig.GConfig, error) {
+	return gconfig.Load(gconfig.WithPath(path), gconfig.WithProfile(profile), gconfig.WithoutFlags())
+}
+
+// get prints the resolved value of a key.
+func get(args []string) error {
+	fs := flag.NewFlagSet("get", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a single key")
+	}
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+	key := fs.Arg(0)
+	if !c.Exists(key) {
+		return fmt.Errorf("key %s is not defined", key)
+	}
+	fmt.Println(c.GetString(key))
+	return nil
+}
+
+// explain prints how the value of a key is resolved: the layers defining it and the
+// expansion of its placeholders.
+func explain(args []string) error {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	asJSON := fs.Bool("json", false, "write the explanation as JSON")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return fmt.Errorf("expected a single key")
+	}
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+	e := c.Explain(fs.Arg(0))
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(e)
+	}
+	fmt.Print(e)
+	return nil
+}
+
+// dump writes the effective configuration of a profile, sensitive values masked.
+func dump(args []string) error {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	format := fs.String("format", "properties", "output format: properties, json or yaml")
+	out := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+	return writeOutput(*out, func(w io.Writer) error {
+		return c.WriteFormat(w, gconfig.Format(*format))
+	})
+}
+
+// diff prints the keys whose effective value differs between two profiles: - for keys
+// only in the first, + for keys only in the second and ~ for changed values. With -check
+// it fails when the profiles don't define the same keys.
+func diff(args []string) error {
+	fs := flag.NewFlagSet("diff", flag.ExitOnError)
+	path := fs.String("path", "config", "directory holding the properties files")
+	check := fs.Bool("check", false, "fail if the profiles don't define the same keys")
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return fmt.Errorf("expected two profiles")
+	}
+
+	a, err := load(*path, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	d, err := a.Diff(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	b, err := load(*path, fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	show := func(c *gconfig.GConfig, k string) string {
+		if c.IsSensitive(k) {
+			return gconfig.MaskedValue
+		}
+		return c.GetString(k)
+	}
+	for _, k := range d.Removed {
+		fmt.Printf("- %s=%s\n", k, show(a, k))
+	}
+	for _, k := range d.Added {
+		fmt.Printf("+ %s=%s\n", k, show(b, k))
+	}
+	for _, k := range d.Changed {
+		fmt.Printf("~ %s: %s -> %s\n", k, show(a, k), show(b, k))
+	}
+	if *check && !d.SameKeys() {
+		return fmt.Errorf("profiles don't define the same keys")
+	}
+	return nil
+}
+
+// convert rewrites a configuration file in another format, chosen from the extensions.
+// Without an output file the properties format is written to stdout.
+func convert(args []string) error {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	fs.Parse(args)
+	if fs.NArg() < 1 || fs.NArg() > 2 {
+		return fmt.Errorf("expected an input and an optional output file")
+	}
+
+	in, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out := fs.Arg(1)
+	return writeOutput(out, func(w io.Writer) error {
+		return gconfig.Convert(w, in, gconfig.FormatFromExt(fs.Arg(0)), gconfig.FormatFromExt(out))
+	})
+}
+
+// sync copies the keys of one source into another, eg: a configuration directory into a
+// key/value store, printing + for added keys, ~ for changed ones and = for keys only the
+// destination defines, which are kept. Values aren't printed as they may be secrets.
+func sync(args []string) error {
+	fs := flag.NewFlagSet("sync", flag.ExitOnError)
+	from := fs.String("from", "", "source URL to copy from, eg: dir:./config?profile=prod")
+	to := fs.String("to", "", "writable source URL to copy to")
+	dryRun := fs.Bool("dry-run", false, "print the differences without writing")
+	fs.Parse(args)
+	if *from == "" || *to == "" {
+		return fmt.Errorf("-from and -to are required")
+	}
+
+	src, err := gconfig.OpenSource(*from)
+	if err != nil {
+		return err
+	}
+	dst, err := gconfig.OpenSource(*to)
+	if err != nil {
+		return err
+	}
+	ws, ok := dst.(gconfig.WritableSource)
+	if !ok {
+		return fmt.Errorf("%s isn't writable", dst.Name())
+	}
+
+	d, err := gconfig.Sync(context.Background(), src, ws, *dryRun)
+	if d != nil {
+		for _, k := range d.Added {
+			fmt.Printf("+ %s\n", k)
+		}
+		for _, k := range d.Changed {
+			fmt.Printf("~ %s\n", k)
+		}
+		for _, k := range d.Removed {
+			fmt.Printf("= %s\n", k)
+		}
+	}
+	return err
+}
+
+// validate loads a profile strictly and fails on malformed lines, unresolved
+// placeholders, invalid expressions and other warnings.
+func validate(args []string) error {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	fs.Parse(args)
+
+	res, err := gconfig.LoadDetailed(gconfig.WithPath(*path), gconfig.WithProfile(*profile), gconfig.WithoutFlags(), gconfig.ParseStrict())
+	if err != nil {
+		return err
+	}
+	for _, w := range res.Warnings {
+		fmt.Println(w)
+	}
+	if len(res.Warnings) > 0 {
+		return fmt.Errorf("%d issues found", len(res.Warnings))
+	}
+	return nil
+}
+
+// checksum prints the checksum of the effective configuration of a profile, to pass to
+// the instances of a fleet with -config-checksum or GC_CHECKSUM.
+func checksum(args []string) error {
+	fs := flag.NewFlagSet("checksum", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	fs.Parse(args)
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+	fmt.Println(c.Checksum())
+	return nil
+}
+
+// bake resolves the configuration for a profile into a single flat properties file
+// suitable for shipping in an immutable image.
+func bake(args []string) error {
+	fs := flag.NewFlagSet("bake", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	out := fs.String("o", "", "output file (default stdout)")
+	keep := fs.Bool("keep-placeholders", false, "write ${...} references unresolved instead of baking in their current values")
+	fs.Parse(args)
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*out, func(w io.Writer) error {
+		return c.Bake(w, *keep)
+	})
+}
+
+// lint checks the key naming conventions of every properties file in a directory and
+// fails if any key breaks them.
+func lint(args []string) error {
+	def := gconfig.DefaultLintRules()
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	path := fs.String("path", "config", "directory holding the properties files")
+	maxDepth := fs.Int("max-depth", def.MaxDepth, "maximum number of segments in a key, 0 for no limit")
+	camel := fs.Bool("allow-camel-case", def.AllowCamelCase, "accept upper case letters in keys")
+	reserved := fs.String("reserved", strings.Join(def.ReservedPrefixes, ","), "comma separated prefixes no key may start with")
+	fs.Parse(args)
+
+	rules := gconfig.LintRules{MaxDepth: *maxDepth, AllowCamelCase: *camel}
+	for _, p := range strings.Split(*reserved, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			rules.ReservedPrefixes = append(rules.ReservedPrefixes, p)
+		}
+	}
+
+	warnings, err := gconfig.LintDir(*path, rules)
+	if err != nil {
+		return err
+	}
+	for _, w := range warnings {
+		fmt.Printf("%s:%d: %s\n", w.File, w.Line, w.Message)
+	}
+	if len(warnings) > 0 {
+		return fmt.Errorf("%d issues found", len(warnings))
+	}
+	return nil
+}
+
+// env lists the environment variables referenced by the properties files, in a format
+// ready to paste into a deployment manifest. With -check it fails instead if a variable
+// without a default isn't set in the current environment.
+func env(args []string) error {
+	fs := flag.NewFlagSet("env", flag.ExitOnError)
+	path := fs.String("path", "config", "directory holding the properties files")
+	format := fs.String("format", "list", "output format: list, k8s or systemd")
+	check := fs.Bool("check", false, "fail if a required variable isn't set")
+	fs.Parse(args)
+
+	refs, err := gconfig.EnvReferences(*path)
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		var missing []string
+		for _, r := range refs {
+			if _, ok := os.LookupEnv(r.Name); r.Required && !ok {
+				missing = append(missing, r.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("required variables not set: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+
+	switch *format {
+	case "list":
+		for _, r := range refs {
+			state := "required"
+			if !r.Required {
+				state = "default " + strings.Join(r.Defaults, ", ")
+			}
+			fmt.Printf("%s\t%s\t%s\n", r.Name, state, strings.Join(r.Refs, " "))
+		}
+	case "k8s":
+		fmt.Println("env:")
+		for _, r := range refs {
+			fmt.Printf("  - name: %s\n", r.Name)
+			if r.Required {
+				fmt.Println("    value: \"\" # required")
+			} else {
+				fmt.Printf("    value: %q\n", r.Defaults[0])
+			}
+		}
+	case "systemd":
+		for _, r := range refs {
+			if r.Required {
+				fmt.Printf("Environment=%s= # required\n", r.Name)
+			} else {
+				fmt.Printf("Environment=%s=%s\n", r.Name, r.Defaults[0])
+			}
+		}
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	return nil
+}
+
+// requiredEnv prints the environment variables a profile declares it needs under
+// gconfig.requires.env, one per line. With -check it fails instead if any isn't set in
+// the current environment.
+func requiredEnv(args []string) error {
+	fs := flag.NewFlagSet("required-env", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	check := fs.Bool("check", false, "fail if a required variable isn't set")
+	fs.Parse(args)
+
+	names, err := gconfig.RequiredEnvInDir(*path, *profile)
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		var missing []string
+		for _, name := range names {
+			if _, ok := os.LookupEnv(name); !ok {
+				missing = append(missing, name)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("required variables not set: %s", strings.Join(missing, ", "))
+		}
+		return nil
+	}
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	return nil
+}
+
+// k8s writes a ConfigMap and a Secret holding the configuration of a profile, or with
+// -env-from the container envFrom block loading them.
+func k8s(args []string) error {
+	fs := flag.NewFlagSet("k8s", flag.ExitOnError)
+	path, profile := loadFlags(fs)
+	var ko gconfig.KubernetesOptions
+	fs.StringVar(&ko.Name, "name", "", "name of the ConfigMap, the Secret is suffixed with -secret")
+	fs.StringVar(&ko.Namespace, "namespace", "", "namespace of the manifests")
+	fs.StringVar(&ko.EnvPrefix, "env-prefix", "", "write keys as environment variables read with this prefix")
+	fs.BoolVar(&ko.KeepPlaceholders, "keep-placeholders", false, "write ${...} references unresolved")
+	envFrom := fs.Bool("env-from", false, "write the envFrom block of a container instead")
+	out := fs.String("o", "", "output file (default stdout)")
+	fs.Parse(args)
+
+	c, err := load(*path, *profile)
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(*out, func(w io.Writer) error {
+		if *envFrom {
+			return c.WriteKubernetesEnvFrom(w, ko)
+		}
+		return c.WriteKubernetes(w, ko)
+	})
+}
+
+// writeOutput runs write against the named file, or stdout when name is empty.
+func writeOutput(name string, write func(w io.Writer) error) error {
+	if name == "" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,213 @@
+package gconfig
+
+import (
+	"os"
+	"path"
+	"runtime"
+	"sort"
+	s "strings"
+	"sync"
+)
+
+// ConditionSeparator separates a key from the condition it applies under in the
+// properties files, eg: cache.dir@darwin=~/Library/Caches/myapp.
+const ConditionSeparator = "@"
+
+var (
+	conditionsMu sync.RWMutex
+	conditions   = make(map[string]func(arg string) bool)
+)
+
+// knownOS and knownArch are the values of GOOS and GOARCH usable as conditions.
+var (
+	knownOS   = []string{"aix", "android", "darwin", "dragonfly", "freebsd", "illumos", "ios", "js", "linux", "netbsd", "openbsd", "plan9", "solaris", "wasip1", "windows"}
+	knownArch = []string{"386", "amd64", "arm", "arm64", "loong64", "mips", "mips64", "mips64le", "mipsle", "ppc64", "ppc64le", "riscv64", "s390x", "wasm"}
+)
+
+// RegisterCondition adds a condition the keys of the properties files can be qualified
+// with, eg: after RegisterCondition("region", ...) the line db.host@region:eu=... applies
+// only where fn("eu") is true, and db.host@region where fn("") is. It's meant to be
+// called from an init function and panics if name is registered twice, is a built-in
+// condition or fn is nil.
+func RegisterCondition(name string, fn func(arg string) bool) {
+	conditionsMu.Lock()
+	defer conditionsMu.Unlock()
+	if fn == nil {
+		panic("gconfig: RegisterCondition condition is nil")
+	}
+	_, dup := conditions[name]
+	if dup || builtinCondition(name) {
+		panic("gconfig: RegisterCondition called twice for condition " + name)
+	}
+	conditions[name] = fn
+}
+
+func builtinCondition(name string) bool {
+	return name == "os" || name == "arch" || name == "hostname" || contains(knownOS, name) || contains(knownArch, name)
+}
+
+// condition evaluates the qualifier of a conditional key for the levels of the active
+// profile, see profileLevels, reporting whether it is one. A qualifier is an OS or an
+// architecture, eg: linux or arm64, os:NAME, arch:NAME, hostname:PATTERN, matched with
+// path.Match, profile:NAME, a registered condition with an optional argument after ':',
+// or else a profile name, eg: app.url@prod, made of letters, digits, '-' and '_'. A
+// profile holds for its own level and the less specific ones: app.url@prod applies to
+// the profile prod-us too.
+func condition(q string, profiles []string) (holds, ok bool) {
+	name, arg, hasArg := s.Cut(q, ":")
+	switch {
+	case hasArg && name == "profile":
+		return contains(profiles, s.ToLower(arg)), true
+	case !hasArg && contains(knownOS, name):
+		return name == runtime.GOOS, true
+	case !hasArg && contains(knownArch, name):
+		return name == runtime.GOARCH, true
+	case hasArg && name == "os":
+		return arg == runtime.GOOS, true
+	case hasArg && name == "arch":
+		return arg == runtime.GOARCH, true
+	case hasArg && name == "hostname":
+		host, err := os.Hostname()
+		if err != nil {
+			return false, true
+		}
+		m, _ := path.Match(s.ToLower(arg), s.ToLower(host))
+		return m, true
+	}
+	conditionsMu.RLock()
+	fn, registered := conditions[name]
+	conditionsMu.RUnlock()
+	switch {
+	case registered:
+		return fn(arg), true
+	case !hasArg && isProfileName(q):
+		return contains(profiles, s.ToLower(q)), true
+	}
+	return false, false
+}
+
+// isBareProfile reports whether q is read as a profile name for want of another
+// condition, eg: prod in app.url@prod, rather than written profile:prod.
+func isBareProfile(q string) bool {
+	if s.Contains(q, ":") || contains(knownOS, q) || contains(knownArch, q) || !isProfileName(q) {
+		return false
+	}
+	conditionsMu.RLock()
+	defer conditionsMu.RUnlock()
+	_, registered := conditions[q]
+	return !registered
+}
+
+// ambiguousConditions returns the keys of configs qualified with a bare profile name
+// whose unqualified key isn't defined alongside: before profile qualifiers, a key like
+// notify@ops was an ordinary key, which is now only read for the profile ops.
+func ambiguousConditions(keys []string) []string {
+	plain := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		plain[k] = true
+	}
+	var ambiguous []string
+	for _, k := range keys {
+		if base, q, ok := splitCondition(k); ok && isBareProfile(q) && !plain[base] {
+			ambiguous = append(ambiguous, k)
+		}
+	}
+	sort.Strings(ambiguous)
+	return ambiguous
+}
+
+// profileLevels returns the levels of a hierarchical profile from the least to the most
+// specific, eg: prod, prod-us and prod-us-east for prod-us-east, or the profile alone when
+// it is split in fragments rather than levels, see ProfileFragments.
+func profileLevels(profile string, fragments bool) []string {
+	if profile == "" {
+		return nil
+	}
+	if fragments {
+		return []string{profile}
+	}
+	parts := s.Split(profile, "-")
+	levels := make([]string, len(parts))
+	for i := range parts {
+		levels[i] = s.Join(parts[:i+1], "-")
+	}
+	return levels
+}
+
+// isProfileName reports whether q can name a profile.
+func isProfileName(q string) bool {
+	for _, c := range q {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return q != ""
+}
+
+// splitCondition splits a conditional key into the key and its qualifier.
+func splitCondition(key string) (string, string, bool) {
+	i := s.LastIndex(key, ConditionSeparator)
+	if i <= 0 || i == len(key)-1 {
+		return key, "", false
+	}
+	return key[:i], key[i+1:], true
+}
+
+// applyConditions returns cf with its conditional keys, eg: key@linux or key@prod,
+// replacing their key when their condition holds for the levels of the active profile
+// and dropped otherwise. A conditional key wins over the plain one in the same file whatever their
+// order, and the last one holding over the others, so a small project can keep every
+// profile in one file:
+//
+//	app.url=http://localhost:8080
+//	app.url@prod=https://app.example.com
+//
+// Keys whose qualifier isn't a condition are kept as they are.
+func (cf configFile) applyConditions(profiles []string) configFile {
+	type conditional struct {
+		key, base string
+	}
+	var matched []conditional
+	var dropped []string
+	for k := range cf.configs {
+		base, q, ok := splitCondition(k)
+		if !ok {
+			continue
+		}
+		holds, isCondition := condition(q, profiles)
+		switch {
+		case !isCondition:
+		case holds:
+			matched = append(matched, conditional{k, base})
+		default:
+			dropped = append(dropped, k)
+		}
+	}
+	if len(matched) == 0 && len(dropped) == 0 {
+		return cf
+	}
+
+	out := configFile{fileInfo: cf.fileInfo, configs: make(map[string]interface{}, len(cf.configs)), positions: make(map[string]position, len(cf.positions)), warnings: cf.warnings}
+	for k, v := range cf.configs {
+		out.configs[k] = v
+		out.positions[k] = cf.positions[k]
+	}
+	for _, k := range dropped {
+		delete(out.configs, k)
+		delete(out.positions, k)
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		pi, pj := cf.positions[matched[i].key], cf.positions[matched[j].key]
+		if pi.line != pj.line {
+			return pi.line < pj.line
+		}
+		return matched[i].key < matched[j].key
+	})
+	for _, m := range matched {
+		out.configs[m.base] = cf.configs[m.key]
+		out.positions[m.base] = cf.positions[m.key]
+		delete(out.configs, m.key)
+		delete(out.positions, m.key)
+	}
+	return out
+}
@@ -0,0 +1,126 @@
+package gconfig
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestConditions(t *testing.T) {
+	host, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+	other := "windows"
+	if runtime.GOOS == "windows" {
+		other = "linux"
+	}
+	RegisterCondition("gctest-region", func(arg string) bool { return arg == "eu" })
+	defer func() {
+		conditionsMu.Lock()
+		delete(conditions, "gctest-region")
+		conditionsMu.Unlock()
+	}()
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "cache.dir@"+runtime.GOOS+"=/native\n"+
+		"cache.dir=/default\n"+
+		"cache.dir@"+other+"=/other\n"+
+		"cpu.arch@arch:"+runtime.GOARCH+"=native\n"+
+		"web.role@hostname:"+host+"=this host\n"+
+		"web.role@hostname:not-*-this-host=other host\n"+
+		"db.host@gctest-region:eu=eu.db\n"+
+		"db.host@gctest-region:us=us.db\n"+
+		"admin.email@example.com=kept\n"+
+		"url: http://example.com\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"cache.dir": "/native", "cpu.arch": "native", "web.role": "this host", "db.host": "eu.db",
+		"admin.email@example.com": "kept", "url": "http://example.com",
+	}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Expected %s=%s but got %s", k, v, got)
+		}
+	}
+	if gcg.Exists("cache.dir@"+other) || gcg.Exists("db.host@gctest-region:us") {
+		t.Error("Conditional keys shouldn't be kept under their qualified name")
+	}
+	if o, _ := gcg.Origin("cache.dir"); o.Line != 1 {
+		t.Errorf("The origin should be the line of the conditional key but got %s", o)
+	}
+}
+
+func TestLintConditions(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "cache.dir@linux=/a\nweb.role@hostname:web-01.example.com=b\nbad@key=c\n")
+	warnings, err := LintDir(dir, DefaultLintRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Key != "bad@key" {
+		t.Errorf("Only keys whose qualifier isn't a condition should be reported but got %v", warnings)
+	}
+}
+
+func TestProfileConditions(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.url@dev=http://dev\n"+
+		"app.url=http://localhost\n"+
+		"app.url@prod=https://prod\n"+
+		"db.pool@profile:linux=50\n"+
+		"db.pool=5\n")
+
+	for profile, want := range map[string][]string{
+		"":      {"http://localhost", "5"},
+		"dev":   {"http://dev", "5"},
+		"prod":  {"https://prod", "5"},
+		"linux": {"http://localhost", "50"},
+		// a profile qualifier applies to the more specific profiles too
+		"prod-us":  {"https://prod", "5"},
+		"linux-eu": {"http://localhost", "50"},
+	} {
+		gcg, err := Load(WithPath(dir), WithProfile(profile), WithoutFlags(), WithoutEnv())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := []string{gcg.GetString("app.url"), gcg.GetString("db.pool")}; got[0] != want[0] || got[1] != want[1] {
+			t.Errorf("Expected %v for profile %q, got %v", want, profile, got)
+		}
+		if gcg.Exists("app.url@dev") || gcg.Exists("app.url@prod") {
+			t.Errorf("Profile qualified keys should be removed for profile %q", profile)
+		}
+	}
+}
+
+func TestAmbiguousProfileConditions(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "notify@ops=pager\napp.url=http://localhost\napp.url@prod=https://prod\n")
+
+	l := &recordingLogger{}
+	if _, err := Load(WithPath(dir), WithProfile("dev"), WithLogger(l), WithoutFlags(), WithoutEnv()); err != nil {
+		t.Fatal(err)
+	}
+	var warned []string
+	for _, line := range l.lines {
+		if strings.Contains(line, "@") {
+			warned = append(warned, line)
+		}
+	}
+	if len(warned) != 1 || !strings.Contains(warned[0], "notify@ops") {
+		t.Errorf("Only the key without an unqualified one should be warned about, got %q", warned)
+	}
+
+	warnings, err := LintDir(dir, DefaultLintRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Key != "notify@ops" || warnings[0].Line != 1 {
+		t.Errorf("Expected a lint warning for notify@ops, got %v", warnings)
+	}
+}
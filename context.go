@@ -0,0 +1,38 @@
+package gconfig
+
+import "context"
+
+// contextKey is the key of the configuration attached to a context.
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying c, which FromContext returns, eg: to hand a
+// test its own configuration through the code under test.
+func NewContext(ctx context.Context, c *GConfig) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext returns the configuration attached to ctx by NewContext or Middleware, and
+// otherwise the global configuration, nil if none was loaded. Libraries can read their
+// settings through it without depending on how the application wires its configuration.
+func FromContext(ctx context.Context) *GConfig {
+	if c, ok := ctx.Value(contextKey{}).(*GConfig); ok && c != nil {
+		return c
+	}
+	c, _ := TryGlobal()
+	return c
+}
+
+// Snapshot returns a view of c reading its current values, whatever the later reloads
+// and Set calls, for a component that must see a consistent configuration while it
+// works, eg: a request or a batch job. It's cheap: the values are shared, not copied.
+// Set on the view still updates c.
+func (c *GConfig) Snapshot() *GConfig {
+	return &GConfig{
+		Profile: c.Profile,
+		schema:  c.schema,
+		opts:    c.opts,
+		root:    c.base(),
+		prefix:  c.prefix,
+		pinned:  c.current(),
+	}
+}
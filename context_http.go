@@ -0,0 +1,15 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import "net/http"
+
+// Middleware attaches a view of c pinned to its current snapshot to the context of each
+// request, so every read made while handling it, through FromContext, sees the same
+// version of the configuration even if it's reloaded or Set meanwhile. Set on the view
+// still updates c; the request keeps reading the values it started with.
+func (c *GConfig) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), c.Snapshot())))
+	})
+}
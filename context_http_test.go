@@ -0,0 +1,40 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=first\ndb.host=localhost\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	handler := gcg.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cfg := FromContext(r.Context())
+		names = append(names, cfg.GetString("app.name"))
+		writeConfig(t, dir, StandardPropFileName, "app.name=reloaded\ndb.host=remote\n")
+		if err := gcg.Reload(); err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Set("db.host", "set"); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, cfg.GetString("app.name"), cfg.Sub("db").GetString("host"))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if len(names) != 3 || names[0] != "first" || names[1] != "first" || names[2] != "localhost" {
+		t.Errorf("Reads within a request should see the snapshot it started with but got %v", names)
+	}
+	if n, h := gcg.GetString("app.name"), gcg.GetString("db.host"); n != "reloaded" || h != "set" {
+		t.Errorf("The configuration should see the reload and Set but got %s, %s", n, h)
+	}
+}
@@ -0,0 +1,32 @@
+package gconfig
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewContext(t *testing.T) {
+	prev, _ := TryGlobal()
+	defer SetGlobal(prev)
+
+	global, err := NewFromMap(map[string]string{"app.name": "global"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetGlobal(nil)
+	if FromContext(context.Background()) != nil {
+		t.Error("FromContext should return nil without a configuration attached nor loaded")
+	}
+	SetGlobal(global)
+	if FromContext(context.Background()) != global {
+		t.Error("FromContext should fall back to the global configuration")
+	}
+
+	local, err := NewFromMap(map[string]string{"app.name": "local"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := FromContext(NewContext(context.Background(), local)).GetString("app.name"); n != "local" {
+		t.Errorf("FromContext should return the configuration attached to the context but got %s", n)
+	}
+}
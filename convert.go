@@ -0,0 +1,241 @@
+package gconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	s "strings"
+)
+
+// ReadFormat reads a configuration in the given format into flat keys. Nested JSON and
+// YAML mappings become dotted keys and sequences indexed ones, eg: servers[0].host, as
+// read by GetStringMapSlice. YAML is read in its common block style subset: mappings,
+// sequences, plain and quoted scalars, flow sequences of scalars, written as comma
+// separated values, and comments; anchors and multi-line scalars aren't supported.
+func ReadFormat(r io.Reader, format Format) (map[string]string, error) {
+	switch format {
+	case FormatProperties:
+		props, _, err := parseProperties(r)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(props))
+		for _, p := range props {
+			m[p.key] = p.value
+		}
+		return m, nil
+	case FormatJSON:
+		d := json.NewDecoder(r)
+		d.UseNumber()
+		var v interface{}
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+		m := make(map[string]string)
+		flatten(m, "", v)
+		return m, nil
+	case FormatYAML:
+		return readYAML(r)
+	}
+	return nil, fmt.Errorf("Unsupported configuration format %q", format)
+}
+
+// Convert reads a configuration in one format and writes it in another, eg: an
+// application.yaml into application.properties. Values are copied as-is, placeholders
+// included, and keys are written sorted.
+func Convert(w io.Writer, r io.Reader, from, to Format) error {
+	m, err := ReadFormat(r, from)
+	if err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	settings := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		keys = append(keys, k)
+		settings[k] = v
+	}
+	sort.Strings(keys)
+	return writeFormat(w, to, keys, settings)
+}
+
+// flatten adds the scalars of a decoded JSON value to m under dotted and indexed keys.
+func flatten(m map[string]string, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if key != "" {
+				k = key + "." + k
+			}
+			flatten(m, k, e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			flatten(m, fmt.Sprintf("%s[%d]", key, i), e)
+		}
+	case nil:
+		m[key] = ""
+	default:
+		m[key] = fmt.Sprint(v)
+	}
+}
+
+// yamlLevel is a mapping or sequence item being read, whose children are indented
+// deeper than indent.
+type yamlLevel struct {
+	indent int
+	key    string
+	item   bool
+}
+
+// readYAML reads the block style subset of YAML described by ReadFormat.
+func readYAML(r io.Reader) (map[string]string, error) {
+	m := make(map[string]string)
+	counters := make(map[string]int)
+	var stack []yamlLevel
+	parent := func() string {
+		if len(stack) == 0 {
+			return ""
+		}
+		return stack[len(stack)-1].key
+	}
+
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		raw := s.TrimRight(stripYAMLComment(sc.Text()), " \t")
+		l := s.TrimLeft(raw, " ")
+		if l == "" || l == "---" {
+			continue
+		}
+		if s.HasPrefix(l, "\t") {
+			return nil, &ParseError{Line: lineNo, Text: raw, Msg: "is indented with a tab"}
+		}
+		indent := len(raw) - len(l)
+
+		if l == "-" || s.HasPrefix(l, "- ") {
+			for len(stack) > 0 && (stack[len(stack)-1].indent > indent || stack[len(stack)-1].indent == indent && stack[len(stack)-1].item) {
+				stack = stack[:len(stack)-1]
+			}
+			p := parent()
+			key := fmt.Sprintf("%s[%d]", p, counters[p])
+			counters[p]++
+
+			rest := s.TrimLeft(s.TrimPrefix(l, "-"), " ")
+			if rest == "" || isYAMLMapping(rest) {
+				stack = append(stack, yamlLevel{indent: indent, key: key, item: true})
+			}
+			if rest == "" {
+				continue
+			}
+			if !isYAMLMapping(rest) {
+				v, err := yamlScalar(rest)
+				if err != nil {
+					return nil, &ParseError{Line: lineNo, Text: raw, Msg: err.Error()}
+				}
+				m[key] = v
+				continue
+			}
+			indent += len(l) - len(rest)
+			l = rest
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		k, v, err := splitYAMLMapping(l)
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Text: raw, Msg: err.Error()}
+		}
+		if p := parent(); p != "" {
+			k = p + "." + k
+		}
+		if v == "" {
+			stack = append(stack, yamlLevel{indent: indent, key: k})
+			continue
+		}
+		if m[k], err = yamlScalar(v); err != nil {
+			return nil, &ParseError{Line: lineNo, Text: raw, Msg: err.Error()}
+		}
+	}
+	return m, sc.Err()
+}
+
+// stripYAMLComment removes a comment, a # at the start of the line or following
+// whitespace outside of quotes.
+func stripYAMLComment(l string) string {
+	var quote byte
+	for i := 0; i < len(l); i++ {
+		switch c := l[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || l[i-1] == ' ' || l[i-1] == '\t'):
+			return l[:i]
+		}
+	}
+	return l
+}
+
+// isYAMLMapping reports whether l is a key: value pair rather than a scalar.
+func isYAMLMapping(l string) bool {
+	_, _, err := splitYAMLMapping(l)
+	return err == nil
+}
+
+// splitYAMLMapping splits a key: value line, unquoting the key.
+func splitYAMLMapping(l string) (string, string, error) {
+	if l[0] == '"' || l[0] == '\'' {
+		end := s.IndexByte(l[1:], l[0])
+		if end < 0 || !s.HasPrefix(l[end+2:], ":") {
+			return "", "", fmt.Errorf("is not a key: value pair")
+		}
+		k, err := yamlScalar(l[:end+2])
+		return k, s.TrimSpace(l[end+3:]), err
+	}
+
+	i := s.Index(l, ": ")
+	if i < 0 && s.HasSuffix(l, ":") {
+		i = len(l) - 1
+	}
+	if i <= 0 {
+		return "", "", fmt.Errorf("is not a key: value pair")
+	}
+	return s.TrimSpace(l[:i]), s.TrimSpace(l[i+1:]), nil
+}
+
+// yamlScalar decodes a plain, quoted or flow sequence scalar.
+func yamlScalar(v string) (string, error) {
+	switch {
+	case v == "~" || v == "null" || v == "{}":
+		return "", nil
+	case v[0] == '"':
+		return strconv.Unquote(v)
+	case v[0] == '\'':
+		if len(v) < 2 || v[len(v)-1] != '\'' {
+			return "", fmt.Errorf("has an unterminated quoted value")
+		}
+		return s.Replace(v[1:len(v)-1], "''", "'", -1), nil
+	case v[0] == '[' && v[len(v)-1] == ']':
+		var items []string
+		for _, e := range s.Split(v[1:len(v)-1], ",") {
+			if e = s.TrimSpace(e); e != "" {
+				item, err := yamlScalar(e)
+				if err != nil {
+					return "", err
+				}
+				items = append(items, item)
+			}
+		}
+		return s.Join(items, ","), nil
+	case v[0] == '|' || v[0] == '>' || v[0] == '&' || v[0] == '*' || v[0] == '{':
+		return "", fmt.Errorf("uses YAML syntax that isn't supported")
+	}
+	return v, nil
+}
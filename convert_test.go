@@ -0,0 +1,83 @@
+package gconfig
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadFormatYAML(t *testing.T) {
+	yaml := `# application.yaml
+server:
+  port: 8080   # default
+  host: "0.0.0.0"
+db:
+  url: jdbc:postgresql://${db.host}:5432/app
+  options: [ssl, 'pool # large']
+servers:
+  - host: a.example.com
+    port: 443
+  - host: b.example.com
+tags:
+- red
+- blue
+"quoted key": ~
+`
+	m, err := ReadFormat(strings.NewReader(yaml), FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"server.port":     "8080",
+		"server.host":     "0.0.0.0",
+		"db.url":          "jdbc:postgresql://${db.host}:5432/app",
+		"db.options":      "ssl,pool # large",
+		"servers[0].host": "a.example.com",
+		"servers[0].port": "443",
+		"servers[1].host": "b.example.com",
+		"tags[0]":         "red",
+		"tags[1]":         "blue",
+		"quoted key":      "",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Unexpected keys:\n%v\nexpected:\n%v", m, expected)
+	}
+
+	if _, err := ReadFormat(strings.NewReader("text: |\n  multi\n"), FormatYAML); err == nil {
+		t.Error("Multi-line scalars should be refused")
+	}
+}
+
+func TestReadFormatJSON(t *testing.T) {
+	m, err := ReadFormat(strings.NewReader(`{"server": {"port": 8080, "tls": true}, "hosts": ["a", "b"], "none": null}`), FormatJSON)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"server.port": "8080", "server.tls": "true", "hosts[0]": "a", "hosts[1]": "b", "none": ""}
+	if !reflect.DeepEqual(m, expected) {
+		t.Errorf("Unexpected keys %v", m)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Convert(&buf, strings.NewReader("db:\n  password: s3cret\n  host: localhost\n"), FormatYAML, FormatProperties); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "db.host=localhost\ndb.password=s3cret\n" {
+		t.Errorf("Unexpected properties:\n%s", buf.String())
+	}
+
+	// what WriteFormat writes as YAML reads back unchanged
+	in := map[string]string{"a.b": "x: \"y\"", "weird key": "#1"}
+	buf.Reset()
+	writeFormat(&buf, FormatYAML, []string{"a.b", "weird key"}, map[string]interface{}{"a.b": in["a.b"], "weird key": in["weird key"]})
+	m, err := ReadFormat(&buf, FormatYAML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(m, in) {
+		t.Errorf("YAML should round trip but got %v", m)
+	}
+}
@@ -0,0 +1,113 @@
+package gconfig
+
+import (
+	"fmt"
+	"sort"
+	s "strings"
+	"sync"
+)
+
+// TrackAccess records the keys read through the Get methods, Get and Unmarshal, for
+// Coverage to report the schema keys an application or a test suite never reads. Keys
+// only read through placeholders of other keys aren't recorded.
+func TrackAccess() Option {
+	return func(o *options) {
+		o.access = &accessTracker{keys: make(map[string]int)}
+	}
+}
+
+// accessTracker counts the reads of each key, shared by every view of a configuration.
+type accessTracker struct {
+	mu   sync.Mutex
+	keys map[string]int
+}
+
+func (t *accessTracker) record(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.keys[key]++
+}
+
+// KeyCoverage is the number of reads of a schema key.
+type KeyCoverage struct {
+	Key   string
+	Reads int
+}
+
+// CoverageReport lists the schema keys read and never read since the configuration was
+// loaded with TrackAccess.
+type CoverageReport struct {
+	Keys []KeyCoverage
+}
+
+// Percent returns the share of schema keys read at least once, 100 for an empty schema.
+func (r CoverageReport) Percent() float64 {
+	if len(r.Keys) == 0 {
+		return 100
+	}
+	read := 0
+	for _, k := range r.Keys {
+		if k.Reads > 0 {
+			read++
+		}
+	}
+	return 100 * float64(read) / float64(len(r.Keys))
+}
+
+// Uncovered returns the schema keys never read.
+func (r CoverageReport) Uncovered() []string {
+	var keys []string
+	for _, k := range r.Keys {
+		if k.Reads == 0 {
+			keys = append(keys, k.Key)
+		}
+	}
+	return keys
+}
+
+// String formats the report like go test -cover, one key per line, eg:
+//
+//	configuration coverage: 50.0% of 2 schema keys
+//	db.host	3 reads
+//	db.port	never read
+func (r CoverageReport) String() string {
+	var b s.Builder
+	fmt.Fprintf(&b, "configuration coverage: %.1f%% of %d schema keys\n", r.Percent(), len(r.Keys))
+	for _, k := range r.Keys {
+		if k.Reads == 0 {
+			fmt.Fprintf(&b, "%s\tnever read\n", k.Key)
+		} else {
+			fmt.Fprintf(&b, "%s\t%d reads\n", k.Key, k.Reads)
+		}
+	}
+	return b.String()
+}
+
+// Coverage reports which keys of the schema registered with WithSchema were read since
+// the configuration was loaded, eg: printed from TestMain once the tests ran. It needs
+// TrackAccess, without which every key is reported as never read.
+func (c *GConfig) Coverage() CoverageReport {
+	var r CoverageReport
+	var t *accessTracker
+	if c.opts != nil {
+		t = c.opts.access
+	}
+	for k := range c.schema {
+		kc := KeyCoverage{Key: k}
+		if t != nil {
+			t.mu.Lock()
+			kc.Reads = t.keys[c.prefix+k]
+			t.mu.Unlock()
+		}
+		r.Keys = append(r.Keys, kc)
+	}
+	sort.Slice(r.Keys, func(i, j int) bool { return r.Keys[i].Key < r.Keys[j].Key })
+	return r
+}
+
+// recordAccess records a read of key when TrackAccess is set.
+func (c *GConfig) recordAccess(key string) {
+	if c.opts != nil && c.opts.access != nil {
+		c.opts.access.record(c.prefix + key)
+	}
+}
@@ -0,0 +1,51 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.host=localhost\ndb.port=5432\ncache.size=10\nlog.level=info\n")
+	schema := Schema{
+		"db.host":    {Required: true},
+		"db.port":    {Type: TypeInt},
+		"cache.size": {Type: TypeInt},
+		"log.level":  {},
+	}
+	gcg, err := Load(WithPath(dir), WithSchema(schema), TrackAccess(), StrictMode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.Coverage().Percent(); p != 0 {
+		t.Errorf("Validation shouldn't count as reads but coverage was %.1f%%", p)
+	}
+
+	gcg.GetString("db.host")
+	gcg.Sub("db").GetInt("host")
+	if _, err := Get[int](gcg, "db.port"); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gcg.Coverage()
+	if p := r.Percent(); p != 50 {
+		t.Errorf("Expected 50%% coverage but got %.1f%%", p)
+	}
+	if u := strings.Join(r.Uncovered(), ","); u != "cache.size,log.level" {
+		t.Errorf("Unexpected uncovered keys %s", u)
+	}
+	expected := "configuration coverage: 50.0% of 4 schema keys\ncache.size\tnever read\ndb.host\t2 reads\ndb.port\t1 reads\nlog.level\tnever read\n"
+	if r.String() != expected {
+		t.Errorf("Unexpected report:\n%s", r)
+	}
+
+	untracked, err := Load(WithPath(dir), WithSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	untracked.GetString("db.host")
+	if p := untracked.Coverage().Percent(); p != 0 {
+		t.Errorf("Reads shouldn't be tracked without TrackAccess but coverage was %.1f%%", p)
+	}
+}
@@ -0,0 +1,32 @@
+package gconfig
+
+import "time"
+
+// DebugInfo describes the effective configuration, as served by Handler.
+type DebugInfo struct {
+	Profile  string    `json:"profile"`
+	LoadedAt time.Time `json:"loadedAt"`
+	// Sources maps the name of every source to the time it was last loaded.
+	Sources  map[string]time.Time   `json:"sources"`
+	Settings map[string]interface{} `json:"settings"`
+	Warnings []string               `json:"warnings,omitempty"`
+}
+
+// DebugInfo returns the effective configuration, with sensitive values masked, and
+// where and when it was loaded from.
+func (c *GConfig) DebugInfo() DebugInfo {
+	vs := c.current()
+	info := DebugInfo{
+		Profile:  c.Profile,
+		LoadedAt: vs.loaded,
+		Sources:  make(map[string]time.Time, len(vs.sources)),
+		Settings: c.AllSettings(),
+	}
+	for name, t := range vs.sources {
+		info.Sources[name] = t
+	}
+	for _, w := range c.Warnings() {
+		info.Warnings = append(info.Warnings, w.String())
+	}
+	return info
+}
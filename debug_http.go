@@ -0,0 +1,47 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+)
+
+// Handler returns an http.Handler serving the DebugInfo of c as JSON, meant to be
+// mounted under a protected path such as /debug/config:
+//
+//	http.Handle("/debug/config", cfg.Handler())
+func (c *GConfig) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		serveDebugInfo(w, c)
+	})
+}
+
+// Handler returns an http.Handler serving the DebugInfo of the global configuration,
+// or 503 Service Unavailable until it's loaded.
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := TryGlobal()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		serveDebugInfo(w, c)
+	})
+}
+
+func serveDebugInfo(w http.ResponseWriter, c *GConfig) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(c.DebugInfo())
+}
+
+// Var returns an expvar.Var reporting the DebugInfo of c, eg:
+// expvar.Publish("config", cfg.Var()) lists it under /debug/vars.
+func (c *GConfig) Var() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return c.DebugInfo()
+	})
+}
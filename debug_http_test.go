@@ -0,0 +1,66 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.password=hunter22\n")
+	writeConfig(t, dir, "application-prod.properties", "app.name=prod\n")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := httptest.NewRecorder()
+	gcg.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Unexpected content type %s", ct)
+	}
+	if strings.Contains(rec.Body.String(), "hunter22") {
+		t.Error("Sensitive values should be masked")
+	}
+
+	var info DebugInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Profile != "prod" || info.Settings["app.name"] != "prod" {
+		t.Errorf("Unexpected profile or settings %v", info)
+	}
+	if _, ok := info.Sources["application-prod.properties"]; !ok || len(info.Sources) != 2 {
+		t.Errorf("Both files should be listed as sources but got %v", info.Sources)
+	}
+	if info.LoadedAt.IsZero() {
+		t.Error("The load timestamp should be set")
+	}
+	if !strings.Contains(gcg.Var().String(), `"profile":"prod"`) {
+		t.Errorf("Unexpected expvar %s", gcg.Var().String())
+	}
+}
+
+func TestGlobalHandler(t *testing.T) {
+	SetGlobal(nil)
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 before Load but got %d", rec.Code)
+	}
+
+	if _, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithDefaults(map[string]string{"a": "b"})); err != nil {
+		t.Fatal(err)
+	}
+	rec = httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/config", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"a": "b"`) {
+		t.Errorf("Expected the global configuration but got %d %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,117 @@
+package gconfig
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// The helpers in this file make GConfig easy to wire into dependency injection containers
+// such as google/wire and uber/fx without gconfig depending on either of them.
+//
+// With fx:
+//
+//	fx.New(
+//		fx.Provide(gconfig.Provider(gconfig.WithProfile("prod"))),
+//		fx.Provide(fx.Annotate(gconfig.SubProvider("database"), fx.ResultTags(`name:"database"`))),
+//		fx.Invoke(func(lc fx.Lifecycle, c *gconfig.GConfig) {
+//			r := gconfig.NewReloader(c, time.Minute)
+//			lc.Append(fx.Hook{OnStart: r.Start, OnStop: r.Stop})
+//		}),
+//	)
+//
+// With wire:
+//
+//	wire.Build(gconfig.ProvideWithOptions, ...)
+
+// Options is a list of Option values, injectable as a single dependency.
+type Options []Option
+
+// Provide loads the configuration from the flags and environment variables, like Load.
+func Provide() (*GConfig, error) {
+	return Load()
+}
+
+// ProvideWithOptions loads the configuration using the injected options.
+func ProvideWithOptions(opts Options) (*GConfig, error) {
+	return Load(opts...)
+}
+
+// Provider returns a constructor loading the configuration with the given options.
+func Provider(opts ...Option) func() (*GConfig, error) {
+	return func() (*GConfig, error) {
+		return Load(opts...)
+	}
+}
+
+// SubProvider returns a constructor deriving the Sub view for prefix from an injected GConfig.
+func SubProvider(prefix string) func(*GConfig) *GConfig {
+	return func(c *GConfig) *GConfig {
+		return c.Sub(prefix)
+	}
+}
+
+// Reloader periodically reloads a configuration. Its Start and Stop methods match the
+// lifecycle hook signatures used by DI containers.
+type Reloader struct {
+	c        *GConfig
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReloader returns a Reloader calling c.Reload every interval once started.
+func NewReloader(c *GConfig, interval time.Duration) *Reloader {
+	return &Reloader{c: c, interval: interval}
+}
+
+// Start begins reloading in the background. Calling Start on a running Reloader is a no-op.
+func (r *Reloader) Start(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.cancel != nil {
+		return nil
+	}
+
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.done = make(chan struct{})
+	go r.run(ctx, r.done)
+	return nil
+}
+
+// Stop ends the background reloading and waits for it to finish, or for ctx to be done.
+func (r *Reloader) Stop(ctx context.Context) error {
+	r.mu.Lock()
+	cancel, done := r.cancel, r.done
+	r.cancel, r.done = nil, nil
+	r.mu.Unlock()
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Reloader) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+	t := time.NewTicker(r.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if err := r.c.Reload(); err != nil {
+				r.c.logf("Error reloading configuration: %s\n", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package gconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestProvider(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "database.url=postgres://localhost/app\n")
+
+	gcg, err := Provider(WithPath(dir))()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := SubProvider("database")(gcg)
+	if u := db.GetString("url"); u != "postgres://localhost/app" {
+		t.Errorf("Sub provider should scope to database but url was %s", u)
+	}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=before\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewReloader(gcg, 5*time.Millisecond)
+	if err := r.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, dir, StandardPropFileName, "app.name=after\n")
+
+	deadline := time.Now().Add(time.Second)
+	for gcg.GetString("app.name") != "after" && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if err := r.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "after" {
+		t.Errorf("Reloader should pick up the new value but got %s", n)
+	}
+}
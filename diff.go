@@ -0,0 +1,103 @@
+package gconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	s "strings"
+)
+
+// ConfigDiff lists the keys that differ between two configurations, each list sorted.
+type ConfigDiff struct {
+	// Added lists the keys only the other configuration defines.
+	Added []string
+	// Removed lists the keys only this configuration defines.
+	Removed []string
+	// Changed lists the keys both define with different resolved values.
+	Changed []string
+}
+
+// Empty reports whether both configurations define the same keys with the same values.
+func (d *ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// SameKeys reports whether both configurations define the same set of keys, whatever
+// their values, eg: to assert in CI that prod and staging haven't drifted apart.
+func (d *ConfigDiff) SameKeys() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+func (d *ConfigDiff) String() string {
+	var parts []string
+	if len(d.Added) > 0 {
+		parts = append(parts, fmt.Sprintf("added %s", s.Join(d.Added, ", ")))
+	}
+	if len(d.Removed) > 0 {
+		parts = append(parts, fmt.Sprintf("removed %s", s.Join(d.Removed, ", ")))
+	}
+	if len(d.Changed) > 0 {
+		parts = append(parts, fmt.Sprintf("changed %s", s.Join(d.Changed, ", ")))
+	}
+	if len(parts) == 0 {
+		return "no difference"
+	}
+	return s.Join(parts, "; ")
+}
+
+// Diff loads the configuration of another profile with the options of c and compares
+// it with c, eg: cfg.Diff("staging") on the prod configuration.
+func (c *GConfig) Diff(otherProfile string) (*ConfigDiff, error) {
+	b := c.base()
+	var o options
+	if b.opts != nil {
+		o = *b.opts
+	}
+	o.profile = otherProfile
+	other, err := load(context.Background(), &o)
+	if err != nil {
+		return nil, err
+	}
+	if c.prefix != "" {
+		other = other.Sub(s.TrimSuffix(c.prefix, "."))
+	}
+	return c.DiffWith(other), nil
+}
+
+// Compare loads the configurations found in two directories with the same options,
+// eg: WithProfile, and returns what the one in pathB changes from the one in pathA.
+func Compare(pathA, pathB string, opts ...Option) (*ConfigDiff, error) {
+	a, err := load(context.Background(), newOptions(append(opts, WithPath(pathA))))
+	if err != nil {
+		return nil, err
+	}
+	b, err := load(context.Background(), newOptions(append(opts, WithPath(pathB))))
+	if err != nil {
+		return nil, err
+	}
+	return a.DiffWith(b), nil
+}
+
+// DiffWith returns the keys that differ between c and the other configuration.
+func (c *GConfig) DiffWith(other *GConfig) *ConfigDiff {
+	a, b := c, other
+	d := new(ConfigDiff)
+	bkeys := make(map[string]bool)
+	for _, k := range b.keys() {
+		bkeys[k] = true
+	}
+	for _, k := range a.keys() {
+		switch {
+		case !bkeys[k]:
+			d.Removed = append(d.Removed, k)
+		case a.replaceSysVars(k) != b.replaceSysVars(k):
+			d.Changed = append(d.Changed, k)
+		}
+		delete(bkeys, k)
+	}
+	for k := range bkeys {
+		d.Added = append(d.Added, k)
+	}
+	sort.Strings(d.Added)
+	return d
+}
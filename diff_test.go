@@ -0,0 +1,58 @@
+package gconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.host=localhost\ndb.url=pg://${db.host}\napp.name=gconfig\n")
+	writeConfig(t, dir, "application-prod.properties", "db.host=db.prod\ndb.pool=20\n")
+	writeConfig(t, dir, "application-staging.properties", "db.host=db.staging\ncache.ttl=1m\n")
+
+	prod, err := Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := prod.Diff("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ConfigDiff{Added: []string{"cache.ttl"}, Removed: []string{"db.pool"}, Changed: []string{"db.host", "db.url"}}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("Unexpected diff %v", d)
+	}
+	if d.SameKeys() || d.Empty() {
+		t.Error("Profiles with different keys shouldn't be reported the same")
+	}
+	if prod.Profile != "prod" || prod.GetString("db.host") != "db.prod" {
+		t.Error("Diff shouldn't change the configuration it's called on")
+	}
+
+	d, err = prod.Sub("db").Diff("staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.String() != "removed pool; changed host, url" {
+		t.Errorf("Diff on a view should compare the keys of the view: %v", d)
+	}
+
+	if d, _ := prod.Diff("prod"); !d.Empty() {
+		t.Errorf("A profile should have no difference with itself: %v", d)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	a, b := t.TempDir(), t.TempDir()
+	writeConfig(t, a, StandardPropFileName, "x=1\ny=2\n")
+	writeConfig(t, b, StandardPropFileName, "y=2\nx=3\n")
+
+	d, err := Compare(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !d.SameKeys() || len(d.Changed) != 1 || d.Changed[0] != "x" {
+		t.Errorf("Unexpected diff %v", d)
+	}
+}
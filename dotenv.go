@@ -0,0 +1,164 @@
+package gconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	s "strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DotEnvFileName is the dotenv file read from the configuration directory by DotEnv.
+const DotEnvFileName = ".env"
+
+// DotEnv reads the dotenv files .env and .env.{profile} from the configuration directory,
+// the profile file winning. Their variables are mapped to keys the same way as EnvOnly,
+// DB_URL being db.url, restricted to the WithEnvPrefix prefix when one is set. The
+// variables take precedence over the properties files but not over the real environment,
+// and ${NAME} placeholders see them as environment variables. Missing files are ignored.
+func DotEnv() Option {
+	return func(o *options) {
+		o.dotEnv = true
+	}
+}
+
+// ExportDotEnv is DotEnv that also sets the dotenv variables in the process environment,
+// for code reading them with os.Getenv. Variables already set are left untouched.
+func ExportDotEnv() Option {
+	return func(o *options) {
+		o.dotEnv = true
+		o.exportDotEnv = true
+	}
+}
+
+// loadDotEnv reads the dotenv files of the configuration directory p into c.
+func (c *GConfig) loadDotEnv(fsys fileSystem, p string, o *options) error {
+	names := []string{DotEnvFileName}
+	if c.Profile != "" {
+		names = append(names, DotEnvFileName+"."+c.Profile)
+	}
+
+	vars := make(map[string]string)
+	from := make(map[string]string)
+	for _, name := range names {
+		f, err := fsys.open(fsys.join(p, name))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Error opening dotenv file %s", name)
+		}
+		kv, err := parseDotEnv(f)
+		f.Close()
+		if perr, ok := err.(*ParseError); ok {
+			perr.File = name
+			return c.redactParseError(perr)
+		}
+		if err != nil {
+			return errors.Wrapf(err, "Error reading dotenv file %s", name)
+		}
+		for k, v := range kv {
+			vars[k] = v
+			from[k] = name
+		}
+		c.v.sources[name] = time.Now()
+	}
+
+	if o.exportDotEnv && !o.noEnv {
+		for k, v := range vars {
+			if _, ok := os.LookupEnv(k); !ok {
+				os.Setenv(k, v)
+			}
+		}
+	}
+
+	c.v.dotEnvVars = vars
+	c.v.dotEnv = make(map[string]string, len(vars))
+	c.v.dotEnvFrom = make(map[string]string, len(vars))
+	prefix := ""
+	if o.envPrefix != "" {
+		prefix = o.envPrefix + "_"
+	}
+	for k, v := range vars {
+		if s.HasPrefix(k, prefix) && len(k) > len(prefix) {
+			c.v.dotEnv[envKey(k[len(prefix):])] = v
+			c.v.dotEnvFrom[envKey(k[len(prefix):])] = from[k]
+		}
+	}
+	return nil
+}
+
+// parseDotEnv reads variables in the dotenv format: NAME=value lines, optionally prefixed
+// by "export ", with # comments. Single quoted values are taken literally, double quoted
+// ones decode \n, \t, \" and \\ escapes and unquoted ones end at a # preceded by a space.
+func parseDotEnv(r io.Reader) (map[string]string, error) {
+	vars := make(map[string]string)
+	sc := bufio.NewScanner(r)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		l := s.TrimSpace(sc.Text())
+		if l == "" || l[0] == '#' {
+			continue
+		}
+		l = s.TrimPrefix(l, "export ")
+
+		i := s.Index(l, "=")
+		if i <= 0 {
+			return nil, &ParseError{Line: lineNo, Key: s.Fields(l)[0], Text: l, Msg: "is not a NAME=value pair"}
+		}
+		name := s.TrimSpace(l[:i])
+		value, err := dotEnvValue(s.TrimSpace(l[i+1:]))
+		if err != nil {
+			return nil, &ParseError{Line: lineNo, Key: name, Msg: fmt.Sprintf("%s has an %s", name, err)}
+		}
+		vars[name] = value
+	}
+	return vars, sc.Err()
+}
+
+// dotEnvValue decodes the value of a dotenv variable.
+func dotEnvValue(v string) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	switch v[0] {
+	case '\'':
+		end := s.Index(v[1:], "'")
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+		return v[1 : end+1], nil
+	case '"':
+		var sb s.Builder
+		for i := 1; i < len(v); i++ {
+			switch {
+			case v[i] == '"':
+				return sb.String(), nil
+			case v[i] == '\\' && i+1 < len(v):
+				i++
+				switch v[i] {
+				case 'n':
+					sb.WriteByte('\n')
+				case 't':
+					sb.WriteByte('\t')
+				case 'r':
+					sb.WriteByte('\r')
+				default:
+					sb.WriteByte(v[i])
+				}
+			default:
+				sb.WriteByte(v[i])
+			}
+		}
+		return "", errors.New("unterminated double quote")
+	}
+	if i := s.Index(v, " #"); i >= 0 {
+		v = s.TrimSpace(v[:i])
+	}
+	return v, nil
+}
@@ -0,0 +1,80 @@
+package gconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestParseDotEnv(t *testing.T) {
+	content := `# local settings
+DB_URL=postgres://localhost/app
+export API_KEY = abc123
+GREETING="hello\nworld" # comment
+RAW='no $expansion \n here'
+EMPTY=
+PORT=8080 # inline comment
+`
+	vars, err := parseDotEnv(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{
+		"DB_URL":   "postgres://localhost/app",
+		"API_KEY":  "abc123",
+		"GREETING": "hello\nworld",
+		"RAW":      `no $expansion \n here`,
+		"EMPTY":    "",
+		"PORT":     "8080",
+	}
+	for k, v := range expected {
+		if vars[k] != v {
+			t.Errorf("Variable %s should be %q but was %q", k, v, vars[k])
+		}
+	}
+
+	_, err = parseDotEnv(strings.NewReader("A=1\nB=\"open\n"))
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 2 {
+		t.Errorf("Unterminated quotes should be reported with their line but got %v", err)
+	}
+}
+
+func TestDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.url=jdbc:file\napp.name=gconfig\ndb.password=${GCTEST_DB_PASSWORD|none}\n")
+	writeConfig(t, dir, ".env", "DB_URL=postgres://localhost/app\nAPP_PORT=8080\nGCTEST_DB_PASSWORD=secret\n")
+	writeConfig(t, dir, ".env.dev", "APP_PORT=9090\n")
+	defer os.Unsetenv("GCTEST_DB_PASSWORD")
+
+	gcg, err := Load(WithPath(dir), WithProfile("dev"), DotEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := gcg.GetString("db.url"); u != "postgres://localhost/app" {
+		t.Errorf("Dotenv variables should override the files but db.url was %s", u)
+	}
+	if p := gcg.GetInt("app.port"); p != 9090 {
+		t.Errorf("The profile dotenv file should win but app.port was %d", p)
+	}
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("Keys missing from dotenv should come from the files but app.name was %s", n)
+	}
+	if p := gcg.GetString("db.password"); p != "secret" {
+		t.Errorf("Placeholders should see dotenv variables but db.password was %s", p)
+	}
+	if _, ok := os.LookupEnv("GCTEST_DB_PASSWORD"); ok {
+		t.Error("DotEnv alone shouldn't export the variables")
+	}
+
+	os.Setenv("APP_PORT", "7070")
+	defer os.Unsetenv("APP_PORT")
+	if _, err := Load(WithPath(dir), WithProfile("dev"), ExportDotEnv()); err != nil {
+		t.Fatal(err)
+	}
+	if v := os.Getenv("GCTEST_DB_PASSWORD"); v != "secret" {
+		t.Errorf("ExportDotEnv should set the variables but GCTEST_DB_PASSWORD was %q", v)
+	}
+	if v := os.Getenv("APP_PORT"); v != "7070" {
+		t.Errorf("ExportDotEnv shouldn't override the environment but APP_PORT was %s", v)
+	}
+}
@@ -0,0 +1,112 @@
+package gconfig
+
+import (
+	"os"
+	"sort"
+	s "strings"
+
+	"github.com/pkg/errors"
+)
+
+// readEnv returns the configuration defined by the environment variables starting with
+// prefix and an underscore, keyed by their configuration key.
+func readEnv(prefix string) map[string]string {
+	p := prefix + "_"
+	values := make(map[string]string)
+	for _, kv := range os.Environ() {
+		i := s.Index(kv, "=")
+		if i < 0 || !s.HasPrefix(kv[:i], p) || i == len(p) {
+			continue
+		}
+		values[envKey(kv[len(p):i])] = kv[i+1:]
+	}
+	return values
+}
+
+// envKey converts an environment variable name, without its prefix, into a configuration
+// key: DB_URL becomes db.url and DB_MAX__CONNS becomes db.max_conns.
+func envKey(name string) string {
+	parts := s.Split(s.ToLower(name), "__")
+	for i, part := range parts {
+		parts[i] = s.Replace(part, "_", ".", -1)
+	}
+	return s.Join(parts, "_")
+}
+
+// EnvName returns the environment variable WithEnvPrefix(prefix) reads key from, and
+// Environ exports it as: db.max_conns becomes PREFIX_DB_MAX__CONNS, or DB_MAX__CONNS
+// without a prefix.
+func EnvName(prefix, key string) string {
+	return envName(prefix, key)
+}
+
+// envName converts a configuration key into the environment variable read for it with
+// prefix, the reverse of envKey.
+func envName(prefix, key string) string {
+	name := s.ToUpper(s.Replace(s.Replace(key, "_", "__", -1), ".", "_", -1))
+	if prefix == "" {
+		return name
+	}
+	return prefix + "_" + name
+}
+
+// Environ returns the effective configuration as sorted KEY=VALUE pairs, for the Env of
+// an exec.Cmd launching a process that only reads environment variables, eg:
+//
+//	env, err := cfg.Environ("APP")
+//	cmd.Env = append(os.Environ(), env...)
+//
+// Keys are named as WithEnvPrefix reads them, upper cased with dots turned into
+// underscores and underscores doubled after prefix and an underscore: db.max_conns
+// becomes APP_DB_MAX__CONNS, so a child process loading its configuration
+// WithEnvPrefix("APP") gets the same keys back. Keys that wouldn't, as they hold other
+// characters than lower case letters, digits, dots and underscores, eg: db.maxConns or
+// db.max-conns, are left out and reported by the error, along with the pairs of the
+// others. Placeholders are resolved, and sensitive values aren't masked: the child needs
+// them.
+func (c *GConfig) Environ(prefix string) ([]string, error) {
+	var env, skipped []string
+	for _, k := range c.keys() {
+		if !envSafe(k) {
+			skipped = append(skipped, k)
+			continue
+		}
+		env = append(env, envName(prefix, k)+"="+c.replaceSysVars(k))
+	}
+	sort.Strings(env)
+	if len(skipped) > 0 {
+		return env, errors.Errorf("Keys %s can't be named as environment variables", s.Join(skipped, ", "))
+	}
+	return env, nil
+}
+
+// envSafe reports whether key is named by a portable environment variable reading back
+// as key, see envKey.
+func envSafe(key string) bool {
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '_') {
+			return false
+		}
+	}
+	return key != ""
+}
+
+// ExportToEnv sets the environment variables returned by Environ in the current
+// process, under prefix, so every child process started afterwards inherits them. It
+// fails when prefix is the one the configuration reads, see WithEnvPrefix: the exported
+// values would then take precedence over the files on every later load and reload,
+// freezing the configuration. Like Environ, it exports the keys it can before reporting
+// the others.
+func (c *GConfig) ExportToEnv(prefix string) error {
+	if o := c.base().opts; o != nil && o.envPrefix != "" && o.envPrefix == prefix {
+		return errors.Errorf("Exporting under %s, the prefix the configuration reads, would override its files", prefix)
+	}
+	env, err := c.Environ(prefix)
+	for _, kv := range env {
+		i := s.Index(kv, "=")
+		if serr := os.Setenv(kv[:i], kv[i+1:]); serr != nil {
+			return errors.Wrapf(serr, "Error exporting %s", kv[:i])
+		}
+	}
+	return err
+}
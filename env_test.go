@@ -0,0 +1,136 @@
+package gconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestEnvKey(t *testing.T) {
+	tests := map[string]string{
+		"DB_URL":          "db.url",
+		"DB_MAX__CONNS":   "db.max_conns",
+		"SERVER_PORT":     "server.port",
+		"FEATURE__A_FLAG": "feature_a.flag",
+	}
+	for name, want := range tests {
+		if got := envKey(name); got != want {
+			t.Errorf("envKey(%s) = %s, want %s", name, got, want)
+		}
+		if got := envName("APP", want); got != "APP_"+name {
+			t.Errorf("envName(%s) = %s, want APP_%s", want, got, name)
+		}
+	}
+}
+
+func TestEnvOnly(t *testing.T) {
+	os.Setenv("GCTEST_DB_URL", "postgres://db/app")
+	os.Setenv("GCTEST_SERVER_PORT", "9090")
+	defer os.Unsetenv("GCTEST_DB_URL")
+	defer os.Unsetenv("GCTEST_SERVER_PORT")
+
+	gcg, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithDefaults(map[string]string{
+		"server.port": "8080",
+		"server.host": "0.0.0.0",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u := gcg.GetString("db.url"); u != "postgres://db/app" {
+		t.Errorf("Key db.url should come from the environment but was %s", u)
+	}
+	if p := gcg.GetInt("server.port"); p != 9090 {
+		t.Errorf("Environment should override defaults but server.port was %d", p)
+	}
+	if h := gcg.GetString("server.host"); h != "0.0.0.0" {
+		t.Errorf("Key server.host should use the default but was %s", h)
+	}
+}
+
+func TestWithEnvPrefix(t *testing.T) {
+	os.Setenv("GCTEST_APP_NAME", "from env")
+	defer os.Unsetenv("GCTEST_APP_NAME")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg, err := Load(WithPath(wd+"/config"), WithProfile("dev"), WithEnvPrefix("GCTEST"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "from env" {
+		t.Errorf("Environment should override the profile file but app.name was %s", n)
+	}
+	if u := gcg.GetString("app.url"); u != "https://github.com/narup/gconfig-dev" {
+		t.Errorf("Key app.url should still come from the profile file but was %s", u)
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	c, err := NewFromMap(map[string]string{
+		"db.host":      "localhost",
+		"db.url":       "postgres://${db.host}/app",
+		"db.max_conns": "10",
+		"db.password":  "secret",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Environ("APP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"APP_DB_HOST=localhost", "APP_DB_MAX__CONNS=10", "APP_DB_PASSWORD=secret", "APP_DB_URL=postgres://localhost/app"}
+	if len(got) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Expected %v, got %v", want, got)
+		}
+	}
+	if env, _ := c.Environ(""); env[0] != "DB_HOST=localhost" {
+		t.Errorf("Expected no prefix, got %s", env[0])
+	}
+
+	c, err = NewFromMap(map[string]string{"db.host": "localhost", "db.maxConns": "10", "db.max-idle": "2"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := c.Environ("APP")
+	if err == nil || !strings.Contains(err.Error(), "db.maxConns") || !strings.Contains(err.Error(), "db.max-idle") {
+		t.Errorf("Keys that don't round trip should be reported, got %v", err)
+	}
+	if len(env) != 1 || env[0] != "APP_DB_HOST=localhost" {
+		t.Errorf("The other keys should be returned, got %v", env)
+	}
+}
+
+func TestExportToEnv(t *testing.T) {
+	defer os.Unsetenv("GCEXPORT_SERVER_PORT")
+	c, err := NewFromMap(map[string]string{"server.port": "9090"}, "", WithEnvPrefix("GCCONFIG"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.ExportToEnv("GCEXPORT"); err != nil {
+		t.Fatal(err)
+	}
+	if v := os.Getenv("GCEXPORT_SERVER_PORT"); v != "9090" {
+		t.Fatalf("Expected 9090, got %q", v)
+	}
+
+	// a child loading with the same prefix reads the same keys back
+	if env := readEnv("GCEXPORT"); env["server.port"] != "9090" {
+		t.Fatalf("Expected server.port to round trip, got %v", env)
+	}
+
+	if err := c.ExportToEnv("GCCONFIG"); err == nil {
+		t.Error("Exporting under the prefix the configuration reads should fail")
+	}
+	if _, ok := os.LookupEnv("GCCONFIG_SERVER_PORT"); ok {
+		t.Error("Nothing should be exported under the prefix the configuration reads")
+	}
+}
@@ -0,0 +1,89 @@
+package gconfig
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// envCall matches an env('NAME') call inside a #{expr} expression.
+var envCall = regexp.MustCompile(`\benv\(\s*['"]([^'"]+)['"]\s*\)`)
+
+// EnvReference is an environment variable the properties files depend on.
+type EnvReference struct {
+	Name string
+	// Required is set when at least one reference has no default value.
+	Required bool
+	// Defaults lists the distinct defaults of the references, in order of appearance.
+	Defaults []string
+	// Refs lists where the variable is referenced, as file:line.
+	Refs []string
+}
+
+// EnvReferences lists the environment variables referenced by the ${...} placeholders
+// and env() expression calls of every properties file in dir, across all profiles,
+// sorted by name. A placeholder naming a key, or a key's default entry, defined by any
+// of the files is a key reference and isn't listed. It's meant for generating or checking deployment
+// manifests, eg: Kubernetes env blocks or systemd units, against what the
+// configuration actually needs.
+func EnvReferences(dir string) ([]EnvReference, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool)
+	for _, f := range files {
+		for _, p := range f.props {
+			keys[p.key] = true
+		}
+	}
+
+	refs := make(map[string]*EnvReference)
+	add := func(name, def, at string) {
+		r := refs[name]
+		if r == nil {
+			r = &EnvReference{Name: name}
+			refs[name] = r
+		}
+		if def == "" {
+			r.Required = true
+		} else if !contains(r.Defaults, def) {
+			r.Defaults = append(r.Defaults, def)
+		}
+		if !contains(r.Refs, at) {
+			r.Refs = append(r.Refs, at)
+		}
+	}
+	for _, f := range files {
+		for _, p := range f.props {
+			at := fmt.Sprintf("%s:%d", f.name, p.line)
+			for _, ph := range placeholder.FindAllString(p.value, -1) {
+				if name, def := splitPlaceholder(ph); !keys[name] && !keys[name+DefaultKeySuffix] && !isFunction(name) {
+					add(name, def, at)
+				}
+			}
+			for _, e := range expression.FindAllString(p.value, -1) {
+				for _, m := range envCall.FindAllStringSubmatch(e, -1) {
+					add(m[1], "", at)
+				}
+			}
+		}
+	}
+
+	list := make([]EnvReference, 0, len(refs))
+	for _, r := range refs {
+		list = append(list, *r)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, e := range list {
+		if e == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,29 @@
+package gconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEnvReferences(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `db.host=${DB_HOST|localhost}
+db.url=postgres://${db.host}/${DB_NAME}
+log.level=#{env('LOG_LEVEL')}
+`)
+	writeConfig(t, dir, "application-prod.properties", "db.host=${DB_HOST|db.internal}\ndb.password=${DB_PASSWORD}\n")
+
+	refs, err := EnvReferences(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []EnvReference{
+		{Name: "DB_HOST", Defaults: []string{"db.internal", "localhost"}, Refs: []string{"application-prod.properties:1", "application.properties:1"}},
+		{Name: "DB_NAME", Required: true, Refs: []string{"application.properties:2"}},
+		{Name: "DB_PASSWORD", Required: true, Refs: []string{"application-prod.properties:2"}},
+		{Name: "LOG_LEVEL", Required: true, Refs: []string{"application.properties:3"}},
+	}
+	if !reflect.DeepEqual(refs, expected) {
+		t.Errorf("Unexpected references:\n%+v\nexpected:\n%+v", refs, expected)
+	}
+}
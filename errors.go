@@ -0,0 +1,83 @@
+package gconfig
+
+import (
+	"fmt"
+	"io/fs"
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Errors are matched with errors.Is rather than by their message, which wraps them with
+// the key or path involved:
+//
+//	if _, err := gconfig.Get[int](cfg, "db.pool"); errors.Is(err, gconfig.ErrKeyNotFound) {
+//
+// and their details are read with errors.As from the error types: *MissingKeysError,
+// *TypeError, *ParseError, *ValidationError, *ConflictError, *StaleError, *LintError and
+// *MissingEnvError.
+var (
+	// ErrKeyNotFound is matched by the *MissingKeysError returned when a key isn't
+	// defined, eg: by Get, Require or StrictMode.
+	ErrKeyNotFound = errors.New("gconfig: key not found")
+	// ErrTypeMismatch is matched by the *TypeError returned when a value doesn't convert
+	// to the type asked for, eg: by Get or Unmarshal.
+	ErrTypeMismatch = errors.New("gconfig: value doesn't convert to the requested type")
+	// ErrFileNotFound is matched when no properties file is found, or when a
+	// configuration directory or an imported file doesn't exist.
+	ErrFileNotFound = errors.New("gconfig: configuration file not found")
+)
+
+// TypeError reports a value that doesn't convert to the type asked for. The value isn't
+// included, as it may be sensitive.
+type TypeError struct {
+	Key string
+	// Type is the type the value was converted to, eg: int or time.Duration.
+	Type string
+	Err  error
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("Invalid value for key %s: %s", e.Key, e.Err)
+}
+
+// Is makes errors.Is(err, ErrTypeMismatch) true for every *TypeError.
+func (e *TypeError) Is(target error) bool {
+	return target == ErrTypeMismatch
+}
+
+// Unwrap returns the conversion error, eg: a *strconv.NumError.
+func (e *TypeError) Unwrap() error {
+	return e.Err
+}
+
+// typeError wraps the error converting the value of key to t.
+func typeError(key string, t reflect.Type, err error) error {
+	return &TypeError{Key: key, Type: t.String(), Err: err}
+}
+
+// Is makes errors.Is(err, ErrKeyNotFound) true for every *MissingKeysError.
+func (e *MissingKeysError) Is(target error) bool {
+	return target == ErrKeyNotFound
+}
+
+// notFoundError marks an error reporting a missing file as matching ErrFileNotFound.
+type notFoundError struct {
+	error
+}
+
+func (e notFoundError) Is(target error) bool {
+	return target == ErrFileNotFound
+}
+
+func (e notFoundError) Unwrap() error {
+	return e.error
+}
+
+// fileError makes err match ErrFileNotFound when it reports a file that doesn't exist.
+func fileError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return notFoundError{err}
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package gconfig
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestErrorTaxonomy(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"db.pool": "ten", "db.timeout": "5s", "cache.size": "lots"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Get[int](gcg, "db.missing")
+	var missing *MissingKeysError
+	if !errors.Is(err, ErrKeyNotFound) || !errors.As(err, &missing) || missing.Keys[0] != "db.missing" {
+		t.Errorf("Expected a *MissingKeysError matching ErrKeyNotFound but got %v", err)
+	}
+	if err := gcg.Require("db.pool", "db.url"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Require should match ErrKeyNotFound but got %v", err)
+	}
+
+	_, err = Get[int](gcg, "db.pool")
+	var terr *TypeError
+	if !errors.Is(err, ErrTypeMismatch) || !errors.As(err, &terr) || terr.Key != "db.pool" || terr.Type != "int" {
+		t.Errorf("Expected a *TypeError for db.pool matching ErrTypeMismatch but got %v", err)
+	}
+	var numErr *strconv.NumError
+	if !errors.As(err, &numErr) {
+		t.Error("The conversion error should be unwrapped")
+	}
+	if _, err := gcg.GetSizeInBytes("cache.size"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("GetSizeInBytes should match ErrTypeMismatch but got %v", err)
+	}
+	var cfg struct {
+		Pool int `gconfig:"db.pool"`
+	}
+	if err := gcg.Unmarshal(&cfg); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("Unmarshal should match ErrTypeMismatch but got %v", err)
+	}
+	if errors.Is(err, ErrKeyNotFound) {
+		t.Error("A type mismatch should not match ErrKeyNotFound")
+	}
+
+	dir := t.TempDir()
+	_, err = Load(WithPath(filepath.Join(dir, "missing")+string(filepath.ListSeparator)+dir), WithoutFlags(), WithoutEnv())
+	if !errors.Is(err, ErrFileNotFound) || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("A missing directory should match ErrFileNotFound and fs.ErrNotExist but got %v", err)
+	}
+	writeConfig(t, dir, StandardPropFileName, "gconfig.import=missing.properties\n")
+	if _, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv()); !errors.Is(err, ErrFileNotFound) {
+		t.Errorf("A missing import should match ErrFileNotFound but got %v", err)
+	}
+	if _, err := Load(WithPath(t.TempDir()), WithoutFlags(), WithoutEnv()); !errors.Is(err, ErrFileNotFound) || !errors.Is(err, ErrConfigFileRequired) {
+		t.Errorf("An empty directory should match ErrFileNotFound but got %v", err)
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\nbroken \\uZZZZ=1\n")
+	_, err = Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.File != StandardPropFileName || perr.Line != 2 {
+		t.Errorf("Expected a *ParseError at %s:2 but got %v", StandardPropFileName, err)
+	}
+}
@@ -0,0 +1,142 @@
+package gconfig
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Explanation traces how the value of a key is resolved, as returned by Explain.
+// Sensitive values are masked throughout, including in values embedding them.
+type Explanation struct {
+	Key       string
+	Defined   bool
+	Sensitive bool
+	// Definitions lists every layer defining the key, from the highest precedence to the
+	// lowest: the first one provides the raw value.
+	Definitions []Definition
+	// Expansions lists how each placeholder of the raw value was resolved.
+	Expansions []Expansion
+	// Reference is the scheme of the Resolver the expanded value was resolved with, if any.
+	Reference string
+	// FromDefaultKey tells the value is the one of key.default, see DefaultKeySuffix.
+	FromDefaultKey bool
+	// Value is the effective value, as GetString returns it.
+	Value string
+}
+
+// Definition is the raw value a layer defines for a key.
+type Definition struct {
+	Origin
+	// Key is the name the layer defines the value under, which differs from the key
+	// explained for aliases and normalized names.
+	Key   string
+	Value string
+}
+
+// Expansion describes how a ${...} placeholder was resolved.
+type Expansion struct {
+	Placeholder string
+	Name        string
+	// From is "key" when the placeholder names a configuration key, "env" for an
+	// environment variable, "function" for a function such as ${uuid}, "default" for the
+	// default written in the placeholder, "key default" for the name.default key and
+	// empty when it resolved to nothing.
+	From  string
+	Value string
+}
+
+// Explain returns the trace of the resolution of key: the layers defining it, the
+// expansion of its placeholders and its effective value, eg: for an admin endpoint or
+// the explain command. Placeholders are expanded one level deep; explain the keys they
+// name to follow them further. Reading a key through Explain doesn't count as an access.
+func (c *GConfig) Explain(key string) Explanation {
+	abs := c.prefix + key
+	vs := c.current()
+	e := Explanation{Key: key, Sensitive: c.IsSensitive(key)}
+	for _, d := range vs.definitions(abs, c.Profile) {
+		if e.Sensitive {
+			d.Value = c.mask(key, d.Value)
+		} else {
+			d.Value = c.base().mask(d.Key, d.Value)
+		}
+		e.Definitions = append(e.Definitions, d)
+	}
+	e.Defined = c.Exists(key)
+
+	raw, _ := vs.get(abs, c.Profile).(string)
+	for _, p := range placeholder.FindAllString(raw, -1) {
+		x := Expansion{Placeholder: p}
+		var def string
+		x.Name, def = splitPlaceholder(p)
+		seen := map[string]bool{abs: true}
+		_, isKey := vs.get(x.Name, c.Profile).(string)
+		fn, isFunction := c.callFunction(abs, x.Name)
+		switch v := c.lookupRef(x.Name, seen); {
+		case isFunction && fn != "":
+			x.From, x.Value = "function", fn
+		case v != "" && isKey:
+			x.From, x.Value = "key", v
+		case v != "":
+			x.From, x.Value = "env", v
+		case def != "":
+			x.From, x.Value = "default", def
+		default:
+			if v := c.keyDefault(x.Name, seen); v != "" {
+				x.From, x.Value = "key default", v
+			}
+		}
+		if e.Sensitive {
+			x.Value = c.mask(key, x.Value)
+		} else {
+			x.Value = c.base().mask(x.Name, x.Value)
+		}
+		e.Expansions = append(e.Expansions, x)
+	}
+
+	expanded := c.expand(abs, raw, map[string]bool{abs: true})
+	e.Reference = referenceScheme(expanded)
+	//mirrors replaceSysVars
+	e.Value = c.resolveRef(expanded)
+	if e.Value == "" {
+		e.Value = c.keyDefault(abs, map[string]bool{abs: true})
+		e.FromDefaultKey = e.Value != ""
+	}
+	//sensitive values embedded through placeholders are scrubbed as in log lines
+	e.Value = c.redact(c.mask(key, e.Value))
+	return e
+}
+
+// String formats the explanation for humans, one step per line.
+func (e Explanation) String() string {
+	var b bytes.Buffer
+	if !e.Defined {
+		fmt.Fprintf(&b, "%s is not defined\n", e.Key)
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s = %s\n", e.Key, e.Value)
+	for i, d := range e.Definitions {
+		state := "overridden"
+		if i == 0 {
+			state = "used"
+		}
+		name := ""
+		if d.Key != e.Key {
+			name = " as " + d.Key
+		}
+		fmt.Fprintf(&b, "  %s: %s%s = %s\n", state, d.Origin, name, d.Value)
+	}
+	for _, x := range e.Expansions {
+		from := x.From
+		if from == "" {
+			from = "unresolved"
+		}
+		fmt.Fprintf(&b, "  expanded %s from %s: %s\n", x.Placeholder, from, x.Value)
+	}
+	if e.Reference != "" {
+		fmt.Fprintf(&b, "  resolved by the %s resolver\n", e.Reference)
+	}
+	if e.FromDefaultKey {
+		fmt.Fprintf(&b, "  empty, using %s%s\n", e.Key, DefaultKeySuffix)
+	}
+	return b.String()
+}
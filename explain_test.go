@@ -0,0 +1,53 @@
+package gconfig
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.host=localhost\ndb.port=5432\ndb.url=postgres://${db.host}:${db.port}/${GCTEST_DB:-app}?pass=${db.password}\ndb.password=hunter2\n")
+	writeConfig(t, dir, "application-prod.properties", "db.host=db.prod\n")
+	os.Unsetenv("GCTEST_DB")
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithoutFlags(), WithDefaults(map[string]string{"db.host": "127.0.0.1", "db.pool.default": "10"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg.Set("db.host", "db.override")
+
+	e := gcg.Explain("db.host")
+	if len(e.Definitions) != 4 || e.Definitions[0].Layer != LayerSet || e.Definitions[1].Layer != LayerProfileFile || e.Definitions[3].Value != "127.0.0.1" {
+		t.Errorf("Expected every layer defining db.host, the Set one first, but got %+v", e.Definitions)
+	}
+	if e.Definitions[2].Line != 1 || e.Value != "db.override" {
+		t.Errorf("Unexpected explanation %+v", e)
+	}
+
+	e = gcg.Explain("db.url")
+	if len(e.Expansions) != 4 {
+		t.Fatalf("Expected 4 expansions but got %+v", e.Expansions)
+	}
+	for i, from := range []string{"key", "key", "default", "key"} {
+		if e.Expansions[i].From != from {
+			t.Errorf("Expected %s to expand from %s but got %+v", e.Expansions[i].Placeholder, from, e.Expansions[i])
+		}
+	}
+	if e.Expansions[3].Value != MaskedValue || strings.Contains(e.Value, "hunter2") {
+		t.Errorf("Sensitive values should be masked but got %+v", e)
+	}
+	if !strings.HasPrefix(e.String(), "db.url = postgres://db.override:5432/app?pass="+MaskedValue+"\n") {
+		t.Errorf("Unexpected explanation:\n%s", e)
+	}
+
+	if e := gcg.Sub("db").Explain("pool"); !e.Defined || !e.FromDefaultKey || e.Value != "10" {
+		t.Errorf("Expected the value of db.pool.default but got %+v", e)
+	}
+	if e := gcg.Explain("db.missing"); e.Defined || e.String() != "db.missing is not defined\n" {
+		t.Errorf("Unexpected explanation of a missing key %+v", e)
+	}
+	if e := gcg.Explain("db.password"); e.Value != MaskedValue || e.Definitions[0].Value != MaskedValue {
+		t.Errorf("The value of a sensitive key should be masked but got %+v", e)
+	}
+}
@@ -0,0 +1,111 @@
+package gconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	s "strings"
+)
+
+// Format is a serialization format for the effective configuration.
+type Format string
+
+const (
+	// FormatProperties writes key=value lines
+	FormatProperties Format = "properties"
+	// FormatJSON writes a JSON object of key to value
+	FormatJSON Format = "json"
+	// FormatYAML writes a YAML mapping of key to value, with keys kept flat
+	FormatYAML Format = "yaml"
+)
+
+// plainYAMLKey matches the keys that can be written in YAML without quoting.
+var plainYAMLKey = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.\-]*$`)
+
+// WriteTo writes the effective configuration to w as a properties file. It implements io.WriterTo.
+func (c *GConfig) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := c.WriteFormat(cw, FormatProperties)
+	return cw.n, err
+}
+
+// WriteFormat writes the effective configuration, with profile overrides applied and
+// placeholders resolved, to w in the given format. Values of sensitive keys are masked.
+func (c *GConfig) WriteFormat(w io.Writer, format Format) error {
+	return writeFormat(w, format, c.keys(), c.AllSettings())
+}
+
+// writeFormat writes the settings, whose values are strings, in the order of keys.
+func writeFormat(w io.Writer, format Format, keys []string, settings map[string]interface{}) error {
+	bw := bufio.NewWriter(w)
+	switch format {
+	case FormatProperties:
+		for _, k := range keys {
+			writeProperty(bw, k, settings[k].(string))
+		}
+	case FormatJSON:
+		b, err := json.MarshalIndent(settings, "", "  ")
+		if err != nil {
+			return err
+		}
+		bw.Write(b)
+		bw.WriteByte('\n')
+	case FormatYAML:
+		for _, k := range keys {
+			v, _ := json.Marshal(settings[k])
+			if !plainYAMLKey.MatchString(k) {
+				qk, _ := json.Marshal(k)
+				k = string(qk)
+			}
+			fmt.Fprintf(bw, "%s: %s\n", k, v)
+		}
+	default:
+		return fmt.Errorf("Unsupported configuration format %q", format)
+	}
+	return bw.Flush()
+}
+
+// SaveAs writes the effective configuration to the file at path, choosing the format
+// from its extension: .json, .yaml or .yml, and properties for anything else.
+func (c *GConfig) SaveAs(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := c.WriteFormat(f, FormatFromExt(path)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// FormatFromExt returns the format matching the extension of a file name.
+func FormatFromExt(name string) Format {
+	switch s.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+	return FormatProperties
+}
+
+// writeProperty writes a single key=value line in properties format.
+func writeProperty(w io.Writer, key, value string) {
+	fmt.Fprintf(w, "%s=%s\n", escapeProperty(key, true), escapeProperty(value, false))
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
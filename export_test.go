@@ -0,0 +1,70 @@
+package gconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	gcg := setup("dev", t)
+
+	var buf bytes.Buffer
+	n, err := gcg.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(buf.Len()) {
+		t.Errorf("WriteTo reported %d bytes but wrote %d", n, buf.Len())
+	}
+	if !strings.Contains(buf.String(), "app.name=gconfig dev profile\n") {
+		t.Errorf("Unexpected properties output:\n%s", buf.String())
+	}
+}
+
+func TestWriteFormat(t *testing.T) {
+	gcg := setup("dev", t)
+
+	var buf bytes.Buffer
+	if err := gcg.WriteFormat(&buf, FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	var settings map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &settings); err != nil {
+		t.Fatal(err)
+	}
+	if settings["app.url"] != "https://github.com/narup/gconfig-dev" {
+		t.Errorf("Unexpected JSON output: %v", settings)
+	}
+
+	buf.Reset()
+	if err := gcg.WriteFormat(&buf, FormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "app.name: \"gconfig dev profile\"\n") {
+		t.Errorf("Unexpected YAML output:\n%s", buf.String())
+	}
+
+	if err := gcg.WriteFormat(&buf, Format("toml")); err == nil {
+		t.Errorf("Unsupported formats should return an error")
+	}
+}
+
+func TestSaveAs(t *testing.T) {
+	gcg := setup("dev", t)
+
+	path := filepath.Join(t.TempDir(), "effective.json")
+	if err := gcg.SaveAs(path); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(b), "{") {
+		t.Errorf("SaveAs should pick JSON from the extension:\n%s", b)
+	}
+}
@@ -0,0 +1,519 @@
+package gconfig
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	s "strings"
+	"unicode"
+)
+
+// The expression language is a small, side-effect free language for derived values
+// (#{...} in a property value), validation rules (Rule.Expr) and ad-hoc evaluation
+// with GConfig.Eval. It has string, number and bool values and supports:
+//
+//	literals     "text" 'text' 42 3.5 true false
+//	keys         db.port, server.max_conns  (resolved from the configuration)
+//	variables    profile, and value inside Rule.Expr
+//	operators    || && ! == != < <= > >= + - * / % and parentheses
+//	functions    len lower upper trim contains startsWith endsWith matches
+//	             int float str env key default if
+//
+// Expressions can't call into Go code, loop or touch anything beyond the configuration
+// and the environment, and their size and nesting are bounded.
+
+const (
+	maxExprLen   = 4096
+	maxExprDepth = 64
+)
+
+// exprVars resolves the identifiers of an expression. ok is false for unknown names.
+type exprVars func(name string) (v interface{}, ok bool, err error)
+
+// Eval evaluates an expression against the configuration and returns a string, float64
+// or bool. Identifiers name configuration keys; profile holds the active profile.
+func (c *GConfig) Eval(expr string) (interface{}, error) {
+	return evalExpr(expr, c.exprVars(nil, nil), c.lookupEnv)
+}
+
+// exprVars resolves identifiers to configuration values. extra holds variables that
+// take precedence over keys, such as value in validation rules, and seen the keys being
+// expanded, to report reference cycles rather than recurse forever.
+func (c *GConfig) exprVars(extra map[string]interface{}, seen map[string]bool) exprVars {
+	if seen == nil {
+		seen = make(map[string]bool)
+	}
+	return func(name string) (interface{}, bool, error) {
+		if v, ok := extra[name]; ok {
+			return v, true, nil
+		}
+		if name == "profile" {
+			return c.Profile, true, nil
+		}
+		if raw, ok := c.current().get(name, c.Profile).(string); ok {
+			if seen[name] {
+				return nil, false, &cycleError{name}
+			}
+			seen[name] = true
+			defer delete(seen, name)
+			v, err := c.expandCycle(name, raw, seen)
+			return v, err == nil, err
+		}
+		return nil, false, nil
+	}
+}
+
+func evalExpr(expr string, vars exprVars, env func(string) string) (interface{}, error) {
+	if len(expr) > maxExprLen {
+		return nil, fmt.Errorf("Expression is longer than %d characters", maxExprLen)
+	}
+	toks, err := lexExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks, vars: vars, env: env}
+	v, err := p.parse(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("Unexpected %q in expression %q", p.peek().text, expr)
+	}
+	return v, nil
+}
+
+// formatExprValue renders an expression result as a property value.
+func formatExprValue(v interface{}) string {
+	switch x := v.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(x)
+	case string:
+		return x
+	}
+	return ""
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokNum
+	tokStr
+	tokIdent
+	tokOp
+)
+
+type token struct {
+	kind tokKind
+	text string
+}
+
+var exprOps = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!", "(", ")", ","}
+
+func lexExpr(expr string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(expr); {
+		ch := rune(expr[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case ch == '"' || ch == '\'':
+			j := i + 1
+			var sb s.Builder
+			for ; j < len(expr) && rune(expr[j]) != ch; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) {
+					j++
+				}
+				sb.WriteByte(expr[j])
+			}
+			if j >= len(expr) {
+				return nil, fmt.Errorf("Unterminated string in expression %q", expr)
+			}
+			toks = append(toks, token{tokStr, sb.String()})
+			i = j + 1
+		case unicode.IsDigit(ch):
+			j := i
+			for j < len(expr) && (unicode.IsDigit(rune(expr[j])) || expr[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{tokNum, expr[i:j]})
+			i = j
+		case unicode.IsLetter(ch) || ch == '_':
+			j := i
+			for j < len(expr) && (unicode.IsLetter(rune(expr[j])) || unicode.IsDigit(rune(expr[j])) || expr[j] == '_' || expr[j] == '.') {
+				j++
+			}
+			toks = append(toks, token{tokIdent, expr[i:j]})
+			i = j
+		default:
+			op := ""
+			for _, o := range exprOps {
+				if s.HasPrefix(expr[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("Unexpected character %q in expression %q", ch, expr)
+			}
+			toks = append(toks, token{tokOp, op})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: tokEOF}), nil
+}
+
+// binding powers of the binary operators
+var exprPrec = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3,
+	"+": 4, "-": 4,
+	"*": 5, "/": 5, "%": 5,
+}
+
+type exprParser struct {
+	toks  []token
+	pos   int
+	depth int
+	vars  exprVars
+	env   func(string) string
+	// skip is set while parsing the operand a logical operator short-circuits: it is
+	// checked for syntax but not evaluated
+	skip int
+}
+
+// result returns v and err, or nothing while the operand is skipped.
+func (p *exprParser) result(v interface{}, err error) (interface{}, error) {
+	if p.skip > 0 {
+		return nil, nil
+	}
+	return v, err
+}
+
+func (p *exprParser) peek() token {
+	return p.toks[p.pos]
+}
+
+func (p *exprParser) next() token {
+	t := p.toks[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) expect(op string) error {
+	if t := p.next(); t.kind != tokOp || t.text != op {
+		return fmt.Errorf("Expected %q but found %q", op, t.text)
+	}
+	return nil
+}
+
+// parse evaluates operators binding tighter than minPrec as it parses them.
+func (p *exprParser) parse(minPrec int) (interface{}, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > maxExprDepth {
+		return nil, fmt.Errorf("Expression is nested deeper than %d levels", maxExprDepth)
+	}
+
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		prec, ok := exprPrec[t.text]
+		if t.kind != tokOp || !ok || prec <= minPrec {
+			return left, nil
+		}
+		p.next()
+
+		// short-circuit the logical operators: the right operand isn't evaluated when
+		// the left one decides, so x != "" && int(x) > 0 doesn't fail on an empty x
+		if t.text == "||" || t.text == "&&" {
+			lb, err := toBool(left)
+			if _, err := p.result(nil, err); err != nil {
+				return nil, err
+			}
+			decided := lb == (t.text == "||")
+			if decided {
+				p.skip++
+			}
+			right, err := p.parse(prec)
+			if decided {
+				p.skip--
+			}
+			if err != nil {
+				return nil, err
+			}
+			if decided {
+				left = lb
+				continue
+			}
+			rb, err := toBool(right)
+			if _, err := p.result(nil, err); err != nil {
+				return nil, err
+			}
+			left = rb
+			continue
+		}
+
+		right, err := p.parse(prec)
+		if err != nil {
+			return nil, err
+		}
+		if left, err = p.result(binaryOp(t.text, left, right)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *exprParser) unary() (interface{}, error) {
+	t := p.peek()
+	if t.kind == tokOp && (t.text == "!" || t.text == "-") {
+		p.next()
+		v, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if t.text == "!" {
+			b, err := toBool(v)
+			return p.result(!b, err)
+		}
+		n, err := toNumber(v)
+		return p.result(-n, err)
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (interface{}, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNum:
+		n, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid number %q", t.text)
+		}
+		return n, nil
+	case tokStr:
+		return t.text, nil
+	case tokIdent:
+		if p.peek().kind == tokOp && p.peek().text == "(" {
+			p.next()
+			var args []interface{}
+			for !(p.peek().kind == tokOp && p.peek().text == ")") {
+				if len(args) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				a, err := p.parse(0)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, a)
+			}
+			p.next()
+			return p.call(t.text, args)
+		}
+		switch t.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		}
+		if p.skip > 0 {
+			return nil, nil
+		}
+		v, ok, err := p.vars(t.text)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("Unknown key or variable %q", t.text)
+	case tokOp:
+		if t.text == "(" {
+			v, err := p.parse(0)
+			if err != nil {
+				return nil, err
+			}
+			return v, p.expect(")")
+		}
+	}
+	if t.kind == tokEOF {
+		return nil, fmt.Errorf("Unexpected end of expression")
+	}
+	return nil, fmt.Errorf("Unexpected %q in expression", t.text)
+}
+
+func (p *exprParser) call(name string, args []interface{}) (interface{}, error) {
+	arity := map[string]int{
+		"len": 1, "lower": 1, "upper": 1, "trim": 1, "int": 1, "float": 1, "str": 1, "env": 1, "key": 1,
+		"contains": 2, "startsWith": 2, "endsWith": 2, "matches": 2, "default": 2, "if": 3,
+	}
+	n, ok := arity[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown function %q", name)
+	}
+	if len(args) != n {
+		return nil, fmt.Errorf("Function %s expects %d arguments but got %d", name, n, len(args))
+	}
+	if p.skip > 0 {
+		return nil, nil
+	}
+	return p.apply(name, args)
+}
+
+// apply calls the function name with args of the right count.
+func (p *exprParser) apply(name string, args []interface{}) (interface{}, error) {
+
+	str := func(i int) string { return formatExprValue(args[i]) }
+	switch name {
+	case "len":
+		return float64(len(str(0))), nil
+	case "lower":
+		return s.ToLower(str(0)), nil
+	case "upper":
+		return s.ToUpper(str(0)), nil
+	case "trim":
+		return s.TrimSpace(str(0)), nil
+	case "int":
+		f, err := toNumber(args[0])
+		return math.Trunc(f), err
+	case "float":
+		return toNumber(args[0])
+	case "str":
+		return str(0), nil
+	case "env":
+		return p.env(str(0)), nil
+	case "key":
+		v, _, err := p.vars(str(0))
+		return formatExprValue(v), err
+	case "contains":
+		return s.Contains(str(0), str(1)), nil
+	case "startsWith":
+		return s.HasPrefix(str(0), str(1)), nil
+	case "endsWith":
+		return s.HasSuffix(str(0), str(1)), nil
+	case "matches":
+		re, err := regexp.Compile(str(1))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(str(0)), nil
+	case "default":
+		if str(0) == "" {
+			return args[1], nil
+		}
+		return args[0], nil
+	}
+
+	// if
+	b, err := toBool(args[0])
+	if err != nil {
+		return nil, err
+	}
+	if b {
+		return args[1], nil
+	}
+	return args[2], nil
+}
+
+func binaryOp(op string, l, r interface{}) (interface{}, error) {
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=":
+		cmp := compareValues(l, r)
+		switch op {
+		case "==":
+			return cmp == 0, nil
+		case "!=":
+			return cmp != 0, nil
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		}
+		return cmp >= 0, nil
+	case "+":
+		ln, lerr := toNumber(l)
+		rn, rerr := toNumber(r)
+		if lerr != nil || rerr != nil {
+			return formatExprValue(l) + formatExprValue(r), nil
+		}
+		return ln + rn, nil
+	}
+
+	ln, err := toNumber(l)
+	if err != nil {
+		return nil, err
+	}
+	rn, err := toNumber(r)
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "-":
+		return ln - rn, nil
+	case "*":
+		return ln * rn, nil
+	case "/":
+		if rn == 0 {
+			return nil, fmt.Errorf("Division by zero")
+		}
+		return ln / rn, nil
+	}
+	if rn == 0 {
+		return nil, fmt.Errorf("Division by zero")
+	}
+	return math.Mod(ln, rn), nil
+}
+
+// compareValues compares numerically when both sides are numbers, or numeric strings,
+// and as strings otherwise.
+func compareValues(l, r interface{}) int {
+	ln, lerr := toNumber(l)
+	rn, rerr := toNumber(r)
+	if lerr == nil && rerr == nil {
+		switch {
+		case ln < rn:
+			return -1
+		case ln > rn:
+			return 1
+		}
+		return 0
+	}
+	return s.Compare(formatExprValue(l), formatExprValue(r))
+}
+
+func toNumber(v interface{}) (float64, error) {
+	switch x := v.(type) {
+	case float64:
+		return x, nil
+	case string:
+		if f, err := strconv.ParseFloat(s.TrimSpace(x), 64); err == nil {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("%q is not a number", formatExprValue(v))
+}
+
+func toBool(v interface{}) (bool, error) {
+	switch x := v.(type) {
+	case bool:
+		return x, nil
+	case string:
+		if b, err := strconv.ParseBool(x); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("%q is not a bool", formatExprValue(v))
+}
@@ -0,0 +1,120 @@
+package gconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "cpu.count=4\napp.name=billing\n")
+	gcg, err := Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		expr string
+		want interface{}
+	}{
+		{"1 + 2 * 3", 7.0},
+		{"(1 + 2) * 3", 9.0},
+		{"10 - 4 - 3", 3.0},
+		{"7 % 4", 3.0},
+		{"-cpu.count + 1", -3.0},
+		{"cpu.count > 2 && !(app.name == 'x')", true},
+		{"false || len(app.name) == 7", true},
+		{"'a' + 1", "a1"},
+		{"contains(app.name, 'bill') && startsWith(app.name, 'b')", true},
+		{`matches(app.name, "^[a-z]+$")`, true},
+		{"default(env('GCONFIG_EXPR_UNSET'), 'fallback')", "fallback"},
+		{"key('cpu.count')", "4"},
+		{"'10' < '9'", false},
+		{"if(profile == 'prod', 'strict', 'lenient')", "strict"},
+		// the right operand isn't evaluated when the left one decides
+		{"false && nosuch.key > 1", false},
+		{"true || int('x') > 0", true},
+		{"app.name == '' && 1 / 0 > 1 || true", true},
+	}
+	for _, tt := range tests {
+		got, err := gcg.Eval(tt.expr)
+		if err != nil {
+			t.Errorf("Eval(%q) failed: %s", tt.expr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"1 +", "nosuch.key", "exec('rm')", "1 / 0", "'open", "(1", "len()", "false && (1", "true || exec('rm')"} {
+		if _, err := gcg.Eval(bad); err == nil {
+			t.Errorf("Eval(%q) should fail", bad)
+		}
+	}
+}
+
+func TestExpressionValues(t *testing.T) {
+	os.Unsetenv("GCONFIG_EXPR_UNSET")
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `cpu.count=4
+pool.size=#{int(cpu.count * 2.5)}
+app.name=billing
+banner=#{upper(app.name)} on #{profile}
+broken=#{nosuch.key + 1}
+`)
+	gcg, err := Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := gcg.GetInt("pool.size"); v != 10 {
+		t.Errorf("Derived pool.size should be 10 but was %d", v)
+	}
+	if v := gcg.GetString("banner"); v != "BILLING on prod" {
+		t.Errorf("Derived banner was %s", v)
+	}
+	if v := gcg.GetString("broken"); v != "#{nosuch.key + 1}" {
+		t.Errorf("Invalid expressions should be left as is but broken was %s", v)
+	}
+}
+
+func TestExprRule(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "pool.min=4\npool.max=3\n")
+	schema := Schema{"pool.max": {Type: TypeInt, Expr: "value >= pool.min"}}
+	gcg, err := Load(WithPath(dir), WithSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verr, ok := gcg.Validate().(*ValidationError)
+	if !ok || len(verr.Violations) != 1 || verr.Violations[0].Key != "pool.max" {
+		t.Errorf("Expression rule should reject pool.max but got %v", gcg.Validate())
+	}
+}
+
+func TestExpressionCycle(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"a": "#{b}", "b": "#{a}", "c": "#{c + 1}", "d": "#{a}"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expr := range []string{"a", "b", "c", "d"} {
+		if _, err := gcg.Eval(expr); err == nil {
+			t.Errorf("Eval(%q) should report the reference cycle", expr)
+		}
+	}
+	if v := gcg.GetString("a"); v != "#{b}" {
+		t.Errorf("a key in a cycle should keep its expression but a was %s", v)
+	}
+	warned := 0
+	for _, w := range gcg.Warnings() {
+		if w.Kind == WarningInvalidExpression {
+			warned++
+		}
+	}
+	if warned != 4 {
+		t.Errorf("Every key of a cycle should be reported, got %d warnings", warned)
+	}
+}
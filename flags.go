@@ -0,0 +1,62 @@
+package gconfig
+
+import (
+	"flag"
+	"fmt"
+)
+
+// flagBinding binds the flag name of fs to a configuration key.
+type flagBinding struct {
+	fs        *flag.FlagSet
+	name, key string
+}
+
+// RegisterFlags defines a string flag on fs for each key, named after it, eg: -db.host,
+// so WithFlags lets the command line override them. Call it before parsing fs, usually
+// with the keys of the schema: the configuration isn't loaded yet at that point.
+func RegisterFlags(fs *flag.FlagSet, keys ...string) {
+	for _, k := range keys {
+		fs.String(k, "", fmt.Sprintf("overrides the %s configuration key", k))
+	}
+}
+
+// WithFlags makes the flags of fs that are set on the command line override the key of
+// the same name, eg: -server.port=9090. Flags take precedence over the environment and
+// the files, only Set overrides them. fs must be parsed before Load; for flag.CommandLine
+// Load does it, and the path, profile and config-checksum flags are skipped as they
+// aren't keys.
+func WithFlags(fs *flag.FlagSet) Option {
+	return func(o *options) {
+		o.flagSets = append(o.flagSets, fs)
+	}
+}
+
+// BindFlag makes the flag name of fs, when set on the command line, override key. It
+// binds existing flags whose name differs from the key, eg: -port to server.port.
+func BindFlag(fs *flag.FlagSet, name, key string) Option {
+	return func(o *options) {
+		o.flagBindings = append(o.flagBindings, flagBinding{fs: fs, name: name, key: key})
+	}
+}
+
+// readFlags returns the configuration defined by the flags set on the command line,
+// keyed by configuration key.
+func readFlags(o *options) map[string]string {
+	values := make(map[string]string)
+	for _, fs := range o.flagSets {
+		fs.Visit(func(f *flag.Flag) {
+			if fs == flag.CommandLine && (f.Name == "path" || f.Name == "profile" || f.Name == "config-checksum") {
+				return
+			}
+			values[f.Name] = f.Value.String()
+		})
+	}
+	for _, b := range o.flagBindings {
+		b.fs.Visit(func(f *flag.Flag) {
+			if f.Name == b.name {
+				values[b.key] = f.Value.String()
+			}
+		})
+	}
+	return values
+}
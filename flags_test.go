@@ -0,0 +1,73 @@
+package gconfig
+
+import (
+	"flag"
+	"os"
+	"testing"
+)
+
+func TestWithFlags(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "server.port=8080\nserver.host=localhost\ndb.host=db\n")
+	os.Setenv("GCFLAGS_SERVER_PORT", "7070")
+	defer os.Unsetenv("GCFLAGS_SERVER_PORT")
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	RegisterFlags(fs, "server.port", "server.host")
+	port := fs.Int("port", 0, "database port")
+	if err := fs.Parse([]string{"-server.port=9090", "-port", "5432"}); err != nil {
+		t.Fatal(err)
+	}
+	if *port != 5432 {
+		t.Fatal("Existing flags should be parsed as usual")
+	}
+
+	gcg, err := Load(WithPath(dir), WithEnvPrefix("GCFLAGS"), WithFlags(fs), BindFlag(fs, "port", "db.port"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.GetInt("server.port"); p != 9090 {
+		t.Errorf("Flags should override the environment and the files but server.port was %d", p)
+	}
+	if h := gcg.GetString("server.host"); h != "localhost" {
+		t.Errorf("Flags not set on the command line shouldn't override the files but server.host was %q", h)
+	}
+	if p := gcg.GetInt("db.port"); p != 5432 {
+		t.Errorf("Bound flags should set their key but db.port was %d", p)
+	}
+	if o, _ := gcg.Origin("server.port"); o.Layer != LayerFlag {
+		t.Errorf("The origin of a flag should be LayerFlag but was %v", o)
+	}
+
+	gcg.Set("server.port", "1")
+	if p := gcg.GetInt("server.port"); p != 1 {
+		t.Errorf("Set should override flags but server.port was %d", p)
+	}
+}
+
+func TestDefineFlags(t *testing.T) {
+	defer func(fs *flag.FlagSet) { flag.CommandLine = fs }(flag.CommandLine)
+
+	flag.CommandLine = flag.NewFlagSet("app", flag.ContinueOnError)
+	if _, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithoutFlags()); err != nil {
+		t.Fatal(err)
+	}
+	if flag.Lookup("path") != nil || flag.Lookup("profile") != nil {
+		t.Error("Loading WithoutFlags shouldn't define flags")
+	}
+
+	// the program defines a flag of the same name first
+	flag.String("profile", "", "the profile of the application")
+	DefineFlags()
+	DefineFlags()
+	if err := flag.CommandLine.Parse([]string{"-profile=qa", "-path=/cfg", "-config-checksum=sha256:x"}); err != nil {
+		t.Fatal(err)
+	}
+	o := newOptions([]Option{WithoutEnv(), WithFlags(flag.CommandLine)})
+	if p, path := loadProfile(o), pathSetting(o); p != "qa" || path != "/cfg" {
+		t.Errorf("The flags should be read but got profile %q and path %q", p, path)
+	}
+	if v := readFlags(o); len(v) != 0 {
+		t.Errorf("The flags of Load shouldn't be keys but got %v", v)
+	}
+}
@@ -0,0 +1,26 @@
+package gconfig
+
+import "github.com/pkg/errors"
+
+// ErrFrozen is returned by Set and Reload once the configuration is frozen.
+var ErrFrozen = errors.New("gconfig: the configuration is frozen")
+
+// Freeze makes the configuration immutable from now on, for services that must keep
+// running on exactly the values they started with: Set, Persist and Reload fail with
+// ErrFrozen, Unset does nothing and the changes pushed by watched sources are dropped.
+// It applies to c, its Sub views and its snapshots, and can't be undone.
+func (c *GConfig) Freeze() {
+	c = c.base()
+	c.mu.Lock()
+	if !c.v.frozen {
+		nv := c.v.clone()
+		nv.frozen = true
+		c.v = nv.seal()
+	}
+	c.mu.Unlock()
+}
+
+// Frozen reports whether Freeze was called.
+func (c *GConfig) Frozen() bool {
+	return c.base().current().frozen
+}
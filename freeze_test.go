@@ -0,0 +1,54 @@
+package gconfig
+
+import "testing"
+
+func TestFreeze(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\napp.port=8080\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg.Set("app.port", "9090")
+	snap := gcg.Snapshot()
+	gcg.Freeze()
+	if !gcg.Frozen() || !gcg.Sub("app").Frozen() {
+		t.Error("The configuration and its views should report being frozen")
+	}
+
+	if err := gcg.Set("app.name", "changed"); err != ErrFrozen {
+		t.Errorf("Set should fail with ErrFrozen but got %v", err)
+	}
+	if err := gcg.Sub("app").Set("name", "changed"); err != ErrFrozen {
+		t.Errorf("Set on a view should fail with ErrFrozen but got %v", err)
+	}
+	if _, ok := gcg.Unset("app.port"); ok {
+		t.Error("Unset should do nothing on a frozen configuration")
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "app.name=reloaded\n")
+	if err := gcg.Reload(); err != ErrFrozen {
+		t.Errorf("Reload should fail with ErrFrozen but got %v", err)
+	}
+	if n, p := gcg.GetString("app.name"), gcg.GetInt("app.port"); n != "gconfig" || p != 9090 {
+		t.Errorf("A frozen configuration should keep its values but got %s, %d", n, p)
+	}
+	if p := snap.GetInt("app.port"); p != 9090 {
+		t.Errorf("The snapshot should keep its values but app.port was %d", p)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"app.port": "8080"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	snap := gcg.Sub("app").Snapshot()
+	gcg.Set("app.port", "9090")
+	if p := snap.GetInt("port"); p != 8080 {
+		t.Errorf("A snapshot should keep reading the values it was taken with but got %d", p)
+	}
+	if p := gcg.GetInt("app.port"); p != 9090 {
+		t.Errorf("The configuration should see the change but got %d", p)
+	}
+}
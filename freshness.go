@@ -0,0 +1,62 @@
+package gconfig
+
+import (
+	"fmt"
+	"sort"
+	s "strings"
+	"time"
+)
+
+// StaleSource describes a source whose values are older than its freshness SLO.
+type StaleSource struct {
+	Name   string
+	Age    time.Duration
+	MaxAge time.Duration
+}
+
+// StaleError is returned by Health, and by Reload in FailOnStale mode, when one or
+// more sources violate their MaxStaleness SLO. Cause holds the reload error, if any.
+type StaleError struct {
+	Sources []StaleSource
+	Cause   error
+}
+
+func (e *StaleError) Error() string {
+	names := make([]string, len(e.Sources))
+	for i, src := range e.Sources {
+		names[i] = fmt.Sprintf("%s (age %s, max %s)", src.Name, src.Age.Round(time.Second), src.MaxAge)
+	}
+	msg := fmt.Sprintf("Configuration sources are stale: %s", s.Join(names, ", "))
+	if e.Cause != nil {
+		msg += ": " + e.Cause.Error()
+	}
+	return msg
+}
+
+// Health reports whether every source was loaded recently enough to satisfy the
+// SLOs declared with MaxStaleness. It returns a *StaleError listing the violations.
+func (c *GConfig) Health() error {
+	if c.opts == nil || len(c.opts.maxStaleness) == 0 {
+		return nil
+	}
+
+	var stale []StaleSource
+	for name, loadedAt := range c.current().sources {
+		max, ok := c.opts.maxStaleness[name]
+		if !ok {
+			max, ok = c.opts.maxStaleness["*"]
+		}
+		if !ok {
+			continue
+		}
+		if age := time.Since(loadedAt); age > max {
+			stale = append(stale, StaleSource{Name: name, Age: age, MaxAge: max})
+		}
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Name < stale[j].Name })
+	return &StaleError{Sources: stale}
+}
@@ -0,0 +1,92 @@
+package gconfig
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t testing.TB, dir, name, content string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=before\n")
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "app.name=after\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "after" {
+		t.Errorf("Reload should pick up the new value but got %s", n)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	gcg, err := Load(WithPath(dir), MaxStaleness("*", 20*time.Millisecond), FailOnStale())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gcg.Health(); err != nil {
+		t.Errorf("Freshly loaded config should be healthy: %s", err)
+	}
+
+	os.Remove(filepath.Join(dir, StandardPropFileName))
+	time.Sleep(30 * time.Millisecond)
+
+	serr, ok := gcg.Reload().(*StaleError)
+	if !ok {
+		t.Fatalf("Failed reload of a stale source should return *StaleError")
+	}
+	if len(serr.Sources) != 1 || serr.Sources[0].Name != StandardPropFileName || serr.Cause == nil {
+		t.Errorf("Unexpected stale error %s", serr)
+	}
+	if gcg.GetString("app.name") != "gconfig" {
+		t.Errorf("Failed reload should keep the previous values")
+	}
+	if _, ok := gcg.Health().(*StaleError); !ok {
+		t.Errorf("Health should report the stale source")
+	}
+}
+
+func TestReloadEnv(t *testing.T) {
+	t.Setenv("GCRELOAD_APP_NAME", "before")
+	gcg, err := Load(EnvOnly("GCRELOAD"), WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=file\napp.port=80\n")
+	t.Setenv("GCRELOAD2_APP_PORT", "8080")
+	prefixed, err := Load(WithPath(dir), WithEnvPrefix("GCRELOAD2"), WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	os.Setenv("GCRELOAD_APP_NAME", "after")
+	os.Setenv("GCRELOAD2_APP_PORT", "9090")
+	for _, c := range []*GConfig{gcg, prefixed} {
+		if err := c.Reload(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := gcg.GetString("app.name"); n != "after" {
+		t.Errorf("Reload should read the environment again in EnvOnly mode but got %s", n)
+	}
+	if p := prefixed.GetString("app.port"); p != "9090" {
+		t.Errorf("Reload should read the prefixed variables again but got %s", p)
+	}
+}
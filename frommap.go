@@ -0,0 +1,13 @@
+package gconfig
+
+import "context"
+
+// NewFromMap creates a configuration holding the given values for profile, without
+// reading files, flags or the environment, nor touching the global configuration, eg: to
+// unit test a component. Values take the precedence of WithDefaults, so Set overrides
+// them; placeholders and expressions resolve against the other values. opts may add
+// modules, a schema or sources.
+func NewFromMap(values map[string]string, profile string, opts ...Option) (*GConfig, error) {
+	opts = append([]Option{WithoutFlags(), WithoutEnv(), EnvOnly(""), WithProfile(profile), WithDefaults(values)}, opts...)
+	return load(context.Background(), newOptions(opts))
+}
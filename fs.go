@@ -0,0 +1,171 @@
+package gconfig
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	s "strings"
+	"time"
+)
+
+// fileSystem is where the properties files are read from: the OS file system, using
+// native paths, or the fs.FS set with WithFS, using slash separated paths relative to
+// its root.
+type fileSystem struct {
+	fsys fs.FS
+	// latin1 decodes properties files from ISO-8859-1, see Latin1Encoding
+	latin1 bool
+}
+
+func (f fileSystem) readDir(dir string) ([]fs.DirEntry, error) {
+	if f.fsys == nil {
+		return os.ReadDir(dir)
+	}
+	return fs.ReadDir(f.fsys, dir)
+}
+
+func (f fileSystem) open(name string) (io.ReadCloser, error) {
+	if f.fsys == nil {
+		return os.Open(name)
+	}
+	return f.fsys.Open(name)
+}
+
+func (f fileSystem) stat(name string) (fs.FileInfo, error) {
+	if f.fsys == nil {
+		return os.Stat(name)
+	}
+	return fs.Stat(f.fsys, name)
+}
+
+// walkDir walks the tree rooted at root, see fs.WalkDir.
+func (f fileSystem) walkDir(root string, fn fs.WalkDirFunc) error {
+	if f.fsys == nil {
+		return filepath.WalkDir(root, fn)
+	}
+	return fs.WalkDir(f.fsys, root, fn)
+}
+
+func (f fileSystem) join(dir, name string) string {
+	if f.fsys == nil {
+		return filepath.Join(dir, name)
+	}
+	return path.Join(dir, name)
+}
+
+// resolve returns the canonical path of name relative to dir, used to detect import cycles.
+func (f fileSystem) resolve(dir, name string) (string, error) {
+	if f.fsys == nil {
+		if filepath.IsAbs(name) {
+			return name, nil
+		}
+		return filepath.Abs(filepath.Join(dir, name))
+	}
+	if s.HasPrefix(name, "/") {
+		return path.Clean(name[1:]), nil
+	}
+	return path.Join(dir, name), nil
+}
+
+func (f fileSystem) dir(name string) string {
+	if f.fsys == nil {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+// memFS is a read-only fs.FS of the in-memory files added with WithContent, or read from
+// a tar bundle, see WithBundle, keyed by slash separated path. Directories are implied by
+// the paths of their files, eg: application.properties.d/10-db.properties.
+type memFS map[string][]byte
+
+func (m memFS) Open(name string) (fs.File, error) {
+	if m.isDir(name) {
+		return &memFile{info: memInfo{name: name, dir: true}}, nil
+	}
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+	return &memFile{Reader: bytes.NewReader(data), info: memInfo{name: name, size: int64(len(data))}}, nil
+}
+
+// isDir reports whether name is the root or the directory of a file.
+func (m memFS) isDir(name string) bool {
+	if name == "." {
+		return true
+	}
+	for n := range m {
+		if s.HasPrefix(n, name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func (m memFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if !m.isDir(name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrNotExist}
+	}
+	prefix := name + "/"
+	if name == "." {
+		prefix = ""
+	}
+	seen := make(map[string]bool)
+	var entries []fs.DirEntry
+	for n, data := range m {
+		if !s.HasPrefix(n, prefix) {
+			continue
+		}
+		rel := n[len(prefix):]
+		if i := s.IndexByte(rel, '/'); i >= 0 {
+			// the file of a sub directory
+			if dir := rel[:i]; !seen[dir] {
+				seen[dir] = true
+				entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: prefix + dir, dir: true}))
+			}
+			continue
+		}
+		entries = append(entries, fs.FileInfoToDirEntry(memInfo{name: n, size: int64(len(data))}))
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+type memFile struct {
+	*bytes.Reader
+	info memInfo
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+
+func (f *memFile) Read(b []byte) (int, error) {
+	if f.info.dir {
+		return 0, &fs.PathError{Op: "read", Path: f.info.name, Err: fs.ErrInvalid}
+	}
+	return f.Reader.Read(b)
+}
+
+func (f *memFile) Close() error { return nil }
+
+type memInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i memInfo) Name() string { return path.Base(i.name) }
+func (i memInfo) Size() int64  { return i.size }
+func (i memInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+func (i memInfo) ModTime() time.Time { return time.Time{} }
+func (i memInfo) IsDir() bool        { return i.dir }
+func (i memInfo) Sys() interface{}   { return nil }
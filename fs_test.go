@@ -0,0 +1,52 @@
+package gconfig
+
+import (
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestWithFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/application.properties":      {Data: []byte("app.name=embedded\ngconfig.import=shared/common.properties\n")},
+		"conf/application-prod.properties": {Data: []byte("app.port=443\n")},
+		"conf/shared/common.properties":    {Data: []byte("app.region=eu\ngconfig.import=/conf/base.properties\n")},
+		"conf/base.properties":             {Data: []byte("app.base=true\n")},
+	}
+
+	gcg, err := Load(WithFS(fsys), WithPath("conf"), WithProfile("prod"), WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "embedded" {
+		t.Errorf("Key app.name should be read from the fs.FS but was %s", n)
+	}
+	if p := gcg.GetInt("app.port"); p != 443 {
+		t.Errorf("Profile file should be read from the fs.FS but app.port was %d", p)
+	}
+	if r := gcg.GetString("app.region"); r != "eu" {
+		t.Errorf("Relative imports should resolve within the fs.FS but app.region was %s", r)
+	}
+	if !gcg.GetBool("app.base") {
+		t.Error("Rooted imports should resolve from the root of the fs.FS")
+	}
+
+	if _, err := Load(WithFS(fstest.MapFS{}), WithoutFlags()); err == nil {
+		t.Error("An empty fs.FS has no properties files, so Load should fail")
+	}
+}
+
+func TestWithoutFlags(t *testing.T) {
+	os.Args = []string{"cmd", "-profile=dev"}
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=default\n")
+	writeConfig(t, dir, "application-dev.properties", "app.name=dev\n")
+
+	gcg, err := Load(WithPath(dir), WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.Profile == "dev" {
+		t.Error("The profile flag should be ignored with WithoutFlags")
+	}
+}
@@ -0,0 +1,140 @@
+package gconfig
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/big"
+	"os"
+	"strconv"
+	s "strings"
+)
+
+// callFunction returns the value of a placeholder naming a function rather than a key or
+// an environment variable:
+//
+//   - ${hostname}: the host name of the machine
+//   - ${pid}: the process id
+//   - ${uuid} or ${random.uuid}: a random version 4 UUID
+//   - ${random.int}, ${random.int(max)} or ${random.int(min,max)}: a random int, within
+//     [min, max) when bounded, eg: server.port=${random.int(1024,65535)}
+//   - ${random.long}, ${random.long(max)} or ${random.long(min,max)}: a random int64
+//   - ${random.value}: 32 random hex digits
+//
+// Functions are evaluated once, the first time the key holding them is resolved, and keep
+// their value for the life of the configuration, reloads included, so a random port or
+// instance id doesn't change under the application. Each key gets its own value: two keys
+// reading ${random.int(1024,65535)} get two ports, and a key derived from another, eg:
+// ${server.port}, reads the value of that key. A key of the same name, eg: hostname, takes
+// precedence over the function.
+func (c *GConfig) callFunction(key, name string) (string, bool) {
+	if !isFunction(name) {
+		return "", false
+	}
+	if _, ok := c.current().get(name, c.Profile).(string); ok {
+		return "", false
+	}
+
+	b := c.base()
+	id := key + "\x00" + name
+	if v, ok := b.generated.Load(id); ok {
+		return v.(string), true
+	}
+	if c.quiet {
+		// a value that isn't generated yet can't appear in the line being redacted
+		return "", true
+	}
+	v, err := evalFunction(name)
+	if err != nil {
+		v = ""
+	}
+	v2, loaded := b.generated.LoadOrStore(id, v)
+	if err != nil && !loaded {
+		//logged once, once stored as logging resolves the sensitive keys again, the
+		//placeholder then resolves as an empty value
+		c.logf("Placeholder ${%s} of %s: %s\n", name, key, err)
+	}
+	return v2.(string), true
+}
+
+// isFunction reports whether a placeholder name calls a function, see callFunction.
+func isFunction(name string) bool {
+	switch name {
+	case "hostname", "pid", "uuid":
+		return true
+	}
+	return s.HasPrefix(name, "random.")
+}
+
+func evalFunction(name string) (string, error) {
+	switch name {
+	case "hostname":
+		return os.Hostname()
+	case "pid":
+		return strconv.Itoa(os.Getpid()), nil
+	case "uuid", "random.uuid":
+		return randomUUID()
+	case "random.value":
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return "", err
+		}
+		return hex.EncodeToString(b), nil
+	}
+
+	fn, args := name, ""
+	if i := s.IndexByte(name, '('); i >= 0 && s.HasSuffix(name, ")") {
+		fn, args = name[:i], name[i+1:len(name)-1]
+	}
+	switch fn {
+	case "random.int":
+		return randomInt(args, math.MinInt32, math.MaxInt32)
+	case "random.long":
+		return randomInt(args, math.MinInt64, math.MaxInt64)
+	}
+	return "", fmt.Errorf("Unknown function %s", name)
+}
+
+// randomInt returns a random number within the bounds listed in args, "max" or
+// "min,max", or within [min, max] when args is empty.
+func randomInt(args string, min, max int64) (string, error) {
+	lo, hi := big.NewInt(min), new(big.Int).Add(big.NewInt(max), big.NewInt(1))
+	if args != "" {
+		bounds := s.Split(args, ",")
+		if len(bounds) > 2 {
+			return "", fmt.Errorf("Too many arguments in (%s)", args)
+		}
+		for i, b := range bounds {
+			n, err := strconv.ParseInt(s.TrimSpace(b), 10, 64)
+			if err != nil || n < min || n > max {
+				return "", fmt.Errorf("Invalid bound %q", b)
+			}
+			bounds[i] = strconv.FormatInt(n, 10)
+		}
+		lo.SetInt64(0)
+		if len(bounds) == 2 {
+			lo.SetString(bounds[0], 10)
+		}
+		hi.SetString(bounds[len(bounds)-1], 10)
+	}
+	if lo.Cmp(hi) >= 0 {
+		return "", fmt.Errorf("Empty range (%s)", args)
+	}
+
+	n, err := rand.Int(rand.Reader, new(big.Int).Sub(hi, lo))
+	if err != nil {
+		return "", err
+	}
+	return n.Add(n, lo).String(), nil
+}
+
+func randomUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
@@ -0,0 +1,94 @@
+package gconfig
+
+import (
+	"os"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestFunctions(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{
+		"app.host":      "${hostname}",
+		"app.pid":       "${pid}",
+		"app.id":        "${uuid}",
+		"http.port":     "${random.int(1024,65535)}",
+		"grpc.port":     "${random.int(1024,65535)}",
+		"http.url":      "http://localhost:${http.port}",
+		"app.dice":      "${random.int(6)}",
+		"app.token":     "${random.value}",
+		"app.bad":       "${random.int(10,1):-7}",
+		"hostname":      "configured",
+		"app.node":      "${hostname}-${pid}",
+		"app.unbounded": "${random.long}",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h, _ := os.Hostname(); gcg.GetString("app.host") != "configured" || h == "" {
+		t.Errorf("A key named hostname should win over the function but got %s", gcg.GetString("app.host"))
+	}
+	if pid := gcg.GetString("app.pid"); pid != strconv.Itoa(os.Getpid()) {
+		t.Errorf("Expected the pid %d but got %s", os.Getpid(), pid)
+	}
+	uuid := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if id := gcg.GetString("app.id"); !uuid.MatchString(id) {
+		t.Errorf("Expected a version 4 UUID but got %s", id)
+	}
+	if len(gcg.GetString("app.token")) != 32 {
+		t.Errorf("Expected 32 hex digits but got %s", gcg.GetString("app.token"))
+	}
+	if d := gcg.GetInt("app.dice"); d < 0 || d >= 6 {
+		t.Errorf("Expected a number within [0, 6) but got %d", d)
+	}
+	if _, err := strconv.ParseInt(gcg.GetString("app.unbounded"), 10, 64); err != nil {
+		t.Error(err)
+	}
+
+	port := gcg.GetInt("http.port")
+	if port < 1024 || port >= 65535 {
+		t.Errorf("Expected a port within [1024, 65535) but got %d", port)
+	}
+	for i := 0; i < 10; i++ {
+		if p := gcg.GetInt("http.port"); p != port {
+			t.Fatalf("The port should be evaluated once but changed from %d to %d", port, p)
+		}
+	}
+	if u := gcg.GetString("http.url"); u != "http://localhost:"+strconv.Itoa(port) {
+		t.Errorf("A derived key should read the port of http.port but got %s", u)
+	}
+	if id := gcg.GetString("app.id"); id != gcg.Snapshot().GetString("app.id") {
+		t.Error("Snapshots should share the generated values")
+	}
+	if v := gcg.GetString("app.bad"); v != "7" {
+		t.Errorf("A failing function should fall back to the placeholder default but got %s", v)
+	}
+
+	if e := gcg.Explain("app.id"); len(e.Expansions) != 1 || e.Expansions[0].From != "function" {
+		t.Errorf("Explain should report the function but got %+v", e.Expansions)
+	}
+	for _, w := range gcg.Warnings() {
+		if w.Key == "app.id" || w.Key == "http.port" {
+			t.Errorf("Functions should not be reported as unresolved: %s", w.Message)
+		}
+	}
+}
+
+func TestFunctionsSurviveReload(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "instance.id=${uuid}\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := gcg.GetString("instance.id")
+
+	writeConfig(t, dir, StandardPropFileName, "instance.id=${uuid}\napp.name=reloaded\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if gcg.GetString("app.name") != "reloaded" || gcg.GetString("instance.id") != id {
+		t.Errorf("The instance id should survive reloads but changed from %s to %s", id, gcg.GetString("instance.id"))
+	}
+}
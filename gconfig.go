@@ -4,20 +4,37 @@
 // 1. application.properties: this holds all the default configuration values as key/value pair.
 // 2. application-{profile}.properties. contains all the environment specific configuration values.
 //    eg: for prod environment, application-prod.properties
+//
+// Profiles are hierarchical: with the profile prod-us, application-prod.properties is read
+// before application-prod-us.properties, which overrides it, so per-region files only
+// hold what differs from prod.
+//
+// Without a path set, the configuration is read from the first of ./config, the working
+// directory, $XDG_CONFIG_HOME/{app} and /etc/{app} holding a properties file, where app is
+// the name of the program; see WithSearchPaths.
+//
+// A GConfig is safe for concurrent use: Get* calls may run on any number of goroutines
+// while Reload or Set happen on another. Loaded values are kept in an immutable snapshot
+// that Reload and Set replace as a whole, so readers never observe a half-applied update.
+//
+// The HTTP parts, the gconfig+http source and the debug, actuator and service endpoints,
+// are left out of builds tagged gconfig_tiny, which then don't depend on net/http.
 package gconfig
 
 import (
-	"bufio"
+	"context"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
+	"io/fs"
 	"os"
-	"regexp"
+	"path/filepath"
+	"sort"
 	s "strings"
+	"sync"
 
-	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -29,266 +46,888 @@ const (
 	StandardPropFileName string = "application.properties"
 )
 
-//Gcg is a global variable that represents configuration
+// Gcg is a global variable that represents configuration.
+//
+// Deprecated: Gcg is nil until Load runs, which makes it easy to dereference from init
+// functions by accident. Use Global, which fails with a clear message instead.
 var Gcg *GConfig
 
-// Command line profile and path flags that can be passed when running the application
-var cpath *string
-var profile *string
+// flagsDefined is the flag.CommandLine DefineFlags last defined the flags on.
+var (
+	flagsMu      sync.Mutex
+	flagsDefined *flag.FlagSet
+)
+
+// DefaultProfile is the profile used when neither the 'profile' flag nor GC_PROFILE is
+// set, so images built for an environment can't start with the wrong profile by accident.
+// It's meant to be compiled in, with the linker:
+//
+//	go build -ldflags "-X github.com/narup/gconfig.DefaultProfile=prod"
+//
+// or from the init function of a file behind a build tag.
+var DefaultProfile string
 
 // ErrConfigFileRequired represents file required error
-var ErrConfigFileRequired = errors.New("At least one configuration file is required")
+//
+// Deprecated: it's the same error as ErrFileNotFound, which it was renamed to.
+var ErrConfigFileRequired = ErrFileNotFound
+
+// MissingKeysError is returned when keys required through StrictMode or Require are not defined
+type MissingKeysError struct {
+	Keys []string
+}
+
+func (e *MissingKeysError) Error() string {
+	return fmt.Sprintf("Missing required configuration keys: %s", s.Join(e.Keys, ", "))
+}
 
 // configFile is a internal representation of individual configurations for default and env specific
 // configuration values.
 type configFile struct {
 	fileInfo os.FileInfo
 	configs  map[string]interface{}
+	// positions records where each key is defined, which may be an imported file
+	positions map[string]position
+	// warnings lists the malformed lines skipped while reading the file and its imports
+	warnings []*ParseError
 }
 
 func (cf configFile) Name() string {
 	return cf.fileInfo.Name()
 }
 
-func (cf *configFile) addProperty(key, value string) {
-	k := s.Trim(key, " ")
-	v := s.Trim(value, " ")
-
-	cf.configs[k] = v
-}
-
-func (cf configFile) isDefault() bool {
-	if cf.Name() == StandardPropFileName {
-		return true
+// merge returns the keys of cf overlaid with the keys of over.
+func (cf configFile) merge(over configFile) configFile {
+	if cf.fileInfo == nil {
+		return over
 	}
-	return false
+	merged := configFile{
+		fileInfo:  over.fileInfo,
+		configs:   make(map[string]interface{}),
+		positions: make(map[string]position),
+	}
+	for _, f := range []configFile{cf, over} {
+		for k, v := range f.configs {
+			merged.configs[k] = v
+			merged.positions[k] = f.positions[k]
+		}
+	}
+	return merged
 }
 
 // GConfig is the representation of all the configuration properties. It loads 2 types of data: default and environment
 // specific. One out of 2 must be present otherwise, error is returned during the Load operation
 type GConfig struct {
-	Profile                      string
+	Profile string
+	schema  Schema
+	opts    *options
+
+	mu sync.RWMutex // guards v; held for writing while a new snapshot is published
+	v  *values
+
+	subMu sync.Mutex // guards subs
+	subs  []*subscription
+
+	watchMu sync.Mutex // guards watches
+	watches []*watchState
+
+	stats *stats
+	// warned holds the deprecated keys already warned about
+	warned sync.Map
+	// generated holds the values of the functions called by placeholders, see callFunction
+	generated sync.Map
+
+	// root and prefix are set on views created by Sub, which read and write through root
+	root   *GConfig
+	prefix string
+	// pinned is set on views created by Snapshot, which read it instead of the snapshot of root
+	pinned *values
+	// quiet is set on the views redact resolves values through, which don't log, see logf
+	quiet bool
+}
+
+// values is an immutable snapshot of the loaded configuration. It's never modified once
+// published; Reload and Set build a new one and swap it in.
+type values struct {
 	profileConfig, defaultConfig configFile
+	// overrides holds the values assigned with Set, which take precedence over the files
+	overrides map[string]string
+	// flags holds the values of the flags set on the command line, see WithFlags, which
+	// take precedence over the environment
+	flags map[string]string
+	// env holds the values read from prefixed environment variables, which take
+	// precedence over the files
+	env map[string]string
+	// dotEnv holds the values read from dotenv files, keyed by configuration key,
+	// dotEnvVars the same values keyed by variable name for placeholders and dotEnvFrom
+	// the file defining each key
+	dotEnv, dotEnvVars, dotEnvFrom map[string]string
+	// sourced holds the values read from the sources added with WithSource, which take
+	// precedence over the files, and sourcedFrom the name of the source of each key
+	sourced, sourcedFrom map[string]string
+	// defaults holds the values registered in code, used when no file defines the key
+	defaults map[string]string
+	// sources records when each source was last loaded successfully
+	sources map[string]time.Time
+	// loaded is when the snapshot was last loaded or reloaded
+	loaded time.Time
+	// warnings lists the malformed lines skipped while reading the files
+	warnings []*ParseError
+	// requiredEnv holds the lists of RequiresEnvKey of the files, in the order read
+	requiredEnv []string
+	// normalize makes lookups fall back to normalized key names, see NormalizeKeys
+	normalize bool
+	// frozen rejects any further change, see Freeze
+	frozen bool
+	// index merges the layers for fast reads, see seal
+	index *lookupIndex
 }
 
-// GetString returns string value for the given key
-func (c GConfig) GetString(key string) string {
+// GetString returns string value for the given key, with ${...} placeholders resolved
+func (c *GConfig) GetString(key string) string {
 	return c.getStringValue(key)
 }
 
-// GetString returns string value for the given key
-func (c GConfig) GetStringOrDefault(key string) string {
-	return c.getStringOrDefaultValue(key)
+// GetStringOrDefault returns string value for the given key, falling back to the default
+// of a ${ENV:-default} or ${ENV|default} placeholder when the variable is not set, and
+// to the key.default entry when the value is still empty. It's the same as GetString,
+// which resolves defaults too.
+func (c *GConfig) GetStringOrDefault(key string) string {
+	return c.getStringValue(key)
 }
 
-// GetString returns string value for the given key
-func (c GConfig) GetStringOrDefaultInCommaSeparator(key string) string {
-	return c.replaceSysVars(key)
+// GetStringOrDefaultInCommaSeparator returns string value for the given key, resolving
+// every ${ENV:-default} placeholder found in a comma separated list
+func (c *GConfig) GetStringOrDefaultInCommaSeparator(key string) string {
+	return c.getStringValue(key)
 }
 
 // GetInt returns int value for the given key
-func (c GConfig) GetInt(key string) int {
+func (c *GConfig) GetInt(key string) int {
 	i, _ := strconv.Atoi(c.getStringValue(key))
 	return i
 }
 
 // GetFloat returns float value for the given key
-func (c GConfig) GetFloat(key string) float64 {
+func (c *GConfig) GetFloat(key string) float64 {
 	v, _ := strconv.ParseFloat(c.getStringValue(key), 32)
 	return v
 }
 
 // GetBool returns bool value for the given key
-func (c GConfig) GetBool(key string) bool {
+func (c *GConfig) GetBool(key string) bool {
 	b, _ := strconv.ParseBool(c.getStringValue(key))
 	return b
 }
 
-// Exists checks if key exists
-func (c GConfig) Exists(key string) bool {
+// Exists checks if key exists, or has a default entry, see DefaultKeySuffix
+func (c *GConfig) Exists(key string) bool {
 	v := c.getValue(key)
 	if v != nil {
 		return true
 	}
-	return false
+	return c.getValue(key+DefaultKeySuffix) != nil
 }
 
 // getStringValue returns a value for a given key as type interface which is converted
-// to actual return type by individual Get* functions.
-func (c GConfig) getStringValue(key string) string {
-	v := c.getValue(key)
-	strV := v.(string)
-	if s.HasPrefix(strV, "${") && s.HasSuffix(strV, "}") {
-		return os.ExpandEnv(strV)
-	}
-
-	return strV
+// to actual return type by individual Get* functions. Missing keys yield an empty string.
+func (c *GConfig) getStringValue(key string) string {
+	c.recordAccess(key)
+	c.warnDeprecatedRead(key)
+	v := c.replaceSysVars(key)
+	c.recordLookup(key, v == "" && !c.Exists(key))
+	return v
 }
 
-// getStringValue returns a value for a given key as type interface which is converted
-// to actual return type by individual Get* functions.
-func (c GConfig) getStringOrDefaultValue(key string) string {
-	v := c.getValue(key)
-	strV := v.(string)
-	return commonHelper(strV)
-
+// ParseWarnings returns the malformed lines skipped while reading the properties files,
+// see ParseStrict to fail the load on them instead.
+func (c *GConfig) ParseWarnings() []*ParseError {
+	return c.current().warnings
 }
 
-func commonHelper(strV string) string {
-	if s.HasPrefix(strV, "${") && s.HasSuffix(strV, "}") {
-		mainKey := s.TrimLeft(s.TrimRight(strV, "}"), "${")
-		keySplitter := s.Split(mainKey, "|")
-		if len(keySplitter) >= 2 {
-			configValue := os.ExpandEnv(fmt.Sprintf("${%s}", keySplitter[0]))
-			if len(configValue) == 0 {
-				return keySplitter[1]
-			}
-			return configValue
-		} else {
-			return os.ExpandEnv(strV)
+// Require checks that every given key is defined and returns a *MissingKeysError
+// listing all the keys that are absent.
+func (c *GConfig) Require(keys ...string) error {
+	var missing []string
+	for _, k := range keys {
+		if !c.Exists(k) {
+			missing = append(missing, k)
 		}
+	}
+	if len(missing) > 0 {
+		return &MissingKeysError{Keys: missing}
+	}
+	return nil
+}
+
+// Set assigns a value to key, overriding whatever the properties files define for it
+// until the next Set of the same key. Overrides survive Reload. With Persist the value
+// is written to a WritableSource instead, so the change outlives the process. It fails
+// with ErrFrozen once the configuration is frozen.
+func (c *GConfig) Set(key, value string, opts ...SetOption) error {
+	var so setOptions
+	for _, opt := range opts {
+		opt(&so)
+	}
 
+	key = c.prefix + key
+	c = c.base()
+	if so.persist {
+		return c.persist(key, value, &so)
+	}
+	c.mu.Lock()
+	old := c.v
+	if old.frozen {
+		c.mu.Unlock()
+		return ErrFrozen
 	}
+	if err := so.check(old, key, value); err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	nv := old.clone()
+	nv.overrides[key] = value
+	c.v = nv.seal()
+	c.mu.Unlock()
 
-	return strV
+	c.changed(AuditSet, old, nv)
+	return nil
 }
 
-func (c GConfig) replaceSysVars(key string) string {
-	value := c.getValue(key)
-	re := regexp.MustCompile(`\${[^}]+}`)
-	return re.ReplaceAllStringFunc(value.(string), c.replaceSysVarsHelper)
+// Unset removes the value assigned to key with Set, so the files, sources and other
+// layers define it again, and returns it, or false if the key wasn't Set or the
+// configuration is frozen.
+func (c *GConfig) Unset(key string) (string, bool) {
+	key = c.prefix + key
+	c = c.base()
+	c.mu.Lock()
+	old := c.v
+	v, ok := old.overrides[key]
+	if !ok || old.frozen {
+		c.mu.Unlock()
+		return "", false
+	}
+	nv := old.clone()
+	delete(nv.overrides, key)
+	c.v = nv.seal()
+	c.mu.Unlock()
+
+	c.changed(AuditSet, old, nv)
+	return v, true
 }
 
-func (c GConfig) replaceSysVarsHelper(value string) string {
-	value = s.Replace(value, "${", "", 1)
-	value = s.Replace(value, "}", "", 1)
-	parts := s.Split(value, "|")
-	v := ""
-	if val := commonHelper(fmt.Sprintf("${%s}", parts[0])); val != "" {
-		v = val
-	} else {
-		v = parts[1]
+// current returns the snapshot readers should use.
+func (c *GConfig) current() *values {
+	if c.pinned != nil {
+		return c.pinned
 	}
-	return v
+	c = c.base()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.v == nil {
+		return new(values)
+	}
+	return c.v
 }
 
 // getValue gets the raw value for a given key
-func (c GConfig) getValue(value string) interface{} {
-	v := c.defaultConfig.configs[value]
-	if c.profileConfig.fileInfo != nil && s.Contains(c.profileConfig.fileInfo.Name(), c.Profile) {
-		v = c.profileConfig.configs[value]
+func (c *GConfig) getValue(value string) interface{} {
+	return c.current().get(c.prefix+value, c.Profile)
+}
+
+// get returns the raw value of key, or nil when it's undefined or isn't active, see
+// ActivateAtSuffix.
+func (vs *values) get(key, profile string) interface{} {
+	if v, ok := vs.indexed(key); ok {
+		return v
 	}
-	if v == nil {
-		v = c.defaultConfig.configs[value]
+	v := vs.lookup(key)
+	if v != nil && !isScheduleKey(key) && !vs.active(key, now()) {
+		return nil
 	}
-
 	return v
 }
 
-func (c *GConfig) addConfigFile(cf configFile) {
-	if cf.isDefault() {
-		c.defaultConfig = cf
+// lookup returns the raw value of key from the layer with the highest precedence
+// defining it.
+func (vs *values) lookup(key string) interface{} {
+	names := lookupNames(key)
+	for _, m := range [...]map[string]string{vs.overrides, vs.flags, vs.env, vs.sourced, vs.dotEnv} {
+		if k, ok := findAlias(m, names, vs.normalize); ok {
+			return m[k]
+		}
+	}
+	if vs.profileConfig.fileInfo != nil {
+		if k, ok := findAlias(vs.profileConfig.configs, names, vs.normalize); ok {
+			return vs.profileConfig.configs[k]
+		}
+	}
+	if k, ok := findAlias(vs.defaultConfig.configs, names, vs.normalize); ok {
+		return vs.defaultConfig.configs[k]
+	}
+	if k, ok := findAlias(vs.defaults, names, vs.normalize); ok {
+		return vs.defaults[k]
+	}
+	return nil
+}
+
+// clone returns a copy of vs whose maps can be modified without affecting vs.
+// The config files are shared as they're never modified after loading.
+func (vs *values) clone() *values {
+	nv := *vs
+	nv.index = nil
+	nv.overrides = make(map[string]string, len(vs.overrides))
+	for k, v := range vs.overrides {
+		nv.overrides[k] = v
+	}
+	nv.sources = make(map[string]time.Time, len(vs.sources))
+	for k, t := range vs.sources {
+		nv.sources[k] = t
+	}
+	return &nv
+}
+
+// addConfigFile adds a file read by loadFiles to the default or the profile layer. Files
+// are added from the least to the most specific and merged, the later ones winning, but
+// for the lists of RequiresEnvKey that add up.
+func (vs *values) addConfigFile(cf configFile, profile bool) {
+	if l, ok := cf.configs[RequiresEnvKey].(string); ok {
+		vs.requiredEnv = append(vs.requiredEnv, l)
+	}
+	if profile {
+		vs.profileConfig = vs.profileConfig.merge(cf)
 	} else {
-		c.profileConfig = cf
+		vs.defaultConfig = vs.defaultConfig.merge(cf)
+	}
+}
+
+// profileFiles returns the files of a hierarchical profile from the least to the most
+// specific: prod-us-east reads application-prod.properties, application-prod-us.properties
+// and application-prod-us-east.properties. With several base names, see WithAppName, the
+// files of each level are listed in the order of bases.
+func profileFiles(bases []string, profile string) []string {
+	var names []string
+	for _, level := range profileLevels(profile, false) {
+		for _, base := range bases {
+			names = append(names, fmt.Sprintf("%s-%s.properties", base, level))
+		}
+	}
+	return names
+}
+
+// fragmentFiles returns the files of a profile split in fragments, see ProfileFragments:
+// application-{profile}.properties then every application-{profile}-*.properties in
+// alphabetical order, for each base name in turn.
+func fragmentFiles(bases []string, profile string, entries map[string]fs.DirEntry) []string {
+	if profile == "" {
+		return nil
+	}
+	var names []string
+	for _, base := range bases {
+		var fragments []string
+		prefix := fmt.Sprintf("%s-%s-", base, profile)
+		for name := range entries {
+			if s.HasPrefix(name, prefix) && s.HasSuffix(name, ".properties") {
+				fragments = append(fragments, name)
+			}
+		}
+		sort.Strings(fragments)
+		names = append(append(names, fmt.Sprintf("%s-%s.properties", base, profile)), fragments...)
 	}
+	return names
 }
 
-func (c GConfig) isEmpty() bool {
-	return len(c.profileConfig.configs) == 0 && len(c.defaultConfig.configs) == 0
+func (vs *values) isEmpty() bool {
+	return len(vs.profileConfig.configs) == 0 && len(vs.defaultConfig.configs) == 0 && len(vs.overrides) == 0 &&
+		len(vs.flags) == 0 && len(vs.env) == 0 && len(vs.sourced) == 0 && len(vs.dotEnv) == 0 && len(vs.defaults) == 0
 }
 
-func configError(cause error, format string, args ...interface{}) (*GConfig, error) {
-	return new(GConfig), errors.Wrap(cause, fmt.Sprintf(format, args...))
+// DefineFlags defines the path, profile and config-checksum flags read by Load on
+// flag.CommandLine, skipping the ones the program defined itself, which Load then reads.
+// Load calls it unless WithoutFlags is set, before parsing the command line; a program
+// parsing it first calls DefineFlags before, as the flags aren't defined otherwise.
+func DefineFlags() {
+	flagsMu.Lock()
+	defer flagsMu.Unlock()
+	if flagsDefined == flag.CommandLine {
+		return
+	}
+	for _, f := range []struct{ name, usage string }{
+		{"path", "-path=/Users/puran/myserver/config"},
+		{"profile", "-profile=dev"},
+		{"config-checksum", "-config-checksum=sha256:..."},
+	} {
+		if flag.Lookup(f.name) == nil {
+			flag.String(f.name, "", f.usage)
+		}
+	}
+	flagsDefined = flag.CommandLine
 }
 
-func init() {
-	cpath = flag.String("path", "", "-path=/Users/puran/myserver/config")
-	profile = flag.String("profile", "", "-profile=dev")
+// flagValue returns the value of the flag name of flag.CommandLine, if defined.
+func flagValue(name string) string {
+	if f := flag.Lookup(name); f != nil {
+		return f.Value.String()
+	}
+	return ""
 }
 
 // Load reads all the properties and creates GConfig representation. It loads
-// config data based on passed in flags or environment variables. If none is
-// defined it uses default values.
-func Load() (*GConfig, error) {
+// config data based on passed in options, flags or environment variables, in
+// that order. If none is defined it uses default values. On error it returns an empty
+// GConfig, never nil, along with the error.
+func Load(opts ...Option) (*GConfig, error) {
+	return LoadContext(context.Background(), opts...)
+}
 
-	flag.Parse()
+// LoadContext is Load bounded by ctx: when ctx is done before the configuration is read,
+// eg: a network file system or a remote source hangs, it returns an error wrapping
+// ctx.Err() right away. Sources receive ctx, see WithSourceTimeout to bound each of them.
+func LoadContext(ctx context.Context, opts ...Option) (*GConfig, error) {
+	o := newOptions(opts)
+	if !o.noFlags {
+		DefineFlags()
+		flag.Parse()
+	}
 
-	gc := new(GConfig)
-	gc.Profile = loadProfile()
+	gc, err := loadContext(ctx, o)
+	if err != nil {
+		return gc, err
+	}
+	if err := gc.verifyChecksum(o); err != nil {
+		return new(GConfig), err
+	}
+
+	SetGlobal(gc)
+	op := AuditLoad
+	if o.fallback {
+		op = AuditFallback
+	}
+	gc.changed(op, &values{}, gc.current())
+
+	//do a final check if loaded config has any values
+	if gc.current().isEmpty() {
+		gc.logf("Configuration loaded, but empty for profile: '%s'\n", gc.Profile)
+	} else {
+		gc.logf("Configuration loaded for profile %s\n", gc.Profile)
+	}
+	gc.warnDeprecated()
+
+	return gc, nil
+}
 
-	p, err := loadPath()
+// Reload re-reads the configuration files and sources using the profile and path resolved by
+// the original Load. If reading fails the previously loaded values are kept and
+// the error is returned; with FailOnStale a *StaleError is returned instead once
+// the kept values are older than their freshness SLO. A frozen configuration isn't
+// reloaded and ErrFrozen is returned.
+func (c *GConfig) Reload() error {
+	return c.ReloadContext(context.Background())
+}
+
+// ReloadContext is Reload bounded by ctx, see LoadContext.
+func (c *GConfig) ReloadContext(ctx context.Context) error {
+	c = c.base()
+	if c.current().frozen {
+		return ErrFrozen
+	}
+	nc, err := loadContext(ctx, c.opts)
 	if err != nil {
-		return configError(err, "Error reading config directory path %s", p)
+		c.stats.recordReload(err)
+		if c.opts.failOnStale {
+			if serr, ok := c.Health().(*StaleError); ok {
+				serr.Cause = err
+				return serr
+			}
+		}
+		return err
+	}
+
+	c.mu.Lock()
+	old := c.v
+	if old.frozen {
+		c.mu.Unlock()
+		return ErrFrozen
+	}
+	nv := old.clone()
+	nv.defaultConfig = nc.v.defaultConfig
+	nv.profileConfig = nc.v.profileConfig
+	nv.sourced, nv.sourcedFrom = nc.v.sourced, nc.v.sourcedFrom
+	nv.dotEnv, nv.dotEnvVars, nv.dotEnvFrom = nc.v.dotEnv, nc.v.dotEnvVars, nc.v.dotEnvFrom
+	nv.env, nv.flags, nv.defaults = nc.v.env, nc.v.flags, nc.v.defaults
+	nv.warnings = nc.v.warnings
+	nv.requiredEnv = nc.v.requiredEnv
+	nv.loaded = nc.v.loaded
+	for name, t := range nc.v.sources {
+		nv.sources[name] = t
+	}
+	c.v = nv.seal()
+	c.mu.Unlock()
+
+	c.stats.recordReload(nil)
+	c.warnDeprecated()
+	c.changed(AuditReload, old, nv)
+	c.logf("Configuration reloaded for profile %s\n", c.Profile)
+	return nil
+}
+
+// loadContext runs load, returning early if ctx is done first. The abandoned load
+// finishes in the background and its result is dropped.
+func loadContext(ctx context.Context, o *options) (*GConfig, error) {
+	if ctx.Done() == nil {
+		return load(ctx, o)
+	}
+	if err := ctx.Err(); err != nil {
+		return new(GConfig), errors.Wrap(err, "Configuration loading interrupted")
 	}
 
-	files, err := ioutil.ReadDir(p)
+	//the load may outlive the call: it gets its own options, with the profile and path
+	//flags already read, so it never touches o, read again by Reload, or the flags
+	lo := *o
+	if lo.profile == "" {
+		lo.profile = loadProfile(o)
+	}
+	if lo.path == "" && lo.fsys == nil && lo.bundle == "" {
+		lo.path = pathSetting(o)
+	}
+	o = &lo
+
+	type result struct {
+		gc  *GConfig
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		gc, err := load(ctx, o)
+		done <- result{gc, err}
+	}()
+	select {
+	case r := <-done:
+		return r.gc, r.err
+	case <-ctx.Done():
+		return new(GConfig), errors.Wrap(ctx.Err(), "Configuration loading interrupted")
+	}
+}
+
+// load reads the configuration described by o, without touching the global Gcg. On
+// error it returns an empty GConfig, never nil, as Load does.
+func load(ctx context.Context, o *options) (*GConfig, error) {
+	gc := new(GConfig)
+	gc.opts = o
+	gc.stats = new(stats)
+	gc.schema = o.schema
+	gc.v = &values{overrides: make(map[string]string), sources: make(map[string]time.Time), loaded: time.Now(), normalize: o.normalizeKeys}
+	gc.Profile = s.ToLower(o.profile)
+	if len(gc.Profile) == 0 {
+		gc.Profile = loadProfile(o)
+	}
+
+	gc.v.defaults = make(map[string]string, len(o.defaults))
+	for k, v := range o.defaults {
+		gc.v.defaults[k] = v
+	}
+
+	if err := o.checkVerifiable(); err != nil {
+		return new(GConfig), err
+	}
+	p := o.path
+	if !o.envOnly {
+		var err error
+		if p, err = gc.loadFiles(o); err != nil {
+			return new(GConfig), err
+		}
+		if err := gc.checkRequiredEnv(o); err != nil {
+			return new(GConfig), err
+		}
+	}
+
+	if o.lint != nil {
+		warnings := gc.Lint(*o.lint)
+		if len(warnings) > 0 && o.parseStrict {
+			return new(GConfig), &LintError{Warnings: warnings}
+		}
+		for _, w := range warnings {
+			gc.logf("Key name %s:%d: %s\n", w.File, w.Line, w.Message)
+		}
+	}
+
+	if err := gc.loadSources(ctx, o); err != nil {
+		return new(GConfig), err
+	}
+	if o.chaos != nil {
+		gc.perturb(o.chaos)
+	}
+
+	if len(o.flagSets) > 0 || len(o.flagBindings) > 0 {
+		gc.v.flags = readFlags(o)
+		gc.v.sources["flags"] = time.Now()
+	}
+
+	if o.envPrefix != "" && !o.noEnv {
+		gc.v.env = readEnv(o.envPrefix)
+		gc.v.sources["env"] = time.Now()
+	}
+
+	if err := gc.applyModules(); err != nil {
+		return new(GConfig), err
+	}
+
+	if o.strict {
+		if err := gc.Require(o.required...); err != nil {
+			return new(GConfig), err
+		}
+		if err := gc.Validate(); err != nil {
+			return new(GConfig), err
+		}
+	}
+
+	//pin the resolved profile and path so reloads read the same files
+	ro := *o
+	ro.profile = gc.Profile
+	ro.path = p
+	gc.opts = &ro
+
+	gc.v.seal()
+	return gc, nil
+}
+
+// loadFiles reads the default and profile properties files into c and returns the
+// directory they were read from.
+func (c *GConfig) loadFiles(o *options) (string, error) {
+	if o.bundle != "" {
+		o.logf("Loading configuration bundle %s\n", o.bundle)
+		bo, err := o.withBundle()
+		if err != nil {
+			return o.bundle, err
+		}
+		o = bo
+	}
+	fsys := fileSystem{fsys: o.fsys, latin1: o.latin1}
+	p := o.path
+	if len(p) == 0 && o.fsys != nil {
+		p = "."
+	}
+	if len(p) == 0 {
+		var err error
+		p, err = loadPath(o)
+		if err != nil {
+			return p, errors.Wrapf(err, "Error reading config directory path %s", p)
+		}
+	}
+	o.logf("Loading configuration file from path %s\n", p)
+
+	//a list of directories is merged in order, the later ones winning
+	dirs := filepath.SplitList(p)
+	if len(dirs) > 1 {
+		for _, dir := range dirs {
+			if _, err := c.loadDir(fsys, dir, o, false); err != nil {
+				return p, err
+			}
+		}
+	} else {
+		var err error
+		if p, err = c.loadDir(fsys, p, o, true); err != nil {
+			return p, err
+		}
+		dirs = []string{p}
+	}
+
+	if o.dotEnv {
+		dir := dirs[0]
+		if isGlob(dir) {
+			dir, _ = splitGlob(dir, o.fsys == nil)
+		}
+		if err := c.loadDotEnv(fsys, dir, o); err != nil {
+			return p, err
+		}
+	}
+
+	return p, nil
+}
+
+// loadDir reads the properties files of the directory, or pattern, p into c and returns
+// the directory they were read from. When p is the only directory, the working directory
+// is tried if it's missing and files are named after their base name in the sources,
+// otherwise after their path.
+func (c *GConfig) loadDir(fsys fileSystem, p string, o *options, single bool) (string, error) {
+	if isGlob(p) {
+		return p, c.loadGlob(fsys, p, o)
+	}
+
+	//ReadDir doesn't stat every entry, only the files actually read are
+	files, err := fsys.readDir(p)
+	if err != nil && (o.fsys != nil || !single) {
+		return p, errors.Wrapf(fileError(err), "Error reading config directory in path %s", p)
+	}
 	if err != nil {
-		log.Printf("Error loading config files from the path: %s. Trying from the working directory", p)
+		o.logf("Error loading config files from the path: %s. Trying from the working directory", p)
 		wd, err := os.Getwd()
 		if err != nil {
-			return nil, err
+			return p, err
 		}
-		p = fmt.Sprintf("%s/config", wd)
-		files, err = ioutil.ReadDir(p)
+		p = filepath.Join(wd, "config")
+		files, err = os.ReadDir(p)
 		if err != nil {
-			return configError(err, "Error reading config directory in path %s", p)
+			return p, errors.Wrapf(fileError(err), "Error reading config directory in path %s", p)
 		}
 	}
 
-	if len(files) == 0 {
-		return configError(ErrConfigFileRequired, "Config file not found in path %s", *cpath)
+	if len(files) == 0 && single {
+		return p, errors.Wrapf(ErrConfigFileRequired, "Config file not found in path %s", p)
+	}
+	source := func(name string) string {
+		if single {
+			return name
+		}
+		return fsys.join(p, name)
 	}
 
-	//read individual config file
+	//read the default file, then the profile files from the least to the most specific,
+	//or the profile fragments
+	entries := make(map[string]fs.DirEntry, len(files))
 	for _, f := range files {
-		cfpath := filepath.Join(p, f.Name())
-		pf := fmt.Sprintf("application-%s.properties", gc.Profile)
-		if f.Name() == StandardPropFileName || pf == f.Name() {
-			cf, err := readPropertyFile(f, cfpath)
+		entries[f.Name()] = f
+	}
+	bases := o.configBases()
+	names := profileFiles(bases, c.Profile)
+	if o.profileFragments {
+		names = fragmentFiles(bases, c.Profile, entries)
+	}
+	defaults := defaultFiles(bases)
+	for i, name := range append(defaults, names...) {
+		profile := i >= len(defaults)
+		if f, ok := entries[name]; ok {
+			fi, err := f.Info()
 			if err != nil {
-				return configError(err, "Error opening config file %s", f)
+				return p, errors.Wrapf(err, "Error opening config file %s", f.Name())
+			}
+			if err := c.addFile(fsys, o, fi, fsys.join(p, f.Name()), source(f.Name()), profile); err != nil {
+				return p, err
+			}
+		}
+		if d, ok := entries[name+DropInSuffix]; ok && d.IsDir() {
+			if err := c.addDropIns(fsys, o, p, d.Name(), profile); err != nil {
+				return p, err
 			}
-			gc.addConfigFile(cf)
 		}
 	}
+	return p, nil
+}
 
-	Gcg = gc
+// DropInSuffix names the directory of drop-in files of a properties file: the files of
+// application.properties.d are merged over application.properties in lexical order, so
+// packages and operators can add overrides without editing the shipped file. Profile files
+// have drop-ins too, eg: application-prod.properties.d.
+const DropInSuffix = ".d"
 
-	//do a final check if loaded config has any values
-	if gc.isEmpty() {
-		log.Printf("Configuration loaded, but empty for profile: '%s'\n", Gcg.Profile)
-	} else {
-		log.Printf("Configuration loaded for profile %s\n", Gcg.Profile)
+// addDropIns reads the properties files of the drop-in directory dir, in p.
+func (c *GConfig) addDropIns(fsys fileSystem, o *options, p, dir string, profile bool) error {
+	files, err := fsys.readDir(fsys.join(p, dir))
+	if err != nil {
+		return errors.Wrapf(err, "Error reading drop-in directory %s", dir)
 	}
+	//ReadDir returns the entries sorted by name
+	for _, f := range files {
+		if f.IsDir() || s.HasPrefix(f.Name(), ".") || !s.HasSuffix(f.Name(), PropertiesExtension) {
+			continue
+		}
+		fi, err := f.Info()
+		if err != nil {
+			return errors.Wrapf(err, "Error opening config file %s", f.Name())
+		}
+		name := dir + "/" + f.Name()
+		if err := c.addFile(fsys, o, fi, fsys.join(fsys.join(p, dir), f.Name()), name, profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	return gc, nil
+// addFile reads the properties file at path into the default or the profile layer,
+// recording it as a source under name.
+func (c *GConfig) addFile(fsys fileSystem, o *options, fi os.FileInfo, path, name string, profile bool) error {
+	cf, err := readPropertyFile(fsys, fi, path)
+	if perr, ok := err.(*ParseError); ok {
+		return c.redactParseError(perr)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "Error opening config file %s", name)
+	}
+	for _, w := range cf.warnings {
+		c.redactParseError(w)
+	}
+	if len(cf.warnings) > 0 && o.parseStrict {
+		return cf.warnings[0]
+	}
+	for _, w := range cf.warnings {
+		c.logf("Ignoring malformed line %s\n", w)
+	}
+	c.v.warnings = append(c.v.warnings, cf.warnings...)
+	keys := make([]string, 0, len(cf.configs))
+	for k := range cf.configs {
+		keys = append(keys, k)
+	}
+	for _, k := range ambiguousConditions(keys) {
+		base, q, _ := splitCondition(k)
+		c.logf("Key %s of %s is read as %s for the profile %s only, as no %s key is defined alongside\n", k, name, base, q, base)
+	}
+	c.v.addConfigFile(cf.applyConditions(profileLevels(c.Profile, o.profileFragments)), profile)
+	c.v.sources[name] = time.Now()
+	return nil
 }
 
 // readPropertyFile opens the configuration file and creates configuration struct with all the key/value pair info.
-// It ignores any line that begins with # and silently ignores line without correct key/value pair format.
-func readPropertyFile(fi os.FileInfo, cfpath string) (configFile, error) {
-	cf := configFile{fileInfo: fi, configs: make(map[string]interface{})}
+// It ignores any line that begins with # or ! and silently ignores line without correct key/value pair format.
+// See parseProperties for the supported syntax.
+// Files listed under ImportKey are merged in before the file's own values.
+func readPropertyFile(fsys fileSystem, fi os.FileInfo, cfpath string) (configFile, error) {
+	return readImporting(fsys, fi, cfpath, map[string]bool{})
+}
 
-	f, err := os.Open(cfpath)
+// readImporting reads a properties file and its imports. seen holds the files being
+// read, to detect import cycles.
+func readImporting(fsys fileSystem, fi os.FileInfo, cfpath string, seen map[string]bool) (configFile, error) {
+	cf := configFile{fileInfo: fi, configs: make(map[string]interface{}), positions: make(map[string]position)}
+
+	f, err := fsys.open(cfpath)
 	if err != nil {
 		return configFile{}, err
 	}
 	defer f.Close()
 
-	sc := bufio.NewScanner(f)
-	sc.Split(bufio.ScanLines)
-	for sc.Scan() {
-		l := sc.Text()
-		kv := s.Split(l, "=")
-		if len(kv) < 2 {
-			continue
-		} else if len(kv) == 2 {
-			cf.addProperty(kv[0], kv[1])
-		} else {
-			//= after first is all part of the value
-			pv := s.Replace(l, fmt.Sprintf("%s=", kv[0]), "", -1)
-			cf.addProperty(kv[0], pv)
+	var r io.Reader = f
+	if fsys.latin1 {
+		b, err := io.ReadAll(f)
+		if err != nil {
+			return configFile{}, errors.Wrapf(err, "Error reading %s", fi.Name())
 		}
+		r = s.NewReader(decodeLatin1(b))
+	}
+	props, warnings, err := parseProperties(r)
+	if perr, ok := err.(*ParseError); ok {
+		perr.File = fi.Name()
+		return configFile{}, perr
+	}
+	if err != nil {
+		return configFile{}, errors.Wrapf(err, "Error parsing %s", fi.Name())
+	}
+	for _, p := range props {
+		cf.configs[p.key] = p.value
+		cf.positions[p.key] = position{file: fi.Name(), line: p.line}
+	}
+	for _, w := range warnings {
+		w.File = fi.Name()
+	}
+	cf.warnings = warnings
+
+	abs, err := fsys.resolve("", cfpath)
+	if err != nil {
+		return configFile{}, err
+	}
+	seen[abs] = true
+	defer delete(seen, abs)
+	if err := cf.resolveImports(fsys, fsys.dir(abs), seen); err != nil {
+		return configFile{}, err
 	}
 
 	return cf, nil
@@ -297,63 +936,92 @@ func readPropertyFile(fi os.FileInfo, cfpath string) (configFile, error) {
 // If no profile is specified then it uses the default profile and load the config
 // data that's marked as default.
 // Profile can be set using 2 ways:
-// 1. Environment variable 'GC_PROFILE' eg: export GC_PROFILE='dev'
-// 2. Command line argument 'profile' eg: go run myserver.go -profile=dev
-func loadProfile() string {
+// 1. Environment variable 'GC_PROFILE' eg: export GC_PROFILE='dev', unless WithoutEnv is set
+// 2. Command line argument 'profile' eg: go run myserver.go -profile=dev, unless WithoutFlags is set
+// and falls back to DefaultProfile.
+func loadProfile(o *options) string {
 	p := ""
-	if len(*profile) == 0 {
+	if !o.noFlags {
+		p = flagValue("profile")
+	}
+	if len(p) == 0 && !o.noEnv {
 		//Load application profile from environment variable
 		p = os.Getenv("GC_PROFILE")
-	} else {
-		p = *profile
+	}
+	if len(p) == 0 {
+		p = DefaultProfile
 	}
 	return s.ToLower(p)
 }
 
 //Check if location of config or properties file is set in the env variable
-//if no path is specified it will use the current directory
-func loadPath() (string, error) {
-	path := ""
-	if len(*cpath) == 0 {
-		path = os.Getenv("GC_PATH")
-	} else {
-		path = *cpath
+//if no path is specified it searches the directories of WithSearchPaths, or the default ones
+func loadPath(o *options) (string, error) {
+	if path := pathSetting(o); len(path) > 0 {
+		return path, nil
 	}
 
-	//if empty, load default config path
-	if len(path) == 0 {
-		gp, err := getGoPath()
-		if err != nil {
-			return "", err
+	dirs := o.searchPaths
+	if dirs == nil {
+		app := o.appName
+		if app == "" {
+			app = appName()
 		}
-		path = gp + "/config"
+		dirs = defaultSearchPaths(app)
+	}
+	for _, dir := range dirs {
+		if hasPropertiesFile(dir) {
+			return dir, nil
+		}
+	}
+	return "", errors.Wrapf(ErrConfigFileRequired, "No configuration directory found, searched %s", s.Join(dirs, ", "))
+}
+
+// pathSetting returns the path set with the 'path' flag or GC_PATH, if any.
+func pathSetting(o *options) string {
+	path := ""
+	if !o.noFlags {
+		path = flagValue("path")
+	}
+	if len(path) == 0 && !o.noEnv {
+		path = os.Getenv("GC_PATH")
 	}
+	return path
+}
 
-	log.Printf("Loading configuration file from path %s\n", path)
+// defaultSearchPaths returns the directories searched for the configuration when no path
+// is set: ./config, the working directory, the user configuration directory of the
+// application, eg: $XDG_CONFIG_HOME/app, then /etc/app.
+func defaultSearchPaths(app string) []string {
+	dirs := []string{"config", "."}
+	if app == "" {
+		return dirs
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, app))
+	}
+	return append(dirs, filepath.Join(string(filepath.Separator)+"etc", app))
+}
 
-	return path, nil
+// appName returns the name of the running program, without extension.
+func appName() string {
+	if len(os.Args) == 0 {
+		return ""
+	}
+	name := filepath.Base(os.Args[0])
+	return s.TrimSuffix(name, filepath.Ext(name))
 }
 
-// getGoPath returns a single GOPATH. If there are multiple defined in the users
-// $GOPATH env variable, then getGoPath validates that the working directory is
-// part of one of the GOPATHs, and uses the first one it finds that does.
-func getGoPath() (string, error) {
-	wd, err := os.Getwd()
+// hasPropertiesFile reports whether dir holds a properties file.
+func hasPropertiesFile(dir string) bool {
+	files, err := os.ReadDir(dir)
 	if err != nil {
-		return "", err
+		return false
 	}
-
-	gopath := os.Getenv("GOPATH")
-	// Split out multiple GOPATHs if necessary
-	if s.Contains(gopath, ":") {
-		paths := s.Split(gopath, ":")
-		for _, path := range paths {
-			if s.Contains(wd, path) {
-				gopath = path
-				break
-			}
+	for _, f := range files {
+		if !f.IsDir() && s.HasSuffix(f.Name(), PropertiesExtension) {
+			return true
 		}
 	}
-
-	return gopath, nil
+	return false
 }
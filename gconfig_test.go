@@ -1,9 +1,13 @@
 package gconfig
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -13,11 +17,11 @@ func setup(profile string, t *testing.T) *GConfig {
 		t.Errorf("Test failed:%s", err)
 	}
 
-	p := wd + "/config"
+	p := filepath.Join(wd, "config")
 	if profile != "" {
 		os.Args = []string{"cmd", "-path=" + p, "-profile=dev"}
 	} else {
-		os.Args = []string{"cmd", "-path=" + p}
+		os.Args = []string{"cmd", "-path=" + p, "-profile="}
 	}
 	gcg, loadErr := Load()
 	if loadErr != nil {
@@ -124,3 +128,289 @@ func TestGetStringOrDefaultInCommaSeparatorWithEnvValueForAllTypes(t *testing.T)
 		t.Errorf("Key app.name didn't match expected value %s\n", expectedString)
 	}
 }
+
+func TestMissingKey(t *testing.T) {
+	gcg := setup("dev", t)
+
+	if v := gcg.GetString("does.not.exist"); v != "" {
+		t.Errorf("Missing key should return empty string but returned %s", v)
+	}
+	if v := gcg.GetInt("does.not.exist"); v != 0 {
+		t.Errorf("Missing key should return 0 but returned %d", v)
+	}
+	if v := gcg.GetStringOrDefault("does.not.exist"); v != "" {
+		t.Errorf("Missing key should return empty string but returned %s", v)
+	}
+	if v := gcg.GetStringOrDefaultInCommaSeparator("does.not.exist"); v != "" {
+		t.Errorf("Missing key should return empty string but returned %s", v)
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcg, err := Load(WithPath(wd+"/config"), WithProfile("dev"), StrictMode("app.name", "db.url", "db.user"))
+	if gcg == nil {
+		t.Error("A failed load should return an empty configuration, not nil")
+	}
+	mkErr, ok := err.(*MissingKeysError)
+	if !ok {
+		t.Fatalf("Expected *MissingKeysError but got %v", err)
+	}
+	if strings.Join(mkErr.Keys, ",") != "db.url,db.user" {
+		t.Errorf("Unexpected missing keys %v", mkErr.Keys)
+	}
+
+	if _, err := Load(WithPath(wd+"/config"), WithProfile("dev"), StrictMode("app.name")); err != nil {
+		t.Errorf("Strict load should succeed when all keys are present: %s", err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	gcg := setup("dev", t)
+
+	if err := gcg.Set("app.name", "overridden"); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "overridden" {
+		t.Errorf("Key app.name didn't match expected value overridden but was %s", n)
+	}
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "overridden" {
+		t.Errorf("Set value should survive reload but was %s", n)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	gcg := setup("dev", t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				gcg.GetString("app.name")
+				gcg.GetInt("connection_pool_count")
+			}
+		}()
+	}
+	for j := 0; j < 20; j++ {
+		gcg.Set("connection_pool_count", strconv.Itoa(j))
+		if err := gcg.Reload(); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Wait()
+}
+
+func TestProfileChain(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\napp.region=none\napp.port=80\n")
+	writeConfig(t, dir, "application-prod.properties", "app.region=global\napp.port=443\n")
+	writeConfig(t, dir, "application-prod-us.properties", "app.region=us\n")
+	writeConfig(t, dir, "application-prod-eu.properties", "app.region=eu\n")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod-us-east"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := gcg.GetString("app.region"); r != "us" {
+		t.Errorf("The most specific existing profile file should win but app.region was %s", r)
+	}
+	if p := gcg.GetInt("app.port"); p != 443 {
+		t.Errorf("Keys missing from prod-us should come from prod but app.port was %d", p)
+	}
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("Keys missing from the profiles should come from the defaults but app.name was %s", n)
+	}
+	if o, _ := gcg.Origin("app.port"); o.Source != "application-prod.properties" {
+		t.Errorf("The origin should name the parent profile file but was %v", o)
+	}
+
+	gcg, err = Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := gcg.GetString("app.region"); r != "global" {
+		t.Errorf("Child profiles shouldn't apply to their parent but app.region was %s", r)
+	}
+}
+
+func TestProfileFragments(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.host=localhost\n")
+	writeConfig(t, dir, "application-prod.properties", "app.port=443\ndb.host=main\nkafka.brokers=none\n")
+	writeConfig(t, dir, "application-prod-kafka.properties", "kafka.brokers=k1,k2\ndb.pool=5\n")
+	writeConfig(t, dir, "application-prod-db.properties", "db.host=db.prod\ndb.pool=10\n")
+	writeConfig(t, dir, "application-production.properties", "app.port=80\n")
+	writeConfig(t, dir, "application-prod-db.properties.bak", "db.host=backup\n")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), ProfileFragments())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"app.name": "gconfig", "app.port": "443", "db.host": "db.prod", "db.pool": "5", "kafka.brokers": "k1,k2"}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Key %s should be %s but was %s", k, v, got)
+		}
+	}
+	if o, _ := gcg.Origin("db.host"); o.Source != "application-prod-db.properties" {
+		t.Errorf("The origin should name the fragment but was %v", o)
+	}
+
+	gcg, err = Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := gcg.GetString("db.host"); h != "main" {
+		t.Errorf("Fragments should only be read with ProfileFragments but db.host was %s", h)
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+	writeConfig(t, dir, "application-prod.properties", "app.name=prod\n")
+	writeConfig(t, dir, "application-dev.properties", "app.name=dev\n")
+
+	defer func(p string) { DefaultProfile = p }(DefaultProfile)
+	DefaultProfile = "Prod"
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.Profile != "prod" || gcg.GetString("app.name") != "prod" {
+		t.Errorf("The default profile should be used but got %s", gcg.Profile)
+	}
+
+	t.Setenv("GC_PROFILE", "dev")
+	gcg, err = Load(WithPath(dir), WithoutFlags())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.Profile != "dev" {
+		t.Errorf("GC_PROFILE should win over the default profile but got %s", gcg.Profile)
+	}
+}
+
+func TestSearchPaths(t *testing.T) {
+	empty, first, second := t.TempDir(), t.TempDir(), t.TempDir()
+	writeConfig(t, empty, "notes.txt", "app.name=notes\n")
+	writeConfig(t, first, StandardPropFileName, "app.name=first\n")
+	writeConfig(t, second, StandardPropFileName, "app.name=second\n")
+
+	missing := filepath.Join(empty, "missing")
+	gcg, err := Load(WithoutFlags(), WithoutEnv(), WithSearchPaths(missing, empty, first, second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "first" {
+		t.Errorf("The first directory holding a properties file should be used but app.name was %s", n)
+	}
+
+	_, err = Load(WithoutFlags(), WithoutEnv(), WithSearchPaths(missing, empty))
+	if !errors.Is(err, ErrConfigFileRequired) || !strings.Contains(err.Error(), missing) {
+		t.Errorf("Load should fail listing the directories searched but got %v", err)
+	}
+
+	dirs := defaultSearchPaths("myserver")
+	if len(dirs) != 4 || dirs[0] != "config" || dirs[1] != "." || filepath.Base(dirs[2]) != "myserver" || dirs[3] != filepath.FromSlash("/etc/myserver") {
+		t.Errorf("Unexpected default search paths %v", dirs)
+	}
+}
+
+func TestDropIns(t *testing.T) {
+	dir := t.TempDir()
+	for _, d := range []string{"application.properties.d", "application-prod.properties.d"} {
+		if err := os.Mkdir(filepath.Join(dir, d), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\napp.port=80\ndb.host=localhost\n")
+	writeConfig(t, dir, "application.properties.d/20-port.properties", "app.port=8080\n")
+	writeConfig(t, dir, "application.properties.d/10-port.properties", "app.port=8000\ndb.pool=5\n")
+	writeConfig(t, dir, "application.properties.d/30-port.properties.disabled", "app.port=1\n")
+	writeConfig(t, dir, "application-prod.properties", "db.host=prod\n")
+	writeConfig(t, dir, "application-prod.properties.d/override.properties", "db.host=replica\n")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"app.name": "gconfig", "app.port": "8080", "db.pool": "5", "db.host": "replica"}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Key %s should be %s but was %s", k, v, got)
+		}
+	}
+	if _, ok := gcg.current().sources["application.properties.d/20-port.properties"]; !ok {
+		t.Errorf("Drop-in files should be tracked as sources: %v", gcg.current().sources)
+	}
+
+	gcg, err = Load(WithPath(dir), WithProfile("dev"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := gcg.GetString("db.host"); h != "localhost" {
+		t.Errorf("Drop-ins of other profiles shouldn't apply but db.host was %s", h)
+	}
+}
+
+func TestPathList(t *testing.T) {
+	base, overrides := t.TempDir(), t.TempDir()
+	writeConfig(t, base, StandardPropFileName, "app.name=gconfig\napp.port=80\ndb.host=localhost\n")
+	writeConfig(t, base, "application-prod.properties", "db.host=prod\ndb.pool=5\n")
+	writeConfig(t, overrides, StandardPropFileName, "app.port=8080\ndb.host=override\n")
+	writeConfig(t, overrides, "application-prod.properties", "db.pool=10\n")
+
+	gcg, err := Load(WithPath(base+string(filepath.ListSeparator)+overrides), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"app.name": "gconfig", "app.port": "8080", "db.host": "prod", "db.pool": "10"}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Key %s should be %s but was %s", k, v, got)
+		}
+	}
+	if _, ok := gcg.current().sources[filepath.Join(overrides, StandardPropFileName)]; !ok {
+		t.Errorf("Files should be tracked by path: %v", gcg.current().sources)
+	}
+
+	missing := filepath.Join(base, "missing")
+	if _, err := Load(WithPath(base + string(filepath.ListSeparator) + missing)); err == nil || !strings.Contains(err.Error(), missing) {
+		t.Errorf("A missing directory should fail the load but got %v", err)
+	}
+}
+
+func TestNewFromMap(t *testing.T) {
+	os.Args = []string{"cmd", "-profile=dev"}
+	gcg, err := NewFromMap(map[string]string{"app.name": "map", "app.greeting": "hello ${app.name}"}, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.Profile != "prod" || gcg.GetString("app.greeting") != "hello map" {
+		t.Errorf("Unexpected profile %s or greeting %s", gcg.Profile, gcg.GetString("app.greeting"))
+	}
+	if w := gcg.Warnings(); len(w) != 0 {
+		t.Errorf("A configuration from a map shouldn't warn about missing files: %v", w)
+	}
+
+	gcg.Set("app.name", "set")
+	if v, ok := gcg.Unset("app.name"); !ok || v != "set" {
+		t.Errorf("Unset should return the value Set but got %s, %v", v, ok)
+	}
+	if n := gcg.GetString("app.name"); n != "map" {
+		t.Errorf("Unset should restore the value from the map but app.name was %s", n)
+	}
+	if _, ok := gcg.Unset("app.name"); ok {
+		t.Error("Unset should report keys that weren't Set")
+	}
+}
@@ -0,0 +1,99 @@
+// Package gconfigtest helps testing code reading a gconfig configuration, without
+// properties files, command line flags or environment variables:
+//
+//	func TestClient(t *testing.T) {
+//		cfg := gconfigtest.New(t, map[string]string{"api.url": "http://localhost", "api.retries": "3"})
+//		gconfigtest.Override(t, cfg, "api.retries", "0")
+//		...
+//	}
+package gconfigtest
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/narup/gconfig"
+)
+
+// New returns a configuration holding values for the default profile, see
+// gconfig.NewFromMap, failing the test if it can't be created.
+func New(t testing.TB, values map[string]string, opts ...gconfig.Option) *gconfig.GConfig {
+	t.Helper()
+	return NewProfile(t, values, "", opts...)
+}
+
+// NewProfile is New for the given profile.
+func NewProfile(t testing.TB, values map[string]string, profile string, opts ...gconfig.Option) *gconfig.GConfig {
+	t.Helper()
+	c, err := gconfig.NewFromMap(values, profile, opts...)
+	if err != nil {
+		t.Fatalf("gconfigtest: %s", err)
+	}
+	return c
+}
+
+// Override sets key to value in c until the end of the test, when the previous value,
+// whatever layer it came from, is restored. It works with any configuration, eg: the one
+// returned by gconfig.Global.
+func Override(t testing.TB, c *gconfig.GConfig, key, value string) {
+	t.Helper()
+	prev, set := c.Unset(key)
+	if err := c.Set(key, value); err != nil {
+		t.Fatalf("gconfigtest: %s", err)
+	}
+	t.Cleanup(func() {
+		c.Unset(key)
+		if set {
+			c.Set(key, prev)
+		}
+	})
+}
+
+// SetGlobal installs c as the global configuration until the end of the test, when the
+// previous one is restored.
+func SetGlobal(t testing.TB, c *gconfig.GConfig) {
+	t.Helper()
+	prev, _ := gconfig.TryGlobal()
+	gconfig.SetGlobal(c)
+	t.Cleanup(func() { gconfig.SetGlobal(prev) })
+}
+
+// SeedEnv names the environment variable holding the first seed of ForEachRandom, 1 by
+// default, eg: GCONFIGTEST_SEED=$RANDOM in CI explores new configurations on every run.
+const SeedEnv = "GCONFIGTEST_SEED"
+
+// ForEachRandom runs fn as n subtests, each with a random configuration valid for schema,
+// see gconfig.Schema.Generate, to check startup code against unusual but valid
+// combinations of values. Subtests are named after their seed and log the generated
+// values, so a failure is reproduced with -run 'TestName/seed=N' and the same SeedEnv.
+//
+//	gconfigtest.ForEachRandom(t, schema, 100, func(t *testing.T, cfg *gconfig.GConfig) {
+//		srv, err := server.New(cfg)
+//		...
+//	})
+func ForEachRandom(t *testing.T, schema gconfig.Schema, n int, fn func(t *testing.T, cfg *gconfig.GConfig), opts ...gconfig.Option) {
+	t.Helper()
+	first := int64(1)
+	if v := os.Getenv(SeedEnv); v != "" {
+		var err error
+		if first, err = strconv.ParseInt(v, 10, 64); err != nil {
+			t.Fatalf("gconfigtest: invalid %s %q", SeedEnv, v)
+		}
+	}
+
+	opts = append(opts, gconfig.WithSchema(schema))
+	for seed := first; seed < first+int64(n); seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed=%d", seed), func(t *testing.T) {
+			values, err := schema.Generate(rand.New(rand.NewSource(seed)))
+			if err != nil {
+				t.Fatalf("gconfigtest: %s", err)
+			}
+			t.Logf("configuration: %v", values)
+			fn(t, New(t, values, opts...))
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package gconfigtest
+
+import (
+	"testing"
+
+	"github.com/narup/gconfig"
+)
+
+func TestNew(t *testing.T) {
+	cfg := New(t, map[string]string{"api.url": "http://${api.host}:8080", "api.host": "localhost", "api.retries": "3"})
+	if u := cfg.GetString("api.url"); u != "http://localhost:8080" {
+		t.Errorf("Placeholders should resolve against the values but api.url was %s", u)
+	}
+	if cfg.Profile != "" {
+		t.Errorf("The default profile should be used but got %s", cfg.Profile)
+	}
+	if p := NewProfile(t, nil, "prod").Profile; p != "prod" {
+		t.Errorf("Expected the prod profile but got %s", p)
+	}
+}
+
+func TestOverride(t *testing.T) {
+	cfg := New(t, map[string]string{"api.retries": "3", "api.timeout": "1s"})
+	cfg.Set("api.timeout", "5s")
+
+	t.Run("override", func(t *testing.T) {
+		Override(t, cfg, "api.retries", "0")
+		Override(t, cfg, "api.timeout", "10s")
+		if r, d := cfg.GetInt("api.retries"), cfg.GetString("api.timeout"); r != 0 || d != "10s" {
+			t.Errorf("The overrides should apply within the test but got %d, %s", r, d)
+		}
+	})
+
+	if r := cfg.GetInt("api.retries"); r != 3 {
+		t.Errorf("The value should be restored after the test but api.retries was %d", r)
+	}
+	if d := cfg.GetString("api.timeout"); d != "5s" {
+		t.Errorf("The previous Set should be restored after the test but api.timeout was %s", d)
+	}
+}
+
+func TestSetGlobal(t *testing.T) {
+	prev, _ := gconfig.TryGlobal()
+	cfg := New(t, map[string]string{"app.name": "test"})
+	t.Run("global", func(t *testing.T) {
+		SetGlobal(t, cfg)
+		if gconfig.Global() != cfg {
+			t.Error("The configuration should be installed as the global one")
+		}
+	})
+	if c, _ := gconfig.TryGlobal(); c != prev {
+		t.Error("The previous global configuration should be restored")
+	}
+}
+
+func TestForEachRandom(t *testing.T) {
+	schema := gconfig.Schema{
+		"server.port": {Type: gconfig.TypeInt, Required: true, Min: gconfig.Bound(1), Max: gconfig.Bound(65535)},
+		"server.tls":  {Type: gconfig.TypeBool},
+	}
+	runs := 0
+	ForEachRandom(t, schema, 10, func(t *testing.T, cfg *gconfig.GConfig) {
+		runs++
+		if err := cfg.Validate(); err != nil {
+			t.Fatal(err)
+		}
+		if port := cfg.GetInt("server.port"); port < 1 || port > 65535 {
+			t.Fatalf("Expected a port within bounds, got %d", port)
+		}
+	})
+	if runs != 10 {
+		t.Fatalf("Expected 10 runs, got %d", runs)
+	}
+}
@@ -0,0 +1,241 @@
+package gconfig
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	s "strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxGenerateAttempts bounds the configurations Generate draws before giving up on a
+// schema whose rules, or expressions, are too hard to satisfy by chance.
+const maxGenerateAttempts = 100
+
+// Generate returns a random configuration satisfying the schema, to test that an
+// application starts with any valid configuration, property-based testing style, see
+// gconfigtest.ForEachRandom. Required keys are always defined and the others half of the
+// time. Values favour the unusual but valid: bounds, zero, every spelling ParseBool
+// accepts, durations mixing units, strings of unicode letters or matching the rule's
+// Pattern. Rules with an Expr are checked against the whole configuration, and values
+// are drawn again until it's valid; Generate returns the *ValidationError of the last
+// attempt if none was. The same seed of rnd generates the same configuration.
+func (schema Schema) Generate(rnd *rand.Rand) (map[string]string, error) {
+	// draw keys in order, so a seed always generates the same configuration
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var err error
+	for i := 0; i < maxGenerateAttempts; i++ {
+		values := make(map[string]string, len(schema))
+		for _, k := range keys {
+			rule := schema[k]
+			if !rule.Required && rnd.Intn(2) == 0 {
+				continue
+			}
+			values[k] = generateValue(rnd, rule)
+		}
+
+		var c *GConfig
+		if c, err = NewFromMap(values, ""); err != nil {
+			return nil, err
+		}
+		if err = c.validateSchema(schema); err == nil {
+			return values, nil
+		}
+	}
+	return nil, err
+}
+
+// generateValue returns a random value likely to satisfy r.
+func generateValue(rnd *rand.Rand, r Rule) string {
+	if len(r.Enum) > 0 {
+		return r.Enum[rnd.Intn(len(r.Enum))]
+	}
+	switch r.Type {
+	case TypeInt:
+		min, max := bounds(r, math.MinInt32, math.MaxInt32)
+		lo, hi := int64(math.Ceil(min)), int64(math.Floor(max))
+		if lo > hi {
+			return strconv.FormatInt(lo, 10)
+		}
+		return strconv.FormatInt(pick(rnd, lo, hi, lo+rnd.Int63n(hi-lo+1)), 10)
+	case TypeFloat:
+		min, max := bounds(r, -1e9, 1e9)
+		return strconv.FormatFloat(pick(rnd, min, max, min+rnd.Float64()*(max-min)), 'g', -1, 64)
+	case TypeBool:
+		spellings := []string{"true", "false", "1", "0", "t", "f", "T", "F", "TRUE", "FALSE", "True", "False"}
+		return spellings[rnd.Intn(len(spellings))]
+	case TypeDuration:
+		return generateDuration(rnd)
+	case TypeURL:
+		return generateURL(rnd)
+	}
+	if r.Pattern != "" {
+		if v, err := generateMatch(rnd, r.Pattern); err == nil {
+			return v
+		}
+	}
+	min, max := bounds(r, 0, 32)
+	return generateString(rnd, int(math.Max(0, math.Ceil(min))), int(math.Floor(max)))
+}
+
+// bounds returns the Min and Max of r, or the given defaults.
+func bounds(r Rule, min, max float64) (float64, float64) {
+	if r.Min != nil {
+		min = *r.Min
+	}
+	if r.Max != nil {
+		max = *r.Max
+	}
+	return min, max
+}
+
+// pick returns one of the bounds, zero when it's within them, or v.
+func pick[N int64 | float64](rnd *rand.Rand, min, max, v N) N {
+	switch rnd.Intn(6) {
+	case 0:
+		return min
+	case 1:
+		return max
+	case 2:
+		if min <= 0 && max >= 0 {
+			return 0
+		}
+	}
+	return v
+}
+
+func generateDuration(rnd *rand.Rand) string {
+	units := []string{"ns", "us", "µs", "ms", "s", "m", "h"}
+	switch rnd.Intn(4) {
+	case 0:
+		return "0"
+	case 1:
+		return time.Duration(rnd.Int63n(int64(48 * time.Hour))).String()
+	case 2:
+		return fmt.Sprintf("%d.%d%s", rnd.Intn(100), rnd.Intn(1000), units[rnd.Intn(len(units))])
+	}
+	var sb s.Builder
+	for n := 1 + rnd.Intn(3); n > 0; n-- {
+		fmt.Fprintf(&sb, "%d%s", rnd.Intn(1000), units[rnd.Intn(len(units))])
+	}
+	return sb.String()
+}
+
+func generateURL(rnd *rand.Rand) string {
+	schemes := []string{"http", "https", "postgres", "redis", "amqp"}
+	hosts := []string{"localhost", "127.0.0.1", "[::1]", "db.internal", "xn--bcher-kva.example"}
+	u := schemes[rnd.Intn(len(schemes))] + "://"
+	if rnd.Intn(3) == 0 {
+		u += "user:p%40ss@"
+	}
+	u += hosts[rnd.Intn(len(hosts))]
+	if rnd.Intn(2) == 0 {
+		u += ":" + strconv.Itoa(1+rnd.Intn(65535))
+	}
+	if rnd.Intn(2) == 0 {
+		u += "/" + generateString(rnd, 1, 8) + "?q=" + strconv.Itoa(rnd.Intn(100))
+	}
+	return u
+}
+
+// generateString returns a string of min to max bytes: letters, digits, spaces and
+// punctuation, some of them outside of ASCII. It never holds a placeholder or an
+// expression.
+func generateString(rnd *rand.Rand, min, max int) string {
+	alphabet := []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789 -_.:/=,;@éßøΩЖ日本語😀")
+	if max < min {
+		max = min
+	}
+	n := min + rnd.Intn(max-min+1)
+	var sb s.Builder
+	for sb.Len() < n {
+		r := alphabet[rnd.Intn(len(alphabet))]
+		if utf8.RuneLen(r) > n-sb.Len() {
+			r = alphabet[rnd.Intn(26)]
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// generateMatch returns a random string matching the regular expression pattern.
+func generateMatch(rnd *rand.Rand, pattern string) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var sb s.Builder
+	generateRegexp(rnd, re.Simplify(), &sb)
+	return sb.String(), nil
+}
+
+func generateRegexp(rnd *rand.Rand, re *syntax.Regexp, sb *s.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && rnd.Intn(2) == 0 {
+				r = unicode.SimpleFold(r)
+			}
+			sb.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		sb.WriteRune(classRune(rnd, re.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune(' ' + rnd.Intn('~'-' '+1)))
+	case syntax.OpCapture:
+		generateRegexp(rnd, re.Sub[0], sb)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, 3
+		case syntax.OpPlus:
+			min, max = 1, 4
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + 3
+		}
+		for n := min + rnd.Intn(max-min+1); n > 0; n-- {
+			generateRegexp(rnd, re.Sub[0], sb)
+		}
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateRegexp(rnd, sub, sb)
+		}
+	case syntax.OpAlternate:
+		generateRegexp(rnd, re.Sub[rnd.Intn(len(re.Sub))], sb)
+	}
+}
+
+// classRune returns a random rune of the character class held by ranges, pairs of
+// inclusive bounds, preferring printable ones.
+func classRune(rnd *rand.Rand, ranges []rune) rune {
+	if len(ranges) == 0 {
+		return 'x'
+	}
+	var r rune
+	for i := 0; i < 10; i++ {
+		j := 2 * rnd.Intn(len(ranges)/2)
+		lo, hi := ranges[j], ranges[j+1]
+		if hi > unicode.MaxRune {
+			hi = unicode.MaxRune
+		}
+		r = lo + rune(rnd.Int63n(int64(hi-lo)+1))
+		if utf8.ValidRune(r) && unicode.IsPrint(r) {
+			return r
+		}
+	}
+	return r
+}
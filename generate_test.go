@@ -0,0 +1,92 @@
+package gconfig
+
+import (
+	"math/rand"
+	"regexp"
+	"strconv"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	schema := Schema{
+		"server.port":   {Type: TypeInt, Required: true, Min: Bound(1), Max: Bound(65535)},
+		"server.ratio":  {Type: TypeFloat, Min: Bound(0), Max: Bound(1)},
+		"server.debug":  {Type: TypeBool},
+		"server.delay":  {Type: TypeDuration, Required: true},
+		"db.url":        {Type: TypeURL, Required: true},
+		"db.name":       {Required: true, Min: Bound(3), Max: Bound(12)},
+		"db.mode":       {Enum: []string{"primary", "replica"}},
+		"release.tag":   {Required: true, Pattern: `v[0-9]+\.[0-9]+(-(rc|beta)[0-9]?)?`},
+		"pool.size":     {Type: TypeInt, Required: true, Min: Bound(1), Max: Bound(64)},
+		"pool.min-size": {Type: TypeInt, Min: Bound(0), Max: Bound(64), Expr: "int(value) <= int(pool.size)"},
+	}
+
+	rnd := rand.New(rand.NewSource(1))
+	seen := make(map[string]bool)
+	for i := 0; i < 200; i++ {
+		values, err := schema.Generate(rnd)
+		if err != nil {
+			t.Fatalf("Generate failed: %s", err)
+		}
+		c, err := NewFromMap(values, "", WithSchema(schema))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Validate(); err != nil {
+			t.Fatalf("Generated an invalid configuration %v: %s", values, err)
+		}
+		for k, rule := range schema {
+			if _, ok := values[k]; rule.Required && !ok {
+				t.Fatalf("Required key %s wasn't generated", k)
+			}
+		}
+		seen[values["server.port"]] = true
+		_, ok := values["db.mode"]
+		seen["db.mode="+strconv.FormatBool(ok)] = true
+	}
+	if !seen["1"] || !seen["65535"] {
+		t.Error("Expected the bounds of server.port to be generated")
+	}
+	if !seen["db.mode=true"] || !seen["db.mode=false"] {
+		t.Error("Expected optional keys to be both generated and left out")
+	}
+}
+
+func TestGenerateSeed(t *testing.T) {
+	schema := Schema{"a": {Required: true}, "b": {Type: TypeInt}, "c": {Type: TypeDuration}}
+	a, _ := schema.Generate(rand.New(rand.NewSource(42)))
+	b, _ := schema.Generate(rand.New(rand.NewSource(42)))
+	if len(a) != len(b) {
+		t.Fatalf("Expected the same configuration for the same seed, got %v and %v", a, b)
+	}
+	for k, v := range a {
+		if b[k] != v {
+			t.Fatalf("Expected the same configuration for the same seed, got %v and %v", a, b)
+		}
+	}
+}
+
+func TestGenerateUnsatisfiable(t *testing.T) {
+	schema := Schema{"a": {Type: TypeInt, Required: true, Min: Bound(5), Max: Bound(1)}}
+	if _, err := schema.Generate(rand.New(rand.NewSource(1))); err == nil {
+		t.Fatal("Expected an error for an unsatisfiable schema")
+	} else if _, ok := err.(*ValidationError); !ok {
+		t.Fatalf("Expected a *ValidationError, got %T", err)
+	}
+}
+
+func TestGenerateMatch(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	for _, pattern := range []string{`[a-z]{3,8}`, `(?i)prod|staging`, `\d{4}-\d{2}`, `[^/]+/v[1-9]`, `a.b*c?`} {
+		re := regexp.MustCompile(`^(?:` + pattern + `)$`)
+		for i := 0; i < 50; i++ {
+			v, err := generateMatch(rnd, pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !re.MatchString(v) {
+				t.Fatalf("%q doesn't match %s", v, pattern)
+			}
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package gconfig
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	s "strings"
+
+	"github.com/pkg/errors"
+)
+
+// isGlob reports whether the configuration path is a pattern, eg: conf.d/*.properties.
+// The volume name is ignored, so \\?\C:\config is a plain path on Windows.
+func isGlob(p string) bool {
+	return s.ContainsAny(p[len(filepath.VolumeName(p)):], "*?[")
+}
+
+// loadGlob reads every file matching pattern, see WithPath, into c.
+func (c *GConfig) loadGlob(fsys fileSystem, pattern string, o *options) error {
+	_, files, err := fsys.glob(pattern)
+	if err != nil {
+		return errors.Wrapf(err, "Error reading config files matching %s", pattern)
+	}
+
+	bases := o.configBases()
+	levels := make(map[string]int)
+	for i, name := range profileFiles(bases, c.Profile) {
+		levels[name] = i
+	}
+	fragmentLevel := func(name string) (int, bool) {
+		for i, base := range bases {
+			switch {
+			case name == fmt.Sprintf("%s-%s%s", base, c.Profile, PropertiesExtension):
+				return 2 * i, true
+			case s.HasPrefix(name, fmt.Sprintf("%s-%s-", base, c.Profile)):
+				return 2*i + 1, true
+			}
+		}
+		return 0, false
+	}
+	isProfileFile := func(name string) bool {
+		for _, base := range bases {
+			if s.HasPrefix(name, base+"-") && s.HasSuffix(name, PropertiesExtension) {
+				return true
+			}
+		}
+		return false
+	}
+	type match struct {
+		path, rel string
+		level     int
+	}
+	var defaults, profiles []match
+	for _, f := range files {
+		if !o.ownFile(f.rel) {
+			//the file of another application
+			continue
+		}
+		name := path.Base(f.rel)
+		level, ok := levels[name]
+		fragment, isFragment := 0, false
+		if o.profileFragments && c.Profile != "" {
+			fragment, isFragment = fragmentLevel(name)
+		}
+		switch {
+		case isFragment:
+			profiles = append(profiles, match{f.path, f.rel, fragment})
+		case ok && !o.profileFragments:
+			profiles = append(profiles, match{f.path, f.rel, level})
+		case isProfileFile(name):
+			//the file of another profile
+		case o.appName != "" && name == o.appName+PropertiesExtension:
+			//the defaults of the application win over the shared ones
+			defaults = append(defaults, match{f.path, f.rel, 1})
+		default:
+			defaults = append(defaults, match{f.path, f.rel, 0})
+		}
+	}
+	if len(defaults)+len(profiles) == 0 {
+		return errors.Wrapf(ErrConfigFileRequired, "Config file not found matching %s", pattern)
+	}
+	sort.SliceStable(defaults, func(i, j int) bool { return defaults[i].level < defaults[j].level })
+	sort.SliceStable(profiles, func(i, j int) bool { return profiles[i].level < profiles[j].level })
+
+	for i, layer := range [][]match{defaults, profiles} {
+		for _, m := range layer {
+			fi, err := fsys.stat(m.path)
+			if err != nil {
+				return errors.Wrapf(err, "Error opening config file %s", m.rel)
+			}
+			if err := c.addFile(fsys, o, fi, m.path, m.rel, i == 1); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// globMatch is a file matching a configuration path pattern.
+type globMatch struct {
+	// path is the path to open and rel the slash separated path relative to the base
+	// directory of the pattern
+	path, rel string
+}
+
+// glob returns the files matching pattern, sorted by path, and the directory holding
+// them: the part of pattern before its first wildcard. Besides the wildcards of path.Match,
+// a ** segment matches any number of directories, hidden ones excepted.
+func (f fileSystem) glob(pattern string) (string, []globMatch, error) {
+	base, segs := splitGlob(pattern, f.fsys == nil)
+	for _, seg := range segs {
+		if _, err := path.Match(seg, ""); err != nil {
+			return "", nil, err
+		}
+	}
+
+	relPath := func(p string) string {
+		rel, dir := filepath.ToSlash(p), filepath.ToSlash(base)
+		switch {
+		case dir == ".":
+		case s.HasSuffix(dir, "/"):
+			rel = s.TrimPrefix(rel, dir)
+		default:
+			rel = s.TrimPrefix(rel, dir+"/")
+		}
+		return rel
+	}
+	var matches []globMatch
+	err := f.walkDir(base, func(p string, d fs.DirEntry, err error) error {
+		switch {
+		case err != nil && (p == base || d == nil):
+			return err
+		case d.IsDir() && p == base:
+			return nil
+		case d.IsDir():
+			//hidden directories and the ones none of the files of which can match are
+			//skipped, along with the errors reading them
+			if s.HasPrefix(d.Name(), ".") || !matchDir(segs, s.Split(relPath(p), "/")) {
+				return fs.SkipDir
+			}
+			return err
+		}
+		if rel := relPath(p); matchSegments(segs, s.Split(rel, "/")) {
+			matches = append(matches, globMatch{path: p, rel: rel})
+		}
+		return nil
+	})
+	sort.Slice(matches, func(i, j int) bool { return matches[i].rel < matches[j].rel })
+	return base, matches, err
+}
+
+// splitGlob splits pattern into the directory before its first wildcard and the slash
+// separated segments matched from there. A native pattern is an OS path, which may start
+// with a volume name, eg: C:\conf.d\*.properties or \\server\share\*.properties on Windows.
+func splitGlob(pattern string, native bool) (string, []string) {
+	vol := ""
+	if native {
+		vol = filepath.VolumeName(pattern)
+	}
+	segs := s.Split(filepath.ToSlash(pattern[len(vol):]), "/")
+	i := 0
+	for i < len(segs) && !s.ContainsAny(segs[i], "*?[") {
+		i++
+	}
+
+	base := s.Join(segs[:i], "/")
+	switch {
+	case base == "" && i > 0:
+		base = "/"
+	case base == "" && vol == "":
+		base = "."
+	}
+	if native {
+		base = vol + filepath.FromSlash(base)
+	}
+	return base, segs[i:]
+}
+
+// matchSegments reports whether the segments of a path match the segments of a pattern.
+func matchSegments(pattern, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(name) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], name[0])
+	return ok && matchSegments(pattern[1:], name[1:])
+}
+
+// matchDir reports whether the segments of a pattern may match a file under the directory
+// with the given segments, so the walk of a pattern without ** stops at its depth.
+func matchDir(pattern, dir []string) bool {
+	if len(dir) == 0 {
+		return len(pattern) > 0
+	}
+	if len(pattern) == 0 {
+		return false
+	}
+	if pattern[0] == "**" {
+		return true
+	}
+	ok, _ := path.Match(pattern[0], dir[0])
+	return ok && matchDir(pattern[1:], dir[1:])
+}
@@ -0,0 +1,188 @@
+package gconfig
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGlobPath(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"conf.d/db", "conf.d/.hidden", "conf.d/kafka/tls"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(t, dir, "conf.d/10-app.properties", "app.name=gconfig\napp.port=80\ndb.host=none\n")
+	writeConfig(t, dir, "conf.d/20-app.properties", "app.port=8080\n")
+	writeConfig(t, dir, "conf.d/db/db.properties", "db.host=localhost\n")
+	writeConfig(t, dir, "conf.d/kafka/tls/tls.properties", "kafka.tls=true\n")
+	writeConfig(t, dir, "conf.d/.hidden/x.properties", "app.name=hidden\n")
+	writeConfig(t, dir, "conf.d/application-prod.properties", "app.port=443\n")
+	writeConfig(t, dir, "conf.d/application-dev.properties", "app.port=3000\n")
+	writeConfig(t, dir, "conf.d/notes.txt", "app.name=notes\n")
+
+	gcg, err := Load(WithPath(filepath.Join(dir, "conf.d", "*.properties")), WithProfile("prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.GetInt("app.port"); p != 443 {
+		t.Errorf("The profile file should win over the other files but app.port was %d", p)
+	}
+	if h := gcg.GetString("db.host"); h != "none" {
+		t.Errorf("* shouldn't match sub directories but db.host was %s", h)
+	}
+
+	gcg, err = Load(WithPath(filepath.Join(dir, "conf.d", "**", "*.properties")), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"app.name": "gconfig", "app.port": "8080", "db.host": "localhost", "kafka.tls": "true"}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Key %s should be %s but was %s", k, v, got)
+		}
+	}
+	if o, _ := gcg.Origin("db.host"); o.Source != "db.properties" {
+		t.Errorf("Unexpected origin %v", o)
+	}
+	if _, ok := gcg.current().sources["kafka/tls/tls.properties"]; !ok {
+		t.Errorf("Files should be tracked by their relative path: %v", gcg.current().sources)
+	}
+
+	writeConfig(t, dir, "conf.d/30-app.properties", "app.port=9090\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.GetInt("app.port"); p != 9090 {
+		t.Errorf("Reload should match the pattern again but app.port was %d", p)
+	}
+
+	if _, err := Load(WithPath(filepath.Join(dir, "conf.d", "*.yaml"))); err == nil {
+		t.Error("A pattern matching no file should fail")
+	}
+	if _, err := Load(WithPath(filepath.Join(dir, "conf.d", "[.properties"))); err == nil {
+		t.Error("A malformed pattern should fail")
+	}
+}
+
+func TestGlobPathFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"application.properties":         {Data: []byte("app.name=gconfig\n")},
+		"conf.d/a.properties":            {Data: []byte("app.name=a\napp.port=80\n")},
+		"conf.d/b.properties":            {Data: []byte("app.port=8080\n")},
+		"conf.d/.env/skipped.properties": {Data: []byte("app.port=1\n")},
+	}
+	gcg, err := Load(WithFS(fsys), WithPath("conf.d/*.properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, p := gcg.GetString("app.name"), gcg.GetInt("app.port"); n != "a" || p != 8080 {
+		t.Errorf("Files should be merged in order but got %s, %d", n, p)
+	}
+
+	gcg, err = Load(WithFS(fsys), WithPath("**/*.properties"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "a" {
+		t.Errorf("Files should be merged by path, conf.d/a.properties last, but app.name was %s", n)
+	}
+}
+
+func TestMatchSegments(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.properties", "a.properties", true},
+		{"*.properties", "x/a.properties", false},
+		{"**/*.properties", "a.properties", true},
+		{"**/*.properties", "x/y/a.properties", true},
+		{"x/**/a.properties", "x/a.properties", true},
+		{"x/**", "x/y/z", true},
+		{"x/**/b.properties", "x/y/a.properties", false},
+	} {
+		if m := matchSegments(strings.Split(tc.pattern, "/"), strings.Split(tc.name, "/")); m != tc.match {
+			t.Errorf("Pattern %s matching %s should be %v", tc.pattern, tc.name, tc.match)
+		}
+	}
+}
+
+func TestMatchDir(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, dir string
+		match        bool
+	}{
+		{"*.properties", "x", false},
+		{"*/*.properties", "x", true},
+		{"*/*.properties", "x/y", false},
+		{"conf.d/*.properties", "other", false},
+		{"**/*.properties", "x/y/z", true},
+		{"x/**", "x/y", true},
+	} {
+		if m := matchDir(strings.Split(tc.pattern, "/"), strings.Split(tc.dir, "/")); m != tc.match {
+			t.Errorf("Pattern %s matching under %s should be %v", tc.pattern, tc.dir, tc.match)
+		}
+	}
+}
+
+// brokenFS is a file system the directories of which named broken can't be read.
+type brokenFS struct{ fstest.MapFS }
+
+func (b brokenFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if path.Base(name) == "broken" {
+		return nil, fs.ErrPermission
+	}
+	return b.MapFS.ReadDir(name)
+}
+
+func TestGlobPathSkipsDirectories(t *testing.T) {
+	fsys := brokenFS{fstest.MapFS{
+		"conf.d/a.properties":               {Data: []byte("app.name=a\n")},
+		"conf.d/broken/b.properties":        {Data: []byte("app.name=b\n")},
+		"conf.d/deep/broken/c.properties":   {Data: []byte("app.name=c\n")},
+		"conf.d/deep/nested/d.properties":   {Data: []byte("app.name=d\n")},
+		"conf.d/deep/nested/x/e.properties": {Data: []byte("app.name=e\n")},
+	}}
+	_, files, err := (fileSystem{fsys: fsys}).glob("conf.d/*.properties")
+	if err != nil {
+		t.Fatalf("Directories the pattern can't match should be skipped but got %v", err)
+	}
+	if len(files) != 1 || files[0].rel != "a.properties" {
+		t.Errorf("Only conf.d/a.properties should match but got %v", files)
+	}
+
+	_, files, err = (fileSystem{fsys: fsys}).glob("conf.d/d*/nested/*.properties")
+	if err != nil {
+		t.Fatalf("Directories the pattern can't match should be skipped but got %v", err)
+	}
+	if len(files) != 1 || files[0].rel != "deep/nested/d.properties" {
+		t.Errorf("Only conf.d/deep/nested/d.properties should match but got %v", files)
+	}
+
+	if _, _, err := (fileSystem{fsys: fsys}).glob("conf.d/**/*.properties"); !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("A directory the pattern may match should fail the walk but got %v", err)
+	}
+}
+
+func TestSplitGlob(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, base, segs string
+	}{
+		{"conf.d/*.properties", "conf.d", "*.properties"},
+		{"*.properties", ".", "*.properties"},
+		{"/etc/app/**/*.properties", "/etc/app", "**,*.properties"},
+		{"/*.properties", "/", "*.properties"},
+	} {
+		base, segs := splitGlob(tc.pattern, false)
+		if base != tc.base || strings.Join(segs, ",") != tc.segs {
+			t.Errorf("Pattern %s should split into %s and %s but got %s and %v", tc.pattern, tc.base, tc.segs, base, segs)
+		}
+	}
+}
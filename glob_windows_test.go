@@ -0,0 +1,51 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitGlobWindows(t *testing.T) {
+	for _, tc := range []struct {
+		pattern, base, segs string
+	}{
+		{`C:\conf.d\*.properties`, `C:\conf.d`, "*.properties"},
+		{`C:\*.properties`, `C:\`, "*.properties"},
+		{`C:*.properties`, `C:`, "*.properties"},
+		{`\\server\share\conf.d\**\*.properties`, `\\server\share\conf.d`, "**,*.properties"},
+		{`conf.d\*.properties`, `conf.d`, "*.properties"},
+	} {
+		base, segs := splitGlob(tc.pattern, true)
+		if base != tc.base || strings.Join(segs, ",") != tc.segs {
+			t.Errorf("Pattern %s should split into %s and %s but got %s and %v", tc.pattern, tc.base, tc.segs, base, segs)
+		}
+	}
+
+	if isGlob(`\\?\C:\config`) {
+		t.Error(`\\?\ paths aren't patterns`)
+	}
+	if !isGlob(`\\server\share\*.properties`) {
+		t.Error("UNC patterns should be detected")
+	}
+}
+
+func TestWindowsPaths(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("A drive letter path should load but app.name was %s", n)
+	}
+
+	gcg, err = Load(WithPath(dir+`\*.properties`), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("A drive letter pattern should load but app.name was %s", n)
+	}
+}
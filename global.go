@@ -0,0 +1,48 @@
+package gconfig
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ErrNotLoaded is returned, or used as the panic value, when the global configuration
+// is accessed before Load or SetGlobal ran.
+var ErrNotLoaded = errors.New("gconfig: configuration used before Load; call gconfig.Load or gconfig.SetGlobal first")
+
+var (
+	globalMu sync.RWMutex
+	global   *GConfig
+)
+
+// Global returns the configuration installed by the last successful Load or SetGlobal.
+// It panics with ErrNotLoaded if there is none yet, which usually means a package
+// touched the configuration from its init function.
+func Global() *GConfig {
+	c, err := TryGlobal()
+	if err != nil {
+		panic(err)
+	}
+	return c
+}
+
+// TryGlobal is like Global but returns ErrNotLoaded instead of panicking.
+func TryGlobal() (*GConfig, error) {
+	globalMu.RLock()
+	defer globalMu.RUnlock()
+	if global == nil {
+		return nil, ErrNotLoaded
+	}
+	return global, nil
+}
+
+// SetGlobal installs c as the global configuration returned by Global. Load calls it
+// automatically; tests and applications building their own GConfig can call it directly.
+// Variables bound with BindString and the like are set from c.
+func SetGlobal(c *GConfig) {
+	globalMu.Lock()
+	global = c
+	Gcg = c
+	globalMu.Unlock()
+	rebind()
+}
@@ -0,0 +1,24 @@
+package gconfig
+
+import "testing"
+
+func TestGlobal(t *testing.T) {
+	SetGlobal(nil)
+	if _, err := TryGlobal(); err != ErrNotLoaded {
+		t.Errorf("Expected ErrNotLoaded before Load but got %v", err)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != ErrNotLoaded {
+				t.Errorf("Global should panic with ErrNotLoaded but got %v", r)
+			}
+		}()
+		Global()
+	}()
+
+	gcg := setup("dev", t)
+	if Global() != gcg || Gcg != gcg {
+		t.Errorf("Load should install the loaded configuration as the global")
+	}
+}
@@ -0,0 +1,61 @@
+package gconfig
+
+import (
+	"fmt"
+	s "strings"
+
+	"github.com/pkg/errors"
+)
+
+// ImportKey lists, comma separated, properties files to merge into the file defining it,
+// eg: gconfig.import=common.properties,security.properties. Relative paths are resolved
+// against the importing file's directory. Imports are merged in order, later ones winning,
+// and the importing file's own values override them all. Imported files can import others.
+const ImportKey = "gconfig.import"
+
+// resolveImports merges the files listed under ImportKey beneath the values of cf.
+func (cf *configFile) resolveImports(fsys fileSystem, dir string, seen map[string]bool) error {
+	list, _ := cf.configs[ImportKey].(string)
+	if list == "" {
+		return nil
+	}
+	delete(cf.configs, ImportKey)
+	delete(cf.positions, ImportKey)
+
+	merged := make(map[string]interface{})
+	positions := make(map[string]position)
+	for _, name := range s.Split(list, ",") {
+		name = s.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		path, err := fsys.resolve(dir, name)
+		if err != nil {
+			return errors.Wrapf(err, "Error importing %s into %s", name, cf.Name())
+		}
+		if seen[path] {
+			return fmt.Errorf("Import cycle: %s imports %s which is already being read", cf.Name(), name)
+		}
+
+		fi, err := fsys.stat(path)
+		if err != nil {
+			return errors.Wrapf(fileError(err), "Error importing %s into %s", name, cf.Name())
+		}
+		imported, err := readImporting(fsys, fi, path, seen)
+		if err != nil {
+			return err
+		}
+		for k, v := range imported.configs {
+			merged[k] = v
+			positions[k] = imported.positions[k]
+		}
+		cf.warnings = append(cf.warnings, imported.warnings...)
+	}
+
+	for k, v := range cf.configs {
+		merged[k] = v
+		positions[k] = cf.positions[k]
+	}
+	cf.configs, cf.positions = merged, positions
+	return nil
+}
@@ -0,0 +1,47 @@
+package gconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImport(t *testing.T) {
+	dir := t.TempDir()
+	os.Mkdir(filepath.Join(dir, "shared"), 0755)
+	writeConfig(t, dir, StandardPropFileName, "gconfig.import=shared/common.properties, security.properties\napp.name=main\n")
+	writeConfig(t, dir, "shared/common.properties", "gconfig.import=base.properties\napp.name=common\ndb.host=common-db\nlog.level=info\n")
+	writeConfig(t, dir, "shared/base.properties", "log.level=debug\nbase.only=yes\n")
+	writeConfig(t, dir, "security.properties", "db.host=secure-db\n")
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{
+		"app.name":  "main",
+		"db.host":   "secure-db",
+		"log.level": "info",
+		"base.only": "yes",
+	}
+	for k, v := range expected {
+		if got := gcg.GetString(k); got != v {
+			t.Errorf("Key %s should be %s but was %s", k, v, got)
+		}
+	}
+	if gcg.Exists(ImportKey) {
+		t.Errorf("The import directive should not be exposed as a key")
+	}
+}
+
+func TestImportCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "gconfig.import=a.properties\n")
+	writeConfig(t, dir, "a.properties", "gconfig.import=application.properties\n")
+
+	if _, err := Load(WithPath(dir)); err == nil || !strings.Contains(err.Error(), "Import cycle") {
+		t.Errorf("Load should report the import cycle but got %v", err)
+	}
+}
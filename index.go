@@ -0,0 +1,83 @@
+package gconfig
+
+import (
+	s "strings"
+	"sync/atomic"
+)
+
+// aliasGeneration counts the calls to RegisterAlias, so lookup indexes built before an
+// alias was registered are recognized as stale.
+var aliasGeneration uint64
+
+// lookupIndex holds the raw value of every key of a snapshot, and of its aliases, merged
+// across the layers, so reads are a single map lookup instead of one per layer.
+type lookupIndex struct {
+	values map[string]interface{}
+	// scheduled holds the keys with an activateAt or deactivateAt entry
+	scheduled map[string]bool
+	// aliases is the aliasGeneration the index was built with
+	aliases uint64
+}
+
+// seal builds the lookup index of vs, once it's complete and about to be published, and
+// returns vs. Snapshots modified after being sealed, or whose aliases changed since, are
+// read layer by layer.
+func (vs *values) seal() *values {
+	ix := &lookupIndex{
+		values:    make(map[string]interface{}),
+		scheduled: make(map[string]bool),
+		aliases:   atomic.LoadUint64(&aliasGeneration),
+	}
+	add := func(k string) {
+		if _, done := ix.values[k]; done {
+			return
+		}
+		if v := vs.lookup(k); v != nil {
+			ix.values[k] = v
+		}
+	}
+	for _, m := range [...]map[string]string{vs.overrides, vs.flags, vs.env, vs.sourced, vs.dotEnv, vs.defaults} {
+		for k := range m {
+			ix.add(k, add)
+		}
+	}
+	for _, m := range [...]map[string]interface{}{vs.profileConfig.configs, vs.defaultConfig.configs} {
+		for k := range m {
+			ix.add(k, add)
+		}
+	}
+	vs.index = ix
+	return vs
+}
+
+// add indexes key and its aliases with add, and records its schedule.
+func (ix *lookupIndex) add(key string, add func(string)) {
+	add(key)
+	for _, alt := range aliasesOf(key) {
+		add(alt)
+	}
+	switch {
+	case s.HasSuffix(key, ActivateAtSuffix):
+		ix.scheduled[s.TrimSuffix(key, ActivateAtSuffix)] = true
+	case s.HasSuffix(key, DeactivateAtSuffix):
+		ix.scheduled[s.TrimSuffix(key, DeactivateAtSuffix)] = true
+	}
+}
+
+// indexed returns the raw value of key from the lookup index, and false when the index
+// can't tell and the layers must be searched.
+func (vs *values) indexed(key string) (interface{}, bool) {
+	ix := vs.index
+	if ix == nil || ix.aliases != atomic.LoadUint64(&aliasGeneration) {
+		return nil, false
+	}
+	v, ok := ix.values[key]
+	if !ok && vs.normalize {
+		//the key may be defined under another spelling
+		return nil, false
+	}
+	if ok && ix.scheduled[key] && !vs.active(key, now()) {
+		return nil, true
+	}
+	return v, true
+}
@@ -0,0 +1,109 @@
+package gconfig
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestLookupIndex(t *testing.T) {
+	withDeprecations(t)
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.pool=10\ndb.host=localhost\nlegacy.timeout=5s\n")
+	writeConfig(t, dir, "application-prod.properties", "db.pool=20\n")
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithoutFlags(), WithoutEnv(), WithDefaults(map[string]string{"db.port": "5432"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.current().index == nil {
+		t.Fatal("A loaded snapshot should be indexed")
+	}
+	for k, want := range map[string]string{"db.pool": "20", "db.host": "localhost", "db.port": "5432", "db.missing": ""} {
+		if v := gcg.GetString(k); v != want {
+			t.Errorf("Expected %s=%q but got %q", k, want, v)
+		}
+	}
+
+	gcg.Set("db.pool", "30")
+	if v := gcg.GetString("db.pool"); v != "30" || gcg.current().index == nil {
+		t.Errorf("Set should publish an indexed snapshot holding its value but got %s", v)
+	}
+
+	//an alias registered after the snapshot was indexed is still honoured
+	RegisterAlias("legacy.timeout", "db.timeout")
+	if v := gcg.GetString("db.timeout"); v != "5s" {
+		t.Errorf("Expected db.timeout read through its alias but got %q", v)
+	}
+	gcg.Unset("db.pool")
+	if v := gcg.GetString("db.timeout"); v != "5s" || gcg.GetString("db.pool") != "20" {
+		t.Errorf("Expected db.timeout read through its alias from a new index but got %q", v)
+	}
+}
+
+func TestLookupIndexNormalized(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"db.max_pool_size": "10"}, "", NormalizeKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := gcg.GetString("db.max-pool-size"); v != "10" {
+		t.Errorf("Expected the normalized key read past the index but got %q", v)
+	}
+}
+
+func BenchmarkGetString(b *testing.B) {
+	b.ReportAllocs()
+	gcg := benchConfig(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gcg.GetString("service.key500")
+	}
+}
+
+func BenchmarkGetInt(b *testing.B) {
+	b.ReportAllocs()
+	gcg := benchConfig(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gcg.GetInt("service.port")
+	}
+}
+
+func BenchmarkGetStringParallel(b *testing.B) {
+	b.ReportAllocs()
+	gcg := benchConfig(b)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			gcg.GetString("service.key500")
+		}
+	})
+}
+
+func BenchmarkGetStringPlaceholder(b *testing.B) {
+	b.ReportAllocs()
+	gcg := benchConfig(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gcg.GetString("service.url")
+	}
+}
+
+// benchConfig loads a configuration of a thousand keys spread over a file, its profile
+// file, defaults and overrides.
+func benchConfig(b *testing.B) *GConfig {
+	dir := b.TempDir()
+	var defaults, prod string
+	for i := 0; i < 1000; i++ {
+		defaults += fmt.Sprintf("service.key%d=value%d\n", i, i)
+		if i%2 == 0 {
+			prod += fmt.Sprintf("service.key%d=prod%d\n", i, i)
+		}
+	}
+	writeConfig(b, dir, StandardPropFileName, defaults+"service.host=localhost\nservice.url=http://${service.host}:${service.port}\n")
+	writeConfig(b, dir, "application-prod.properties", prod)
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithoutFlags(), WithoutEnv(), WithDefaults(map[string]string{"service.port": "8080"}))
+	if err != nil {
+		b.Fatal(err)
+	}
+	gcg.Set("service.key999", "override")
+	return gcg
+}
@@ -0,0 +1,121 @@
+package gconfig
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// KubernetesOptions configures the manifests written by WriteKubernetes.
+type KubernetesOptions struct {
+	// Name names the ConfigMap, the Secret is named Name-secret.
+	Name      string
+	Namespace string
+	// EnvPrefix, when set, writes the keys as the environment variables read by
+	// WithEnvPrefix, eg: APP_DB_URL, for use with envFrom. Otherwise the keys are kept
+	// as-is, for mounting the manifests as volumes read with KeyPerFile.
+	EnvPrefix string
+	// KeepPlaceholders writes ${...} references unresolved, see Bake.
+	KeepPlaceholders bool
+}
+
+// WriteKubernetes writes the effective configuration as a ConfigMap and a Secret, in a
+// multi-document YAML stream. Sensitive keys, see IsSensitive, go into the Secret and
+// the rest into the ConfigMap. Schema keys without a value are written empty so the
+// manifests list everything the application expects.
+func (c *GConfig) WriteKubernetes(w io.Writer, ko KubernetesOptions) error {
+	if ko.Name == "" {
+		return fmt.Errorf("Kubernetes manifests need a name")
+	}
+
+	plain, secret := make(map[string]string), make(map[string]string)
+	for _, k := range c.manifestKeys() {
+		v, _ := c.getValue(k).(string)
+		if !ko.KeepPlaceholders {
+			v = c.replaceSysVars(k)
+		}
+		name := k
+		if ko.EnvPrefix != "" {
+			name = envName(ko.EnvPrefix, k)
+		}
+		if c.IsSensitive(k) {
+			secret[name] = v
+		} else {
+			plain[name] = v
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# generated by gconfig for profile '%s'\n", c.Profile)
+	writeManifest(bw, "ConfigMap", ko.Name, ko.Namespace, "data", plain)
+	if len(secret) > 0 {
+		bw.WriteString("---\n")
+		writeManifest(bw, "Secret", ko.Name+"-secret", ko.Namespace, "stringData", secret)
+	}
+	return bw.Flush()
+}
+
+// WriteKubernetesEnvFrom writes the envFrom block of a container spec loading the
+// manifests written by WriteKubernetes with the same options into the environment.
+func (c *GConfig) WriteKubernetesEnvFrom(w io.Writer, ko KubernetesOptions) error {
+	hasSecret := false
+	for _, k := range c.manifestKeys() {
+		hasSecret = hasSecret || c.IsSensitive(k)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "envFrom:\n  - configMapRef:\n      name: %s\n", ko.Name)
+	if hasSecret {
+		fmt.Fprintf(bw, "  - secretRef:\n      name: %s-secret\n", ko.Name)
+	}
+	return bw.Flush()
+}
+
+// manifestKeys returns the sorted keys of the configuration and of the schema.
+func (c *GConfig) manifestKeys() []string {
+	keys := c.keys()
+	seen := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		seen[k] = true
+	}
+	for k := range c.schema {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// writeManifest writes a ConfigMap or Secret holding values under the given field.
+func writeManifest(w *bufio.Writer, kind, name, namespace, field string, values map[string]string) {
+	fmt.Fprintf(w, "apiVersion: v1\nkind: %s\nmetadata:\n  name: %s\n", kind, name)
+	if namespace != "" {
+		fmt.Fprintf(w, "  namespace: %s\n", namespace)
+	}
+	if kind == "Secret" {
+		w.WriteString("type: Opaque\n")
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if len(keys) == 0 {
+		fmt.Fprintf(w, "%s: {}\n", field)
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", field)
+	for _, k := range keys {
+		v, _ := json.Marshal(values[k])
+		qk := k
+		if !plainYAMLKey.MatchString(k) {
+			b, _ := json.Marshal(k)
+			qk = string(b)
+		}
+		fmt.Fprintf(w, "  %s: %s\n", qk, v)
+	}
+}
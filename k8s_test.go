@@ -0,0 +1,59 @@
+package gconfig
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteKubernetes(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.host=localhost\ndb.password=s3cret\ndb.url=postgres://${db.host}\n")
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithSchema(Schema{"db.max_conns": {Type: TypeInt}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := gcg.WriteKubernetes(&buf, KubernetesOptions{Name: "app", Namespace: "web", EnvPrefix: "APP"}); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# generated by gconfig for profile 'prod'
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: app
+  namespace: web
+data:
+  APP_DB_HOST: "localhost"
+  APP_DB_MAX__CONNS: ""
+  APP_DB_URL: "postgres://localhost"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app-secret
+  namespace: web
+type: Opaque
+stringData:
+  APP_DB_PASSWORD: "s3cret"
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected manifests:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	gcg.WriteKubernetes(&buf, KubernetesOptions{Name: "app", KeepPlaceholders: true})
+	if !bytes.Contains(buf.Bytes(), []byte(`  db.url: "postgres://${db.host}"`)) {
+		t.Errorf("Keys should be kept without an env prefix and placeholders on demand:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	gcg.WriteKubernetesEnvFrom(&buf, KubernetesOptions{Name: "app"})
+	if buf.String() != "envFrom:\n  - configMapRef:\n      name: app\n  - secretRef:\n      name: app-secret\n" {
+		t.Errorf("Unexpected envFrom block:\n%s", buf.String())
+	}
+
+	if err := gcg.WriteKubernetes(&buf, KubernetesOptions{}); err == nil {
+		t.Error("Manifests without a name should be refused")
+	}
+}
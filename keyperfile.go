@@ -0,0 +1,75 @@
+package gconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	s "strings"
+
+	"github.com/pkg/errors"
+)
+
+// KeyPerFile returns a Source reading a directory laid out like the ConfigMap and Secret
+// volumes of Kubernetes: every file holds a single value and is named after its key, eg:
+// /etc/secrets/db.password. One trailing newline is trimmed from each value. Hidden
+// entries, including the ..data links Kubernetes uses to swap volume contents atomically,
+// and subdirectories are skipped. The source is also registered as "keyperfile://", so
+// WithSourceURL("keyperfile:///etc/config") works too.
+func KeyPerFile(dir string) Source {
+	return keyPerFile(dir)
+}
+
+// WithKeyPerFileDirs adds a KeyPerFile source for each of dirs, eg:
+// WithKeyPerFileDirs("/etc/config", "/etc/secrets"), later directories winning.
+func WithKeyPerFileDirs(dirs ...string) Option {
+	return func(o *options) {
+		for _, dir := range dirs {
+			o.sources = append(o.sources, KeyPerFile(dir))
+		}
+	}
+}
+
+type keyPerFile string
+
+func (d keyPerFile) Name() string {
+	return "keyperfile:" + string(d)
+}
+
+func (d keyPerFile) Load(ctx context.Context) (map[string]string, error) {
+	entries, err := os.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if s.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		path := filepath.Join(string(d), e.Name())
+		//entries are usually symlinks into ..data, stat follows them
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading key %s", e.Name())
+		}
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error reading key %s", e.Name())
+		}
+		v := s.TrimSuffix(string(content), "\n")
+		values[e.Name()] = s.TrimSuffix(v, "\r")
+	}
+	return values, nil
+}
+
+func init() {
+	RegisterSource("keyperfile", func(location string) (Source, error) {
+		return KeyPerFile(location), nil
+	})
+}
@@ -0,0 +1,60 @@
+package gconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyPerFile(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.user=app\ndb.password=changeme\n")
+
+	//mimic the layout of a projected volume: keys link into a timestamped directory
+	secrets := t.TempDir()
+	data := filepath.Join(secrets, "..2026_10_16_10_00_00.000000001")
+	if err := os.Mkdir(data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, data, "db.password", "s3cret\n")
+	if err := os.Symlink(filepath.Base(data), filepath.Join(secrets, "..data")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "db.password"), filepath.Join(secrets, "db.password")); err != nil {
+		t.Fatal(err)
+	}
+
+	configMap := t.TempDir()
+	writeConfig(t, configMap, "db.user", "reader")
+	writeConfig(t, configMap, "db.password", "from configmap")
+	if err := os.Mkdir(filepath.Join(configMap, "nested"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	gcg, err := Load(WithPath(dir), WithKeyPerFileDirs(configMap, secrets))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.GetString("db.password"); p != "s3cret" {
+		t.Errorf("The secrets mount should win and the newline be trimmed but db.password was %q", p)
+	}
+	if u := gcg.GetString("db.user"); u != "reader" {
+		t.Errorf("The config map should override the files but db.user was %s", u)
+	}
+	if n := gcg.GetString("app.name"); n != "gconfig" {
+		t.Errorf("Keys missing from the mounts should come from the files but app.name was %s", n)
+	}
+	for _, k := range gcg.Keys() {
+		if k == "nested" || k == "..data" {
+			t.Errorf("Key %s should have been skipped", k)
+		}
+	}
+
+	gcg, err = Load(WithPath(dir), WithSourceURL("keyperfile://"+configMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := gcg.GetString("db.user"); u != "reader" {
+		t.Errorf("The keyperfile scheme should read the directory but db.user was %s", u)
+	}
+}
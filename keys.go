@@ -0,0 +1,212 @@
+package gconfig
+
+import (
+	"sort"
+	"strconv"
+	s "strings"
+)
+
+// Keys returns the sorted keys of the effective configuration.
+func (c *GConfig) Keys() []string {
+	return c.keys()
+}
+
+// KeysWithPrefix returns the sorted keys starting with prefix.
+func (c *GConfig) KeysWithPrefix(prefix string) []string {
+	var keys []string
+	for _, k := range c.keys() {
+		if s.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// GetStringMap returns the resolved values of the keys under prefix, keyed by the rest of
+// their name, eg: headers.accept=json gives {"accept": "json"} for the prefix headers.
+func (c *GConfig) GetStringMap(prefix string) map[string]string {
+	p := s.TrimSuffix(prefix, ".") + "."
+	m := make(map[string]string)
+	for _, k := range c.KeysWithPrefix(p) {
+		m[s.TrimPrefix(k, p)] = c.getStringValue(k)
+	}
+	return m
+}
+
+// GetStringMapSlice returns the repeated groups of keys under prefix, ordered by index.
+// Groups are numbered with either servers[0].host or servers.0.host; indexes need not
+// be contiguous. Keys under prefix without an index are ignored.
+func (c *GConfig) GetStringMapSlice(prefix string) []map[string]string {
+	p := s.TrimSuffix(prefix, ".")
+	groups := make(map[int]map[string]string)
+	for _, k := range c.KeysWithPrefix(p) {
+		i, field, ok := splitIndex(s.TrimPrefix(k, p))
+		if !ok {
+			continue
+		}
+		if groups[i] == nil {
+			groups[i] = make(map[string]string)
+		}
+		groups[i][field] = c.getStringValue(k)
+	}
+
+	indexes := make([]int, 0, len(groups))
+	for i := range groups {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	slice := make([]map[string]string, len(indexes))
+	for n, i := range indexes {
+		slice[n] = groups[i]
+	}
+	return slice
+}
+
+// GetSlice returns the values of the indexed keys under prefix, ordered by index, eg:
+// hosts[0]=a and hosts[1]=b, or hosts.0=a and hosts.1=b, give [a b]; indexes need not be
+// contiguous. Without indexed keys the value of prefix is split on commas instead, so
+// both styles of list read the same. It's nil when neither is defined.
+func (c *GConfig) GetSlice(prefix string) []string {
+	if keys := c.indexedKeys(prefix); keys != nil {
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			values[i] = c.getStringValue(k)
+		}
+		return values
+	}
+	if !c.Exists(prefix) {
+		return nil
+	}
+	v := c.getStringValue(prefix)
+	if s.TrimSpace(v) == "" {
+		return []string{}
+	}
+	items := s.Split(v, ",")
+	for i, item := range items {
+		items[i] = s.TrimSpace(item)
+	}
+	return items
+}
+
+// indexedKeys returns the keys made of prefix and an index, ordered by index, nil if
+// there are none.
+func (c *GConfig) indexedKeys(prefix string) []string {
+	p := s.TrimSuffix(prefix, ".")
+	byIndex := make(map[int]string)
+	for _, k := range c.KeysWithPrefix(p) {
+		if i, ok := scalarIndex(s.TrimPrefix(k, p)); ok {
+			byIndex[i] = k
+		}
+	}
+	if len(byIndex) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(byIndex))
+	for i := range byIndex {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	keys := make([]string, len(indexes))
+	for n, i := range indexes {
+		keys[n] = byIndex[i]
+	}
+	return keys
+}
+
+// scalarIndex parses "[0]" or ".0", the rest of an indexed key holding a single value.
+func scalarIndex(rest string) (int, bool) {
+	var idx string
+	switch {
+	case s.HasPrefix(rest, "[") && s.HasSuffix(rest, "]"):
+		idx = rest[1 : len(rest)-1]
+	case s.HasPrefix(rest, "."):
+		idx = rest[1:]
+	default:
+		return 0, false
+	}
+	i, err := strconv.Atoi(idx)
+	if err != nil || i < 0 {
+		return 0, false
+	}
+	return i, true
+}
+
+// splitIndex splits "[0].host" or ".0.host" into the index and the field name.
+func splitIndex(rest string) (int, string, bool) {
+	var idx string
+	switch {
+	case s.HasPrefix(rest, "["):
+		end := s.Index(rest, "]")
+		if end < 0 {
+			return 0, "", false
+		}
+		idx, rest = rest[1:end], rest[end+1:]
+	case s.HasPrefix(rest, "."):
+		rest = rest[1:]
+		end := s.Index(rest, ".")
+		if end < 0 {
+			return 0, "", false
+		}
+		idx, rest = rest[:end], rest[end:]
+	default:
+		return 0, "", false
+	}
+	i, err := strconv.Atoi(idx)
+	if err != nil || i < 0 || !s.HasPrefix(rest, ".") || len(rest) == 1 {
+		return 0, "", false
+	}
+	return i, rest[1:], true
+}
+
+// AllSettings returns the effective configuration, after profile overrides and
+// placeholder expansion, as a map of key to value. Useful for dumping what the
+// service is actually running with at startup. Values of sensitive keys are masked.
+func (c *GConfig) AllSettings() map[string]interface{} {
+	settings := make(map[string]interface{})
+	for _, k := range c.keys() {
+		settings[k] = c.mask(k, c.replaceSysVars(k))
+	}
+	return settings
+}
+
+// keys returns the sorted set of keys defined by the default and profile configuration,
+// by Set, by flags, by the environment, by sources, by dotenv files and by registered
+// defaults. Keys that aren't active, see ActivateAtSuffix, are left out.
+func (c *GConfig) keys() []string {
+	vs := c.current()
+	seen := make(map[string]bool)
+	for k := range vs.defaultConfig.configs {
+		seen[k] = true
+	}
+	for k := range vs.profileConfig.configs {
+		seen[k] = true
+	}
+	for k := range vs.overrides {
+		seen[k] = true
+	}
+	for k := range vs.defaults {
+		seen[k] = true
+	}
+	for k := range vs.flags {
+		seen[k] = true
+	}
+	for k := range vs.env {
+		seen[k] = true
+	}
+	for k := range vs.sourced {
+		seen[k] = true
+	}
+	for k := range vs.dotEnv {
+		seen[k] = true
+	}
+
+	keys := make([]string, 0, len(seen))
+	t := now()
+	for k := range seen {
+		if s.HasPrefix(k, c.prefix) && (isScheduleKey(k) || vs.active(k, t)) {
+			keys = append(keys, s.TrimPrefix(k, c.prefix))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
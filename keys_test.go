@@ -0,0 +1,108 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	gcg := setup("dev", t)
+
+	keys := gcg.Keys()
+	if len(keys) != 8 || keys[0] != "app.name" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+
+	if keys := strings.Join(gcg.KeysWithPrefix("myEnv."), ","); keys != "myEnv.variable,myEnv.variable.listwithDefault,myEnv.variable.withDefault" {
+		t.Errorf("Unexpected keys with prefix myEnv.: %s", keys)
+	}
+}
+
+func TestAllSettings(t *testing.T) {
+	gcg := setup("dev", t)
+
+	settings := gcg.AllSettings()
+	if settings["app.name"] != "gconfig dev profile" || settings["app.version"] != "1.0" {
+		t.Errorf("Settings should merge the profile over the defaults: %v", settings)
+	}
+	if settings["myEnv.variable.withDefault"] != gcg.GetStringOrDefaultInCommaSeparator("myEnv.variable.withDefault") {
+		t.Errorf("Settings should expand placeholders: %v", settings)
+	}
+}
+
+func TestGetStringMap(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "headers.accept=json\nheaders.x-api-key=${api.key}\napi.key=secret\nheadersX=ignored\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := gcg.GetStringMap("headers")
+	if len(m) != 2 || m["accept"] != "json" || m["x-api-key"] != "secret" {
+		t.Errorf("Unexpected map %v", m)
+	}
+	if m := gcg.GetStringMap("missing."); len(m) != 0 {
+		t.Errorf("A missing prefix should give an empty map but was %v", m)
+	}
+}
+
+func TestGetStringMapSlice(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `
+servers[10].host=c
+servers[0].host=a
+servers[0].port=80
+servers.2.host=b
+servers.name=ignored
+servers[x].host=ignored
+`)
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := gcg.GetStringMapSlice("servers")
+	if len(groups) != 3 {
+		t.Fatalf("Expected 3 groups but got %v", groups)
+	}
+	if groups[0]["host"] != "a" || groups[0]["port"] != "80" || groups[1]["host"] != "b" || groups[2]["host"] != "c" {
+		t.Errorf("Groups should be ordered by index: %v", groups)
+	}
+}
+
+func TestGetSlice(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{
+		"hosts[2]": "c", "hosts[0]": "a", "hosts[1]": "${app.host}", "app.host": "b",
+		"ports.0": "80", "ports.1": "x",
+		"tags":            "red, green",
+		"servers[0].host": "s0",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := gcg.GetSlice("hosts"); strings.Join(h, ",") != "a,b,c" {
+		t.Errorf("Expected the indexed values in order but got %v", h)
+	}
+	if tags := gcg.GetSlice("tags"); strings.Join(tags, ",") != "red,green" {
+		t.Errorf("A comma separated value should be split but got %v", tags)
+	}
+	if s := gcg.GetSlice("servers"); s != nil {
+		t.Errorf("Repeated groups aren't values of a slice but got %v", s)
+	}
+	if m := gcg.GetSlice("missing"); m != nil {
+		t.Errorf("Expected nil for a missing key but got %v", m)
+	}
+
+	hosts, err := Get[[]string](gcg, "hosts")
+	if err != nil || len(hosts) != 3 {
+		t.Errorf("Get should read indexed keys but got %v, %v", hosts, err)
+	}
+	if _, err := Get[[]int](gcg, "ports"); err == nil || !strings.Contains(err.Error(), "ports.1") {
+		t.Errorf("Expected an error naming ports.1 but got %v", err)
+	}
+	var cfg struct{ Hosts []string }
+	if err := gcg.Unmarshal(&cfg); err != nil || len(cfg.Hosts) != 3 || cfg.Hosts[1] != "b" {
+		t.Errorf("Unmarshal should read indexed keys but got %v, %v", cfg.Hosts, err)
+	}
+}
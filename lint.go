@@ -0,0 +1,168 @@
+package gconfig
+
+import (
+	"fmt"
+	"sort"
+	s "strings"
+	"unicode"
+)
+
+// LintRules configures the key naming conventions checked by Lint and LintDir. Keys are
+// lowercase and dotted, eg: db.pool.max-size, and each segment holds lowercase letters,
+// digits, '-' and '_', optionally followed by an index, eg: servers[0].host.
+type LintRules struct {
+	// AllowCamelCase accepts upper case letters in segments, eg: db.maxPoolSize.
+	AllowCamelCase bool
+	// MaxDepth is the maximum number of segments in a key, 0 for no limit.
+	MaxDepth int
+	// ReservedPrefixes lists prefixes applications must not define keys under.
+	ReservedPrefixes []string
+}
+
+// DefaultLintRules returns the conventions of most gconfig projects: no camelCase, keys
+// at most 6 segments deep and the gconfig. prefix reserved for directives.
+func DefaultLintRules() LintRules {
+	return LintRules{MaxDepth: 6, ReservedPrefixes: []string{"gconfig."}}
+}
+
+// LintError lists the keys breaking the naming conventions, returned by Load when both
+// WithLint and ParseStrict are set.
+type LintError struct {
+	Warnings []Warning
+}
+
+func (e *LintError) Error() string {
+	msgs := make([]string, len(e.Warnings))
+	for i, w := range e.Warnings {
+		msgs[i] = fmt.Sprintf("%s:%d: %s", w.File, w.Line, w.Message)
+	}
+	return fmt.Sprintf("Configuration keys break the naming conventions: %s", s.Join(msgs, "; "))
+}
+
+// WithLint checks the keys of the properties files against rules on every load. Keys
+// breaking them are logged and reported by GConfig.Warnings, or fail the load with a
+// *LintError in ParseStrict mode.
+func WithLint(rules LintRules) Option {
+	return func(o *options) {
+		o.lint = &rules
+	}
+}
+
+// Lint checks the keys defined by the loaded properties files, including imported ones,
+// against rules and returns a WarningKeyName warning for each key breaking them.
+func (c *GConfig) Lint(rules LintRules) []Warning {
+	vs := c.current()
+	var warnings []Warning
+	for _, cf := range []configFile{vs.defaultConfig, vs.profileConfig} {
+		for k, p := range cf.positions {
+			if s.HasPrefix(k, c.prefix) {
+				warnings = append(warnings, rules.check(k, p)...)
+			}
+		}
+	}
+	sortWarnings(warnings)
+	return warnings
+}
+
+// LintDir checks the keys of every properties file in dir against rules, whatever the
+// profile it belongs to. Keys qualified with a bare profile name without their
+// unqualified key alongside, eg: notify@ops, are reported too, as they may be meant as
+// ordinary keys.
+func LintDir(dir string, rules LintRules) ([]Warning, error) {
+	files, err := parseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []Warning
+	for _, f := range files {
+		keys := make([]string, 0, len(f.props))
+		lines := make(map[string]int, len(f.props))
+		for _, p := range f.props {
+			if p.key != ImportKey {
+				warnings = append(warnings, rules.check(p.key, position{file: f.name, line: p.line})...)
+			}
+			keys = append(keys, p.key)
+			lines[p.key] = p.line
+		}
+		for _, k := range ambiguousConditions(keys) {
+			base, q, _ := splitCondition(k)
+			warnings = append(warnings, Warning{Kind: WarningKeyName, File: f.name, Line: lines[k], Key: k,
+				Message: fmt.Sprintf("%s is read as %s for the profile %s only, as no %s key is defined alongside", k, base, q, base)})
+		}
+	}
+	sortWarnings(warnings)
+	return warnings, nil
+}
+
+// check returns a warning for each convention key breaks. The condition of a conditional
+// key, eg: key@linux or key@prod, and the suffix of a schedule entry, eg: key.activateAt, aren't
+// checked.
+func (r LintRules) check(key string, p position) []Warning {
+	name := key
+	if base, q, ok := splitCondition(key); ok {
+		if _, isCondition := condition(q, nil); isCondition {
+			name = base
+		}
+	}
+	name = s.TrimSuffix(s.TrimSuffix(name, ActivateAtSuffix), DeactivateAtSuffix)
+
+	var msgs []string
+	for _, prefix := range r.ReservedPrefixes {
+		if s.HasPrefix(name, prefix) {
+			msgs = append(msgs, fmt.Sprintf("is under the reserved prefix %s", prefix))
+		}
+	}
+
+	segments := s.Split(name, ".")
+	if r.MaxDepth > 0 && len(segments) > r.MaxDepth {
+		msgs = append(msgs, fmt.Sprintf("has %d segments, more than %d", len(segments), r.MaxDepth))
+	}
+	for _, seg := range segments {
+		if msg := r.checkSegment(seg); msg != "" {
+			msgs = append(msgs, msg)
+			break
+		}
+	}
+
+	warnings := make([]Warning, len(msgs))
+	for i, msg := range msgs {
+		warnings[i] = Warning{Kind: WarningKeyName, File: p.file, Line: p.line, Key: key, Message: fmt.Sprintf("%s %s", key, msg)}
+	}
+	return warnings
+}
+
+// checkSegment describes why a segment of a key breaks the rules, or returns an empty string.
+func (r LintRules) checkSegment(seg string) string {
+	if i := s.IndexByte(seg, '['); i > 0 && s.HasSuffix(seg, "]") {
+		if idx := seg[i+1 : len(seg)-1]; idx == "" || s.Trim(idx, "0123456789") != "" {
+			return fmt.Sprintf("has a malformed index in %q", seg)
+		}
+		seg = seg[:i]
+	}
+	if seg == "" {
+		return "has an empty segment"
+	}
+
+	for _, c := range seg {
+		switch {
+		case unicode.IsUpper(c):
+			if !r.AllowCamelCase {
+				return fmt.Sprintf("is not lowercase in %q", seg)
+			}
+		case c > unicode.MaxASCII || !(unicode.IsLetter(c) || unicode.IsDigit(c) || c == '-' || c == '_'):
+			return fmt.Sprintf("has the invalid character %q", c)
+		}
+	}
+	return ""
+}
+
+// sortWarnings orders warnings by file and line.
+func sortWarnings(warnings []Warning) {
+	sort.SliceStable(warnings, func(i, j int) bool {
+		if warnings[i].File != warnings[j].File {
+			return warnings[i].File < warnings[j].File
+		}
+		return warnings[i].Line < warnings[j].Line
+	})
+}
@@ -0,0 +1,86 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `app.name=gconfig
+db.maxPoolSize=10
+servers[0].host=a
+gconfig.secret=x
+a.b.c.d.e.f.g=deep
+bad..key=1
+`)
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	warnings := gcg.Lint(DefaultLintRules())
+	var msgs []string
+	for _, w := range warnings {
+		if w.Kind != WarningKeyName || w.File != StandardPropFileName {
+			t.Errorf("Unexpected warning %v", w)
+		}
+		msgs = append(msgs, w.Message)
+	}
+	expected := []string{
+		`db.maxPoolSize is not lowercase in "maxPoolSize"`,
+		"gconfig.secret is under the reserved prefix gconfig.",
+		"a.b.c.d.e.f.g has 7 segments, more than 6",
+		"bad..key has an empty segment",
+	}
+	if strings.Join(msgs, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected lint warnings:\n%s", strings.Join(msgs, "\n"))
+	}
+	if warnings[0].Line != 2 {
+		t.Errorf("Warnings should carry the line of the key but was %d", warnings[0].Line)
+	}
+
+	if w := gcg.Lint(LintRules{AllowCamelCase: true}); len(w) != 1 {
+		t.Errorf("Only the empty segment should break relaxed rules: %v", w)
+	}
+}
+
+func TestWithLint(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\napp.logLevel=debug\n")
+
+	gcg, err := Load(WithPath(dir), WithLint(DefaultLintRules()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var named []Warning
+	for _, w := range gcg.Warnings() {
+		if w.Kind == WarningKeyName {
+			named = append(named, w)
+		}
+	}
+	if len(named) != 1 || named[0].Key != "app.logLevel" {
+		t.Errorf("Lint warnings should be reported by Warnings: %v", named)
+	}
+
+	_, err = Load(WithPath(dir), WithLint(DefaultLintRules()), ParseStrict())
+	if lerr, ok := err.(*LintError); !ok || len(lerr.Warnings) != 1 {
+		t.Errorf("Strict mode should fail with a *LintError but got %v", err)
+	}
+}
+
+func TestLintDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "gconfig.import=common.properties\napp.name=gconfig\n")
+	writeConfig(t, dir, "application-prod.properties", "app.Name=prod\n")
+	writeConfig(t, dir, "common.properties", "x.y=1\nx.y z=2\n")
+
+	warnings, err := LintDir(dir, DefaultLintRules())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 2 || warnings[0].File != "application-prod.properties" || warnings[1].File != "common.properties" {
+		t.Errorf("Every file should be linted, whatever its profile: %v", warnings)
+	}
+}
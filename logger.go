@@ -0,0 +1,60 @@
+package gconfig
+
+import (
+	"log"
+	"sync"
+)
+
+// Logger receives the diagnostics gconfig writes while loading configuration.
+// *log.Logger satisfies it, and so does a thin wrapper around *slog.Logger.
+type Logger interface {
+	Printf(format string, v ...interface{})
+}
+
+var (
+	loggerMu sync.RWMutex
+	logger   Logger = stdLogger{}
+)
+
+// SetLogger replaces the package logger used when no WithLogger option is given.
+// Passing nil silences gconfig. By default messages go to the standard log package.
+func SetLogger(l Logger) {
+	if l == nil {
+		l = nopLogger{}
+	}
+	loggerMu.Lock()
+	defer loggerMu.Unlock()
+	logger = l
+}
+
+// WithLogger sets the logger used for a single configuration. Passing nil silences it.
+func WithLogger(l Logger) Option {
+	return func(o *options) {
+		if l == nil {
+			l = nopLogger{}
+		}
+		o.logger = l
+	}
+}
+
+func (o *options) logf(format string, v ...interface{}) {
+	if o != nil && o.logger != nil {
+		o.logger.Printf(format, v...)
+		return
+	}
+	loggerMu.RLock()
+	l := logger
+	loggerMu.RUnlock()
+	l.Printf(format, v...)
+}
+
+// stdLogger writes through the standard log package so it follows log.SetOutput and log.SetFlags.
+type stdLogger struct{}
+
+func (stdLogger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Printf(string, ...interface{}) {}
@@ -0,0 +1,49 @@
+package gconfig
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordingLogger struct {
+	lines []string
+}
+
+func (l *recordingLogger) Printf(format string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(format, v...))
+}
+
+func TestWithLogger(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	l := new(recordingLogger)
+	if _, err := Load(WithPath(dir), WithLogger(l)); err != nil {
+		t.Fatal(err)
+	}
+	out := strings.Join(l.lines, "")
+	if !strings.Contains(out, "Loading configuration file from path "+dir) || !strings.Contains(out, "Configuration loaded") {
+		t.Errorf("Unexpected log output: %s", out)
+	}
+}
+
+func TestSetLogger(t *testing.T) {
+	defer SetLogger(stdLogger{})
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	l := new(recordingLogger)
+	SetLogger(l)
+	if _, err := Load(WithPath(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if len(l.lines) == 0 {
+		t.Errorf("Package logger should receive the load messages")
+	}
+
+	SetLogger(nil)
+	if _, err := Load(WithPath(dir)); err != nil {
+		t.Fatal(err)
+	}
+}
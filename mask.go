@@ -0,0 +1,96 @@
+package gconfig
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	s "strings"
+)
+
+// MaskedValue replaces the value of sensitive keys in dumps and log lines.
+const MaskedValue = "******"
+
+// SensitiveTag marks a schema key as sensitive.
+const SensitiveTag = "sensitive"
+
+// DefaultSensitivePatterns are the key patterns always treated as sensitive. Patterns
+// use path.Match syntax and match case-insensitively.
+var DefaultSensitivePatterns = []string{"*password*", "*secret*", "*token*"}
+
+// IsSensitive reports whether the value of key must be masked: it matches one of the
+// sensitive patterns, or its schema rule carries the SensitiveTag.
+func (c *GConfig) IsSensitive(key string) bool {
+	key = c.prefix + key
+	if rule, ok := c.base().schema[key]; ok && rule.hasTag(SensitiveTag) {
+		return true
+	}
+
+	patterns := DefaultSensitivePatterns
+	if c.opts != nil {
+		patterns = append(patterns[:len(patterns):len(patterns)], c.opts.sensitive...)
+	}
+	lk := s.ToLower(key)
+	for _, p := range patterns {
+		if ok, _ := path.Match(s.ToLower(p), lk); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// mask returns MaskedValue in place of the value of a sensitive key.
+func (c *GConfig) mask(key, value string) string {
+	if value != "" && c.IsSensitive(key) {
+		return MaskedValue
+	}
+	return value
+}
+
+// minRedactedLen is the length under which values aren't scrubbed from log lines, as
+// replacing every occurrence of a short string would garble the message.
+const minRedactedLen = 4
+
+// redact replaces the values of the sensitive keys found in a message generated by
+// gconfig with MaskedValue. Every log line goes through it.
+func (c *GConfig) redact(msg string) string {
+	vs := c.current()
+	// resolving values may log, eg: a failed reference, which would redact again
+	q := &GConfig{Profile: c.Profile, opts: c.opts, root: c.base(), prefix: c.prefix, pinned: c.pinned, quiet: true}
+	for _, k := range c.keys() {
+		if !c.IsSensitive(k) {
+			continue
+		}
+		raw, _ := vs.get(c.prefix+k, c.Profile).(string)
+		for _, v := range []string{raw, q.expand(c.prefix+k, raw, map[string]bool{c.prefix + k: true})} {
+			if len(v) >= minRedactedLen {
+				msg = s.Replace(msg, v, MaskedValue, -1)
+			}
+		}
+	}
+	return msg
+}
+
+// redactValue replaces the quoted value v of key in msg with MaskedValue if key is sensitive.
+func (c *GConfig) redactValue(key, v, msg string) string {
+	if v == "" || !c.IsSensitive(key) {
+		return msg
+	}
+	return s.Replace(msg, strconv.Quote(v), strconv.Quote(MaskedValue), -1)
+}
+
+// redactParseError masks the text of a parse error about a sensitive key.
+func (c *GConfig) redactParseError(e *ParseError) *ParseError {
+	if e.Text != "" && e.Key != "" && c.IsSensitive(e.Key) {
+		e.Text = MaskedValue
+	}
+	return e
+}
+
+// logf formats a log line and redacts it before handing it to the logger. Quiet views,
+// see redact, don't log.
+func (c *GConfig) logf(format string, v ...interface{}) {
+	if c.quiet {
+		return
+	}
+	c.base().opts.logf("%s", c.redact(fmt.Sprintf(format, v...)))
+}
@@ -0,0 +1,137 @@
+package gconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestMasking(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `db.user=app
+db.password=hunter2
+api.Token=abc
+stripe.key=sk_live
+`)
+	gcg, err := Load(WithPath(dir), WithSensitiveKeys("stripe.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	settings := gcg.AllSettings()
+	for _, k := range []string{"db.password", "api.Token", "stripe.key"} {
+		if settings[k] != MaskedValue {
+			t.Errorf("Key %s should be masked but was %s", k, settings[k])
+		}
+	}
+	if settings["db.user"] != "app" {
+		t.Errorf("Key db.user should not be masked but was %s", settings["db.user"])
+	}
+	if gcg.GetString("db.password") != "hunter2" {
+		t.Errorf("Getters should return the real value")
+	}
+
+	var buf bytes.Buffer
+	gcg.WriteTo(&buf)
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Errorf("WriteTo should mask sensitive values:\n%s", buf.String())
+	}
+
+	if !gcg.Sub("db").IsSensitive("password") {
+		t.Errorf("Sub views should check the absolute key")
+	}
+}
+
+func TestSensitiveTag(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "customer.ssn=123-45-6789\n")
+	gcg, err := Load(WithPath(dir), WithSchema(Schema{"customer.ssn": {Tags: []string{SensitiveTag}}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.AllSettings()["customer.ssn"] != MaskedValue {
+		t.Errorf("Keys tagged %s should be masked", SensitiveTag)
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.user=app\ndb.password=hunter22\napi.token=abcdefgh\ndb.pool=10\ndb.password hunter22\n")
+
+	l := &recordingLogger{}
+	gcg, err := Load(WithPath(dir), WithLogger(l), WithSchema(Schema{
+		"db.password": {Min: Bound(12)},
+		"db.user":     {Min: Bound(5)},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := gcg.ParseWarnings()
+	if len(w) != 1 || strings.Contains(w[0].Error(), "hunter22") || w[0].Key != "db.password" {
+		t.Errorf("Malformed lines defining sensitive keys should be masked but got %v", w)
+	}
+	for _, line := range l.lines {
+		if strings.Contains(line, "hunter22") {
+			t.Errorf("Log line leaks a sensitive value: %s", line)
+		}
+	}
+
+	gcg.logf("connecting with %s and %s as %s\n", "hunter22", "abcdefgh", "app")
+	last := l.lines[len(l.lines)-1]
+	if strings.Contains(last, "hunter22") || strings.Contains(last, "abcdefgh") || !strings.Contains(last, "as app") {
+		t.Errorf("Sensitive values should be scrubbed from log lines but got %s", last)
+	}
+
+	err = gcg.Validate()
+	verr, ok := err.(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected a *ValidationError but got %v", err)
+	}
+	if strings.Contains(verr.Error(), "hunter22") {
+		t.Errorf("Validation errors leak a sensitive value: %s", verr)
+	}
+	if !strings.Contains(verr.Error(), `"app"`) {
+		t.Errorf("Values of other keys should be kept in validation errors: %s", verr)
+	}
+	for _, v := range verr.Violations {
+		if v.Key == "db.password" && v.Value != MaskedValue {
+			t.Errorf("Violation value should be masked but was %s", v.Value)
+		}
+	}
+}
+
+func TestRedactedParseError(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "api.token=\\u12zz-secret-value\n")
+	_, err := Load(WithPath(dir))
+	if err == nil || strings.Contains(err.Error(), "secret-value") || strings.Contains(err.Error(), "12zz") {
+		t.Errorf("Parse errors about sensitive keys should be masked but got %v", err)
+	}
+}
+
+func TestRedactionDoesNotRecurse(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.user=app\napi.token=${missing}\nmissing.default=gctest-noscheme://x\n")
+
+	l := &recordingLogger{}
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv(), WithLogger(l),
+		WithDefaults(map[string]string{"db.password": "x${random.foo}"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the failed function is logged while the sensitive keys are resolved for redaction
+	if v := gcg.GetString("db.password"); v != "x" {
+		t.Errorf("db.password = %q, want x", v)
+	}
+	gcg.GetString("api.token")
+	n := 0
+	for _, line := range l.lines {
+		if strings.Contains(line, "random.foo") {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Errorf("The failed function should be logged once, got %d lines: %q", n, l.lines)
+	}
+}
@@ -0,0 +1,100 @@
+// Package mobile exposes gconfig to Android and iOS apps through gomobile bind, whose
+// generated bindings only support basic types. The host app hands the configuration
+// over as file contents or paths, so nothing depends on flags or environment variables
+// a mobile process doesn't have.
+//
+//	loader := mobile.NewLoader("prod")
+//	loader.AddContent("application.properties", bundled)
+//	cfg, err := loader.Load()
+package mobile
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/narup/gconfig"
+)
+
+// Loader collects the configuration files supplied by the host app.
+type Loader struct {
+	profile string
+	opts    []gconfig.Option
+}
+
+// NewLoader creates a Loader for the given profile, empty for the default one.
+func NewLoader(profile string) *Loader {
+	return &Loader{profile: profile}
+}
+
+// AddContent adds a properties file read by the host app, eg: from the app bundle.
+func (l *Loader) AddContent(name string, content []byte) {
+	l.opts = append(l.opts, gconfig.WithContent(name, content))
+}
+
+// AddFile adds the properties file at path, eg: one downloaded into the app's files
+// directory. It's read when the Loader loads.
+func (l *Loader) AddFile(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	l.AddContent(filepath.Base(path), content)
+	return nil
+}
+
+// Load reads the added files.
+func (l *Loader) Load() (*Config, error) {
+	if len(l.opts) == 0 {
+		return nil, gconfig.ErrConfigFileRequired
+	}
+	opts := append([]gconfig.Option{
+		gconfig.WithProfile(l.profile),
+		gconfig.WithoutFlags(),
+		gconfig.WithoutEnv(),
+	}, l.opts...)
+	c, err := gconfig.Load(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Config{c: c}, nil
+}
+
+// Config is the loaded configuration.
+type Config struct {
+	c *gconfig.GConfig
+}
+
+// Profile returns the active profile.
+func (c *Config) Profile() string {
+	return c.c.Profile
+}
+
+// GetString returns string value for the given key
+func (c *Config) GetString(key string) string {
+	return c.c.GetString(key)
+}
+
+// GetInt returns int value for the given key
+func (c *Config) GetInt(key string) int {
+	return c.c.GetInt(key)
+}
+
+// GetFloat returns float value for the given key
+func (c *Config) GetFloat(key string) float64 {
+	return c.c.GetFloat(key)
+}
+
+// GetBool returns bool value for the given key
+func (c *Config) GetBool(key string) bool {
+	return c.c.GetBool(key)
+}
+
+// Exists checks if key exists
+func (c *Config) Exists(key string) bool {
+	return c.c.Exists(key)
+}
+
+// Set assigns a value to key, see GConfig.Set.
+func (c *Config) Set(key, value string) error {
+	return c.c.Set(key, value)
+}
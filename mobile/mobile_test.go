@@ -0,0 +1,48 @@
+package mobile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoader(t *testing.T) {
+	os.Setenv("GC_PROFILE", "dev")
+	os.Setenv("MOBILE_HOME", "/home/app")
+	defer os.Unsetenv("GC_PROFILE")
+	defer os.Unsetenv("MOBILE_HOME")
+
+	path := filepath.Join(t.TempDir(), "application-prod.properties")
+	if err := os.WriteFile(path, []byte("api.url=https://api.example.com\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	l := NewLoader("prod")
+	l.AddContent("application.properties", []byte("api.url=http://localhost\napi.retries=3\nhome=${MOBILE_HOME|none}\n"))
+	if err := l.AddFile(path); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := l.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Profile() != "prod" {
+		t.Errorf("Profile should be prod but was %s", cfg.Profile())
+	}
+	if u := cfg.GetString("api.url"); u != "https://api.example.com" {
+		t.Errorf("The profile file should override the bundled default but api.url was %s", u)
+	}
+	if r := cfg.GetInt("api.retries"); r != 3 {
+		t.Errorf("Key api.retries should be 3 but was %d", r)
+	}
+	if h := cfg.GetString("home"); h != "none" {
+		t.Errorf("The environment should be ignored but home was %s", h)
+	}
+}
+
+func TestLoaderWithoutFiles(t *testing.T) {
+	if _, err := NewLoader("").Load(); err == nil {
+		t.Error("Loading without any file should fail")
+	}
+}
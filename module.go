@@ -0,0 +1,225 @@
+package gconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	s "strings"
+	"sync"
+)
+
+// Module describes the configuration an application module needs. Modules register
+// themselves from their init functions; Load then applies their defaults, validates
+// their keys and rejects modules claiming overlapping prefixes.
+type Module struct {
+	// Name identifies the module in errors and documentation.
+	Name string
+	// Prefix is the key prefix owned by the module, eg: "cache" for cache.size.
+	Prefix string
+	// Description is a short summary used in the generated documentation.
+	Description string
+	// Schema holds the rules of the module keys, relative to Prefix.
+	Schema Schema
+	// Defaults holds the values used when no properties file defines the key, relative to Prefix.
+	Defaults map[string]string
+}
+
+var (
+	modulesMu sync.Mutex
+	modules   []Module
+	// libraryDefaults holds the defaults registered with RegisterDefaults, by key
+	libraryDefaults = make(map[string]string)
+)
+
+// Register adds a module to the set applied by every Load.
+func Register(m Module) {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	modules = append(modules, m)
+}
+
+// RegisterDefaults registers default values for the keys under prefix, relative to it, eg:
+// RegisterDefaults("cache", map[string]string{"size": "128"}) defaults cache.size. It lets
+// a library ship sane defaults from its init function that the properties files, and every
+// other layer, override. Defaults are merged key by key, the last registered winning, and
+// don't claim their prefix as a Module does, so libraries may register defaults under
+// nested prefixes, eg: http and http.client, or under the prefix of a module, whose own
+// defaults they override. It panics if prefix is empty.
+func RegisterDefaults(prefix string, defaults map[string]string) {
+	p := s.Trim(prefix, ".")
+	if p == "" {
+		panic("gconfig: RegisterDefaults called with an empty prefix")
+	}
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	for k, v := range defaults {
+		libraryDefaults[p+"."+k] = v
+	}
+}
+
+// registeredDefaults returns a copy of the defaults registered with RegisterDefaults.
+func registeredDefaults() map[string]string {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	defs := make(map[string]string, len(libraryDefaults))
+	for k, v := range libraryDefaults {
+		defs[k] = v
+	}
+	return defs
+}
+
+// registeredModules returns the registered modules sorted by prefix.
+func registeredModules() []Module {
+	modulesMu.Lock()
+	defer modulesMu.Unlock()
+	mods := make([]Module, len(modules))
+	copy(mods, modules)
+	sort.SliceStable(mods, func(i, j int) bool { return mods[i].Prefix < mods[j].Prefix })
+	return mods
+}
+
+// key returns the fully qualified name of a key relative to the module prefix.
+func (m Module) key(k string) string {
+	return s.TrimSuffix(m.Prefix, ".") + "." + k
+}
+
+// overlaps reports whether the prefixes of m and o are equal or one is nested in the other.
+func (m Module) overlaps(o Module) bool {
+	a, b := s.TrimSuffix(m.Prefix, ".")+".", s.TrimSuffix(o.Prefix, ".")+"."
+	return s.HasPrefix(a, b) || s.HasPrefix(b, a)
+}
+
+// applyModules checks the registered modules for prefix collisions, installs their
+// defaults and validates their keys against their schemas.
+func (c *GConfig) applyModules() error {
+	mods, defs := registeredModules(), registeredDefaults()
+	if len(mods) == 0 && len(defs) == 0 {
+		return nil
+	}
+
+	for i, m := range mods {
+		for _, o := range mods[i+1:] {
+			if m.overlaps(o) {
+				return fmt.Errorf("Configuration modules %s and %s claim overlapping prefixes %s and %s", m.Name, o.Name, m.Prefix, o.Prefix)
+			}
+		}
+	}
+
+	//defaults passed to Load take precedence over the registered ones, and the ones of
+	//RegisterDefaults over the module ones
+	for k, v := range defs {
+		if _, ok := c.v.defaults[k]; !ok {
+			c.v.defaults[k] = v
+		}
+	}
+	schema := make(Schema)
+	for _, m := range mods {
+		for k, v := range m.Defaults {
+			if _, ok := c.v.defaults[m.key(k)]; !ok {
+				c.v.defaults[m.key(k)] = v
+			}
+		}
+		for k, rule := range m.Schema {
+			schema[m.key(k)] = rule
+		}
+	}
+
+	merged := make(Schema, len(schema)+len(c.schema))
+	for k, rule := range schema {
+		merged[k] = rule
+	}
+	for k, rule := range c.schema {
+		merged[k] = rule
+	}
+	c.schema = merged
+
+	return c.validateSchema(schema)
+}
+
+// WriteModuleDocs writes markdown documentation of every registered module: its
+// prefix, description and the keys it reads with their type, default and constraints,
+// followed by the defaults registered outside of any module.
+func WriteModuleDocs(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	defs := registeredDefaults()
+	mods := registeredModules()
+	for _, m := range mods {
+		fmt.Fprintf(bw, "## %s\n\n", m.Name)
+		if m.Description != "" {
+			fmt.Fprintf(bw, "%s\n\n", m.Description)
+		}
+		fmt.Fprintf(bw, "Prefix: `%s`\n\n", m.Prefix)
+
+		keys := make(map[string]bool)
+		for k := range m.Schema {
+			keys[k] = true
+		}
+		for k := range m.Defaults {
+			keys[k] = true
+		}
+		prefix := s.TrimSuffix(m.Prefix, ".") + "."
+		for k := range defs {
+			if s.HasPrefix(k, prefix) {
+				keys[k[len(prefix):]] = true
+			}
+		}
+		names := make([]string, 0, len(keys))
+		for k := range keys {
+			names = append(names, k)
+		}
+		sort.Strings(names)
+
+		fmt.Fprintln(bw, "| Key | Type | Required | Default | Constraints |")
+		fmt.Fprintln(bw, "|-----|------|----------|---------|-------------|")
+		for _, k := range names {
+			rule := m.Schema[k]
+			def, ok := defs[m.key(k)]
+			if !ok {
+				def = m.Defaults[k]
+			}
+			fmt.Fprintf(bw, "| `%s` | %s | %t | %s | %s |\n", m.key(k), rule.Type, rule.Required, def, rule.describe())
+		}
+		fmt.Fprintln(bw)
+	}
+
+	var other []string
+	for k := range defs {
+		owned := false
+		for _, m := range mods {
+			owned = owned || s.HasPrefix(k, s.TrimSuffix(m.Prefix, ".")+".")
+		}
+		if !owned {
+			other = append(other, k)
+		}
+	}
+	if len(other) > 0 {
+		sort.Strings(other)
+		fmt.Fprintf(bw, "## Library defaults\n\n")
+		fmt.Fprintln(bw, "| Key | Default |")
+		fmt.Fprintln(bw, "|-----|---------|")
+		for _, k := range other {
+			fmt.Fprintf(bw, "| `%s` | %s |\n", k, defs[k])
+		}
+		fmt.Fprintln(bw)
+	}
+	return bw.Flush()
+}
+
+// describe summarizes the constraints of a rule for documentation.
+func (r Rule) describe() string {
+	var parts []string
+	if r.Min != nil {
+		parts = append(parts, fmt.Sprintf("min %v", *r.Min))
+	}
+	if r.Max != nil {
+		parts = append(parts, fmt.Sprintf("max %v", *r.Max))
+	}
+	if len(r.Enum) > 0 {
+		parts = append(parts, "one of "+s.Join(r.Enum, ", "))
+	}
+	if r.Pattern != "" {
+		parts = append(parts, "matches `"+r.Pattern+"`")
+	}
+	return s.Join(parts, "; ")
+}
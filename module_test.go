@@ -0,0 +1,129 @@
+package gconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func withModules(t *testing.T, mods ...Module) {
+	modulesMu.Lock()
+	saved, savedDefaults := modules, libraryDefaults
+	modules, libraryDefaults = nil, make(map[string]string)
+	modulesMu.Unlock()
+	t.Cleanup(func() {
+		modulesMu.Lock()
+		modules, libraryDefaults = saved, savedDefaults
+		modulesMu.Unlock()
+	})
+
+	for _, m := range mods {
+		Register(m)
+	}
+}
+
+func TestModules(t *testing.T) {
+	withModules(t, Module{
+		Name:     "cache",
+		Prefix:   "cache",
+		Schema:   Schema{"size": {Type: TypeInt, Min: Bound(1)}},
+		Defaults: map[string]string{"size": "128", "ttl": "1m"},
+	})
+
+	gcg := setup("dev", t)
+	if v := gcg.GetInt("cache.size"); v != 128 {
+		t.Errorf("Module default for cache.size should be used but got %d", v)
+	}
+	if v := gcg.GetString("app.name"); v != "gconfig dev profile" {
+		t.Errorf("Files should still be loaded but app.name was %s", v)
+	}
+}
+
+func TestModuleValidation(t *testing.T) {
+	withModules(t, Module{
+		Name:     "cache",
+		Prefix:   "cache",
+		Schema:   Schema{"size": {Type: TypeInt, Min: Bound(1)}},
+		Defaults: map[string]string{"size": "0"},
+	})
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+	if _, err := Load(WithPath(dir)); err == nil {
+		t.Errorf("Load should fail when a module key is invalid")
+	}
+}
+
+func TestModulePrefixCollision(t *testing.T) {
+	withModules(t, Module{Name: "db", Prefix: "db"}, Module{Name: "replica", Prefix: "db.replica"})
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+	if _, err := Load(WithPath(dir)); err == nil || !strings.Contains(err.Error(), "overlapping prefixes") {
+		t.Errorf("Load should reject overlapping module prefixes but got %v", err)
+	}
+}
+
+func TestWriteModuleDocs(t *testing.T) {
+	withModules(t, Module{
+		Name:        "cache",
+		Prefix:      "cache",
+		Description: "In-memory cache settings.",
+		Schema:      Schema{"size": {Type: TypeInt, Required: true, Min: Bound(1)}},
+		Defaults:    map[string]string{"size": "128"},
+	})
+
+	var buf bytes.Buffer
+	if err := WriteModuleDocs(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "| `cache.size` | int | true | 128 | min 1 |") {
+		t.Errorf("Unexpected module docs:\n%s", buf.String())
+	}
+}
+
+func TestRegisterDefaults(t *testing.T) {
+	withModules(t, Module{Name: "cache", Prefix: "cache", Schema: Schema{"size": {Type: TypeInt, Min: Bound(1)}}, Defaults: map[string]string{"ttl": "30s"}})
+	RegisterDefaults("cache", map[string]string{"size": "128", "ttl": "1m"})
+	RegisterDefaults("http.client", map[string]string{"timeout": "5s"})
+	// nested prefixes don't collide
+	RegisterDefaults("http", map[string]string{"port": "80"})
+	RegisterDefaults("cache.redis", map[string]string{"db": "0"})
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ncache.ttl=5m\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv(), WithDefaults(map[string]string{"http.client.timeout": "10s"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := gcg.GetInt("cache.size"); v != 128 {
+		t.Errorf("Registered default for cache.size should be used but got %d", v)
+	}
+	if v := gcg.GetString("cache.ttl"); v != "5m" {
+		t.Errorf("Files should win over registered defaults but cache.ttl was %s", v)
+	}
+	if v := gcg.GetString("http.client.timeout"); v != "10s" {
+		t.Errorf("Defaults passed to Load should win over registered ones but got %s", v)
+	}
+	if v := gcg.GetString("http.port") + " " + gcg.GetString("cache.redis.db"); v != "80 0" {
+		t.Errorf("Defaults under nested prefixes should be used but got %s", v)
+	}
+	if mods := registeredModules(); len(mods) != 1 {
+		t.Errorf("Defaults shouldn't register modules but got %+v", mods)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteModuleDocs(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if doc := buf.String(); !strings.Contains(doc, "| `cache.ttl` | string | false | 1m |") || !strings.Contains(doc, "| `http.port` | 80 |") {
+		t.Errorf("Unexpected docs:\n%s", doc)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("An empty prefix should panic")
+		}
+	}()
+	RegisterDefaults(".", map[string]string{"key": "v"})
+}
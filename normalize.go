@@ -0,0 +1,40 @@
+package gconfig
+
+import s "strings"
+
+// NormalizeKeys makes key lookups ignore case and treat '-', '_' and '.' as the same
+// separator, so APP_DB_URL, app.db.url and app.db-url all read the same entry. Within
+// each layer an exact match wins over a normalized one, so layer precedence is kept.
+// Keys and AllSettings still list the keys as they are defined.
+func NormalizeKeys() Option {
+	return func(o *options) {
+		o.normalizeKeys = true
+	}
+}
+
+// normalizeKey returns the normalized form of key, lowercase and dot separated.
+func normalizeKey(key string) string {
+	return s.Map(func(r rune) rune {
+		if r == '-' || r == '_' {
+			return '.'
+		}
+		return r
+	}, s.ToLower(key))
+}
+
+// findKey returns the key of m matching key exactly or, if normalize is set, once
+// normalized. When several keys normalize alike the smallest wins, to be deterministic.
+func findKey[V any](m map[string]V, key string, normalize bool) (string, bool) {
+	if _, ok := m[key]; ok || !normalize {
+		return key, ok
+	}
+
+	n := normalizeKey(key)
+	found, ok := "", false
+	for k := range m {
+		if normalizeKey(k) == n && (!ok || k < found) {
+			found, ok = k, true
+		}
+	}
+	return found, ok
+}
@@ -0,0 +1,41 @@
+package gconfig
+
+import "testing"
+
+func TestNormalizeKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.db-url=postgres://db\napp.name=gconfig\n")
+	writeConfig(t, dir, "application-prod.properties", "APP_NAME=prod\n")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), NormalizeKeys())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"app.db-url", "app.db.url", "APP_DB_URL", "App.Db_Url"} {
+		if v := gcg.GetString(k); v != "postgres://db" {
+			t.Errorf("%s should resolve to app.db-url but was %q", k, v)
+		}
+	}
+	if v := gcg.GetString("app.name"); v != "prod" {
+		t.Errorf("A normalized match in the profile should override the default file but was %q", v)
+	}
+	if o, _ := gcg.Origin("app.name"); o.Layer != LayerProfileFile || o.Line != 1 {
+		t.Errorf("Origin should follow the normalized key but was %v", o)
+	}
+	if v := gcg.Sub("APP").GetString("DB_URL"); v != "postgres://db" {
+		t.Errorf("Sub views should normalize too but got %q", v)
+	}
+
+	gcg.Set("APP_DB_URL", "postgres://override")
+	if v := gcg.GetString("app.db-url"); v != "postgres://override" {
+		t.Errorf("Set should override the file whatever the key spelling but got %q", v)
+	}
+
+	gcg, err = Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gcg.Exists("APP_DB_URL") {
+		t.Error("Keys shouldn't be normalized by default")
+	}
+}
@@ -0,0 +1,260 @@
+package gconfig
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"io/fs"
+	"time"
+)
+
+// Option customizes how Load locates and reads the configuration. Options take
+// precedence over the command line flags and environment variables.
+type Option func(*options)
+
+// options holds the settings collected from the Option values passed to Load.
+type options struct {
+	path     string
+	profile  string
+	strict   bool
+	required []string
+	schema   Schema
+
+	maxStaleness map[string]time.Duration
+	failOnStale  bool
+
+	sensitive []string
+	logger    Logger
+
+	profileFragments bool
+	searchPaths      []string
+	appName          string
+
+	envPrefix     string
+	envOnly       bool
+	defaults      map[string]string
+	normalizeKeys bool
+
+	flagSets     []*flag.FlagSet
+	flagBindings []flagBinding
+
+	sources        []Source
+	sourceURLs     []string
+	sourceTimeouts []sourceTimeout
+
+	parseStrict bool
+	lint        *LintRules
+	latin1      bool
+
+	bundle          string
+	verify          bool
+	verificationKey ed25519.PublicKey
+	decryptionKey   []byte
+
+	fsys    fs.FS
+	noFlags bool
+	noEnv   bool
+
+	dotEnv, exportDotEnv bool
+
+	audit    *AuditLog
+	checksum string
+	fallback bool
+	chaos    *chaos
+	access   *accessTracker
+}
+
+// WithPath sets the directory the properties files are read from, overriding
+// the 'path' flag and the 'GC_PATH' environment variable. It may list several
+// directories, separated by os.PathListSeparator, eg: /app/config:/etc/overrides, which
+// are merged in order, the files of later directories winning over the files of the same
+// layer in earlier ones; dotenv files are read from the first. The path may also be a
+// pattern, eg: conf.d/*.properties, or conf.d/**/*.properties to descend into sub
+// directories. Every matching file is then read in the order of its path, the later
+// ones winning, with application-{profile}.properties files applying to their profile
+// only, over the others.
+func WithPath(path string) Option {
+	return func(o *options) {
+		o.path = path
+	}
+}
+
+// WithProfile sets the active profile, overriding the 'profile' flag and the
+// 'GC_PROFILE' environment variable.
+func WithProfile(profile string) Option {
+	return func(o *options) {
+		o.profile = profile
+	}
+}
+
+// WithSearchPaths sets the directories searched, in order, for the properties files when
+// no path is set with WithPath, the 'path' flag or GC_PATH. The first directory holding a
+// properties file is used. It replaces the default search paths: ./config, the working
+// directory, the user configuration directory of the application, eg:
+// $XDG_CONFIG_HOME/myserver, then /etc/myserver.
+func WithSearchPaths(dirs ...string) Option {
+	return func(o *options) {
+		o.searchPaths = append([]string{}, dirs...)
+	}
+}
+
+// ProfileFragments reads a profile split by concern across several files: after
+// application-{profile}.properties, every application-{profile}-*.properties file is merged
+// in alphabetical order, eg: application-prod-db.properties then
+// application-prod-kafka.properties, the later files winning. Fragments share their naming
+// with hierarchical profiles, so with this option a profile is no longer split on '-':
+// profile prod-us reads application-prod-us.properties and its fragments only.
+func ProfileFragments() Option {
+	return func(o *options) {
+		o.profileFragments = true
+	}
+}
+
+// StrictMode makes Load fail fast with a *MissingKeysError when any of the given
+// keys is not defined by the loaded configuration, instead of silently handing out
+// zero values later on. A schema registered with WithSchema is validated as well.
+func StrictMode(required ...string) Option {
+	return func(o *options) {
+		o.strict = true
+		o.required = append(o.required, required...)
+	}
+}
+
+// WithSchema registers the schema checked by GConfig.Validate.
+func WithSchema(schema Schema) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// MaxStaleness declares the freshness SLO of a source: its values must have been
+// (re)loaded within d. The source is named by its file name, eg: application-prod.properties,
+// or "*" for every source. Violations are reported by GConfig.Health.
+func MaxStaleness(source string, d time.Duration) Option {
+	return func(o *options) {
+		if o.maxStaleness == nil {
+			o.maxStaleness = make(map[string]time.Duration)
+		}
+		o.maxStaleness[source] = d
+	}
+}
+
+// FailOnStale makes Reload return a *StaleError when it fails to refresh a source
+// that has outlived its MaxStaleness, instead of quietly serving the expired values.
+func FailOnStale() Option {
+	return func(o *options) {
+		o.failOnStale = true
+	}
+}
+
+// WithSensitiveKeys adds exact keys, or key patterns in path.Match syntax, whose values
+// are masked in AllSettings, WriteTo and log lines, on top of DefaultSensitivePatterns.
+func WithSensitiveKeys(patterns ...string) Option {
+	return func(o *options) {
+		o.sensitive = append(o.sensitive, patterns...)
+	}
+}
+
+// WithEnvPrefix overlays the environment variables starting with prefix followed by an
+// underscore on top of the properties files, see EnvOnly for how names map to keys.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+	}
+}
+
+// EnvOnly builds the whole configuration from the environment variables starting with
+// prefix followed by an underscore, plus the WithDefaults and module defaults, without
+// reading any file. It suits serverless deployments where shipping a config directory is
+// impractical. The prefix is stripped and the rest of the name is lowercased with single
+// underscores turned into dots and double underscores into one underscore, so
+// MYAPP_DB_URL is db.url and MYAPP_DB_MAX__CONNS is db.max_conns.
+func EnvOnly(prefix string) Option {
+	return func(o *options) {
+		o.envPrefix = prefix
+		o.envOnly = true
+	}
+}
+
+// WithDefaults sets values used when neither the files nor the environment define the key.
+func WithDefaults(defaults map[string]string) Option {
+	return func(o *options) {
+		if o.defaults == nil {
+			o.defaults = make(map[string]string, len(defaults))
+		}
+		for k, v := range defaults {
+			o.defaults[k] = v
+		}
+	}
+}
+
+// ParseStrict makes Load fail with a *ParseError naming the file and line of the first
+// line that is neither blank, a comment nor a key/value pair, eg: "server.port 8080".
+// By default such lines are skipped, logged and reported by GConfig.ParseWarnings.
+func ParseStrict() Option {
+	return func(o *options) {
+		o.parseStrict = true
+	}
+}
+
+// Latin1Encoding reads the properties files as ISO-8859-1, the encoding of the files
+// written by Java tooling, instead of UTF-8, so accented characters aren't garbled. Files
+// starting with a UTF-8 byte order mark are still read as UTF-8, and \uXXXX escapes are
+// decoded either way.
+func Latin1Encoding() Option {
+	return func(o *options) {
+		o.latin1 = true
+	}
+}
+
+// WithFS reads the properties files from fsys instead of the OS file system, eg: an
+// embed.FS or the preopened directories of a WASM runtime. The path set with WithPath
+// is then a slash separated path within fsys, "." by default, and the search paths and
+// working directory fallbacks are skipped.
+func WithFS(fsys fs.FS) Option {
+	return func(o *options) {
+		o.fsys = fsys
+	}
+}
+
+// WithoutFlags stops Load from parsing the command line and ignores the 'path' and
+// 'profile' flags, for hosts where os.Args isn't the application's command line such
+// as WASM modules, plugins or tests. The GC_PATH and GC_PROFILE environment variables
+// are still honoured.
+func WithoutFlags() Option {
+	return func(o *options) {
+		o.noFlags = true
+	}
+}
+
+// WithContent adds an in-memory properties file, eg: WithContent("application.properties", data).
+// Once content is added the configuration is read only from the files added this way,
+// which suits hosts that hand the configuration over as bytes, like mobile apps reading
+// it from their bundle. Imports are resolved among the added files.
+func WithContent(name string, content []byte) Option {
+	return func(o *options) {
+		m, ok := o.fsys.(memFS)
+		if !ok {
+			m = make(memFS)
+			o.fsys = m
+		}
+		m[name] = content
+	}
+}
+
+// WithoutEnv ignores the process environment: GC_PROFILE and GC_PATH aren't read and
+// ${NAME} placeholders and the env function of expressions only see configuration keys,
+// and WithEnvPrefix has no effect.
+// Sandboxed hosts, such as mobile apps, may not have a meaningful environment.
+func WithoutEnv() Option {
+	return func(o *options) {
+		o.noEnv = true
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := new(options)
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
@@ -0,0 +1,129 @@
+package gconfig
+
+import "fmt"
+
+// Layer is one of the layers the effective configuration is merged from, listed from
+// the highest precedence to the lowest.
+type Layer int
+
+const (
+	// LayerSet holds the values assigned with Set
+	LayerSet Layer = iota
+	// LayerFlag holds the values of the command line flags, see WithFlags
+	LayerFlag
+	// LayerEnv holds the prefixed environment variables, see WithEnvPrefix
+	LayerEnv
+	// LayerSource holds the values of the sources added with WithSource
+	LayerSource
+	// LayerDotEnv holds the values of dotenv files, see DotEnv
+	LayerDotEnv
+	// LayerProfileFile holds the values of application-{profile}.properties
+	LayerProfileFile
+	// LayerDefaultFile holds the values of application.properties
+	LayerDefaultFile
+	// LayerDefaults holds the values registered with WithDefaults and by modules
+	LayerDefaults
+)
+
+func (l Layer) String() string {
+	switch l {
+	case LayerSet:
+		return "set"
+	case LayerFlag:
+		return "flag"
+	case LayerEnv:
+		return "env"
+	case LayerSource:
+		return "source"
+	case LayerDotEnv:
+		return "dotenv"
+	case LayerProfileFile:
+		return "profile file"
+	case LayerDefaultFile:
+		return "default file"
+	}
+	return "defaults"
+}
+
+// MarshalText encodes the layer as its name, eg: in the JSON of an Explanation.
+func (l Layer) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// Origin tells where the effective value of a key comes from.
+type Origin struct {
+	Layer Layer
+	// Source names the file, or imported file, or the source the value was read from.
+	Source string
+	// Line is the line of the properties file defining the key, 0 for other layers.
+	Line int
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Line > 0:
+		return fmt.Sprintf("%s %s:%d", o.Layer, o.Source, o.Line)
+	case o.Source != "":
+		return fmt.Sprintf("%s %s", o.Layer, o.Source)
+	}
+	return o.Layer.String()
+}
+
+// position is where a key is defined in a properties file.
+type position struct {
+	file string
+	line int
+}
+
+// Origin returns where the effective value of key comes from, eg: which file and line,
+// or false if the key isn't defined. It answers why an override doesn't take effect.
+func (c *GConfig) Origin(key string) (Origin, bool) {
+	return c.current().origin(c.prefix+key, c.Profile)
+}
+
+// origin returns the origin of the definition get uses.
+func (vs *values) origin(key, profile string) (Origin, bool) {
+	defs := vs.definitions(key, profile)
+	if len(defs) == 0 {
+		return Origin{}, false
+	}
+	return defs[0].Origin, true
+}
+
+// definitions returns the raw value of key in every layer defining it, in the order of
+// precedence of get.
+func (vs *values) definitions(key, profile string) []Definition {
+	var defs []Definition
+	names := lookupNames(key)
+	if k, ok := findAlias(vs.overrides, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerSet}, k, vs.overrides[k]})
+	}
+	if k, ok := findAlias(vs.flags, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerFlag}, k, vs.flags[k]})
+	}
+	if k, ok := findAlias(vs.env, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerEnv}, k, vs.env[k]})
+	}
+	if k, ok := findAlias(vs.sourced, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerSource, Source: vs.sourcedFrom[k]}, k, vs.sourced[k]})
+	}
+	if k, ok := findAlias(vs.dotEnv, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerDotEnv, Source: vs.dotEnvFrom[k]}, k, vs.dotEnv[k]})
+	}
+	if vs.profileConfig.fileInfo != nil {
+		if k, ok := findAlias(vs.profileConfig.configs, names, vs.normalize); ok {
+			p := vs.profileConfig.positions[k]
+			v, _ := vs.profileConfig.configs[k].(string)
+			defs = append(defs, Definition{Origin{Layer: LayerProfileFile, Source: p.file, Line: p.line}, k, v})
+		}
+	}
+	if k, ok := findAlias(vs.defaultConfig.configs, names, vs.normalize); ok {
+		p := vs.defaultConfig.positions[k]
+		v, _ := vs.defaultConfig.configs[k].(string)
+		defs = append(defs, Definition{Origin{Layer: LayerDefaultFile, Source: p.file, Line: p.line}, k, v})
+	}
+	if k, ok := findAlias(vs.defaults, names, vs.normalize); ok {
+		defs = append(defs, Definition{Origin{Layer: LayerDefaults}, k, vs.defaults[k]})
+	}
+	return defs
+}
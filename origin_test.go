@@ -0,0 +1,46 @@
+package gconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOrigin(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "# defaults\napp.name=gconfig\napp.port=8080\ngconfig.import=common.properties\n")
+	writeConfig(t, dir, "common.properties", "app.region=eu\n")
+	writeConfig(t, dir, "application-prod.properties", "\napp.port=443\n")
+	writeConfig(t, dir, ".env", "GCTEST_APP_COLOR=blue\n")
+	os.Setenv("GCTEST_APP_ENV", "prod")
+	defer os.Unsetenv("GCTEST_APP_ENV")
+
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithEnvPrefix("GCTEST"), DotEnv(),
+		WithSource(&mapSource{name: "remote", values: map[string]string{"app.flag": "on"}}),
+		WithDefaults(map[string]string{"app.timeout": "5s"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg.Set("app.name", "tuned")
+
+	expected := map[string]string{
+		"app.name":    "set",
+		"app.port":    "profile file application-prod.properties:2",
+		"app.region":  "default file common.properties:1",
+		"app.color":   "dotenv .env",
+		"app.env":     "env",
+		"app.flag":    "source remote",
+		"app.timeout": "defaults",
+	}
+	for k, want := range expected {
+		o, ok := gcg.Origin(k)
+		if !ok || o.String() != want {
+			t.Errorf("Origin of %s should be %q but was %q", k, want, o)
+		}
+	}
+	if _, ok := gcg.Origin("missing"); ok {
+		t.Error("Undefined keys have no origin")
+	}
+	if o, _ := gcg.Sub("app").Origin("port"); o.Line != 2 {
+		t.Errorf("Sub views should report the origin of the prefixed key but got %v", o)
+	}
+}
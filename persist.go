@@ -0,0 +1,187 @@
+package gconfig
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// ErrConflict is matched, with errors.Is, by the *ConflictError returned when a write
+// is based on a value that has changed since: someone else wrote the key in the
+// meantime. Reload and retry to apply the change over theirs.
+var ErrConflict = errors.New("gconfig: the key changed since it was read")
+
+// ConflictError reports a write rejected because the key no longer holds the value it
+// was based on. It carries both values so the conflict can be shown to the operator.
+type ConflictError struct {
+	Key string
+	// Value is the value the write tried to set, Current the value the key holds.
+	Value, Current string
+	// Version is the version the write expected and CurrentVersion the key's version.
+	Version, CurrentVersion string
+	// Source names the source that rejected the write, empty for an in-memory Set.
+	Source string
+}
+
+func (e *ConflictError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("gconfig: %s changed in %s, expected version %q but found %q", e.Key, e.Source, e.Version, e.CurrentVersion)
+	}
+	return fmt.Sprintf("gconfig: %s changed, expected version %q but found %q", e.Key, e.Version, e.CurrentVersion)
+}
+
+// Is makes errors.Is(err, ErrConflict) true for every *ConflictError.
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// Version returns a token identifying the current value of key, or an empty string if
+// the key isn't defined. Tokens are derived from the raw value, so every replica
+// serving the same value returns the same token; pass it back with IfVersion to make a
+// read-modify-write safe against concurrent changes.
+func (c *GConfig) Version(key string) string {
+	raw, ok := c.getValue(key).(string)
+	return version(raw, ok)
+}
+
+// version returns the token of a raw value.
+func version(raw string, defined bool) string {
+	if !defined {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:8])
+}
+
+// ErrNotWritable is returned by Set with Persist when no WritableSource is configured.
+var ErrNotWritable = errors.New("gconfig: no writable source is configured")
+
+// WritableSource is a Source that can store changes made with Set and Persist, eg: a
+// key/value store or a SQL table.
+type WritableSource interface {
+	Source
+	// CompareAndSwap stores value under key if the source still holds expected, the
+	// value it last returned from Load, or no value when expected is empty. It returns
+	// ErrConflict, or an error matching it such as a *ConflictError, when the stored
+	// value differs.
+	CompareAndSwap(ctx context.Context, key, expected, value string) error
+}
+
+// SetOption customizes a single Set.
+type SetOption func(*setOptions)
+
+type setOptions struct {
+	persist bool
+	// version is the version the key must be at, if checkVersion is set
+	version      string
+	checkVersion bool
+}
+
+// IfVersion makes Set fail with a *ConflictError unless key is still at version, as
+// returned by Version; an empty version requires the key to be undefined. Two operators
+// editing the same key through different replicas can't overwrite each other's change.
+func IfVersion(version string) SetOption {
+	return func(so *setOptions) {
+		so.version, so.checkVersion = version, true
+	}
+}
+
+// check returns a *ConflictError if key isn't at the version required by so in vs.
+func (so *setOptions) check(vs *values, key, value string) error {
+	if !so.checkVersion {
+		return nil
+	}
+	raw, ok := vs.get(key, "").(string)
+	if cur := version(raw, ok); cur != so.version {
+		return &ConflictError{Key: key, Value: value, Current: raw, Version: so.version, CurrentVersion: cur}
+	}
+	return nil
+}
+
+// Persist makes Set write the value to the writable source defining the key, or to the
+// last writable source added when none does. The write fails with ErrConflict if the
+// key changed in the source since it was last loaded, with a *ConflictError when the
+// source reports the value it holds, so two replicas can't silently
+// overwrite each other's changes. On success the new value is served as the source's,
+// replacing any override made by a plain Set.
+func Persist() SetOption {
+	return func(so *setOptions) {
+		so.persist = true
+	}
+}
+
+// persist writes key to the writable source serving it and publishes the new value. It
+// must be called on the base configuration.
+func (c *GConfig) persist(key, value string, so *setOptions) error {
+	var srcs []Source
+	if c.opts != nil {
+		srcs = c.opts.sources
+	}
+	old := c.current()
+	if old.frozen {
+		return ErrFrozen
+	}
+	if err := so.check(old, key, value); err != nil {
+		return err
+	}
+
+	// a value written to a source is only visible if no later source defines the key
+	var target WritableSource
+	for _, src := range srcs {
+		ws, writable := src.(WritableSource)
+		switch {
+		case src.Name() == old.sourcedFrom[key]:
+			target = ws
+		case writable:
+			target = ws
+		}
+	}
+	if target == nil && old.sourcedFrom[key] != "" {
+		return fmt.Errorf("Error persisting %s: it's defined by %s, which isn't writable", key, old.sourcedFrom[key])
+	}
+	if target == nil {
+		return ErrNotWritable
+	}
+
+	expected := ""
+	if old.sourcedFrom[key] == target.Name() {
+		expected = old.sourced[key]
+	}
+	ctx := context.Background()
+	if err := target.CompareAndSwap(ctx, key, expected, value); err != nil {
+		if !errors.Is(err, ErrConflict) {
+			return errors.Wrapf(err, "Error persisting %s to %s", key, target.Name())
+		}
+		cerr, ok := err.(*ConflictError)
+		if !ok {
+			cerr = &ConflictError{}
+			if kv, lerr := target.Load(ctx); lerr == nil {
+				cur, ok := kv[key]
+				cerr.Current, cerr.CurrentVersion = cur, version(cur, ok)
+			}
+		}
+		cerr.Key, cerr.Value, cerr.Source = key, value, target.Name()
+		cerr.Version = version(expected, expected != "")
+		return cerr
+	}
+
+	c.mu.Lock()
+	old = c.v
+	nv := old.clone()
+	delete(nv.overrides, key)
+	nv.sourced = make(map[string]string, len(old.sourced)+1)
+	nv.sourcedFrom = make(map[string]string, len(old.sourcedFrom)+1)
+	for k, v := range old.sourced {
+		nv.sourced[k], nv.sourcedFrom[k] = v, old.sourcedFrom[k]
+	}
+	nv.sourced[key], nv.sourcedFrom[key] = value, target.Name()
+	c.v = nv.seal()
+	c.mu.Unlock()
+
+	c.changed(AuditSet, old, nv)
+	c.logf("Persisted %s to %s\n", key, target.Name())
+	return nil
+}
@@ -0,0 +1,171 @@
+package gconfig
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+// kvStore is a WritableSource keeping its values in memory, like a remote store would.
+type kvStore struct {
+	name   string
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (kv *kvStore) Name() string { return kv.name }
+
+func (kv *kvStore) Load(ctx context.Context) (map[string]string, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	values := make(map[string]string, len(kv.values))
+	for k, v := range kv.values {
+		values[k] = v
+	}
+	return values, nil
+}
+
+func (kv *kvStore) CompareAndSwap(ctx context.Context, key, expected, value string) error {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if kv.values[key] != expected {
+		return ErrConflict
+	}
+	kv.values[key] = value
+	return nil
+}
+
+func TestSetPersist(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\nratelimit.rps=10\n")
+	store := &kvStore{name: "kv", values: map[string]string{"ratelimit.rps": "20"}}
+
+	gcg, err := Load(WithPath(dir), WithSource(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var events []Event
+	gcg.Subscribe("ratelimit", func(e Event) { events = append(events, e) })
+
+	gcg.Set("ratelimit.rps", "1")
+	if err := gcg.Set("ratelimit.rps", "30", Persist()); err != nil {
+		t.Fatal(err)
+	}
+	if v := store.values["ratelimit.rps"]; v != "30" {
+		t.Errorf("The value should be written to the source but it holds %s", v)
+	}
+	if v := gcg.GetInt("ratelimit.rps"); v != 30 {
+		t.Errorf("A persisted value should replace the override but ratelimit.rps was %d", v)
+	}
+	if o, _ := gcg.Origin("ratelimit.rps"); o.Layer != LayerSource || o.Source != "kv" {
+		t.Errorf("A persisted value should be served by its source but came from %v", o)
+	}
+	if len(events) != 2 || events[1].New != "30" {
+		t.Errorf("Subscribers should be notified of persisted values: %v", events)
+	}
+
+	if err := gcg.Sub("app").Set("owner", "ops", Persist()); err != nil {
+		t.Fatal(err)
+	}
+	if v := store.values["app.owner"]; v != "ops" {
+		t.Errorf("Keys new to the source should be created but app.owner was %q", v)
+	}
+
+	// another replica changes the key after we loaded it
+	store.values["ratelimit.rps"] = "40"
+	err = gcg.Set("ratelimit.rps", "50", Persist())
+	if !errors.Is(err, ErrConflict) {
+		t.Errorf("Writing over a concurrent change should fail with ErrConflict but got %v", err)
+	}
+	if v := store.values["ratelimit.rps"]; v != "40" {
+		t.Errorf("A conflicting write shouldn't clobber the other change but the source holds %s", v)
+	}
+	cerr, ok := err.(*ConflictError)
+	if !ok || cerr.Key != "ratelimit.rps" || cerr.Value != "50" || cerr.Current != "40" || cerr.Source != "kv" {
+		t.Errorf("Conflicts should be reported with both values but got %#v", err)
+	}
+	if cerr.Version != version("30", true) || cerr.CurrentVersion != version("40", true) {
+		t.Errorf("Conflicts should carry both versions but got %#v", cerr)
+	}
+
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gcg.Set("ratelimit.rps", "50", Persist()); err != nil {
+		t.Errorf("Writing after a reload should succeed but got %v", err)
+	}
+}
+
+func TestSetPersistNotWritable(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gcg.Set("app.name", "x", Persist()); err != ErrNotWritable {
+		t.Errorf("Persisting without a writable source should fail with ErrNotWritable but got %v", err)
+	}
+
+	store := &kvStore{name: "kv", values: map[string]string{}}
+	fixed := &mapSource{name: "fixed", values: map[string]string{"app.name": "fixed"}}
+	gcg, err = Load(WithPath(dir), WithSource(store), WithSource(fixed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gcg.Set("app.name", "x", Persist()); err == nil || len(store.values) != 0 {
+		t.Errorf("Persisting a key a later read-only source overrides should fail but got %v", err)
+	}
+}
+
+func TestIfVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "ratelimit.rps=10\n")
+	store := &kvStore{name: "kv", values: map[string]string{}}
+
+	// two replicas serving the same configuration
+	a, err := Load(WithPath(dir), WithSource(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Load(WithPath(dir), WithSource(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := a.Version("ratelimit.rps")
+	if v == "" || v != b.Version("ratelimit.rps") {
+		t.Fatalf("Replicas serving the same value should return the same version: %s, %s", v, b.Version("ratelimit.rps"))
+	}
+	if a.Version("missing") != "" {
+		t.Error("Undefined keys should have an empty version")
+	}
+
+	// both operators read version v, the first write wins
+	if err := a.Set("ratelimit.rps", "20", Persist(), IfVersion(v)); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	err = b.Set("ratelimit.rps", "30", Persist(), IfVersion(v))
+	cerr, ok := err.(*ConflictError)
+	if !ok || cerr.Value != "30" || cerr.Current != "20" || cerr.Version != v || cerr.CurrentVersion != b.Version("ratelimit.rps") {
+		t.Errorf("The second write should conflict with both values but got %#v", err)
+	}
+	if store.values["ratelimit.rps"] != "20" {
+		t.Errorf("The conflicting write shouldn't reach the source but it holds %s", store.values["ratelimit.rps"])
+	}
+
+	if err := a.Set("ratelimit.rps", "5", IfVersion(v)); !errors.Is(err, ErrConflict) {
+		t.Errorf("In-memory writes should check the version too but got %v", err)
+	}
+	if err := a.Set("new.key", "1", IfVersion("")); err != nil {
+		t.Errorf("An empty version should allow creating a key but got %v", err)
+	}
+	if err := a.Set("new.key", "2", IfVersion("")); err == nil {
+		t.Error("An empty version should refuse to overwrite an existing key")
+	}
+}
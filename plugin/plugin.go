@@ -0,0 +1,171 @@
+// Package plugin runs configuration sources and resolvers as separate executables, so
+// proprietary backends can be added to a service without compiling their clients into it.
+//
+// The protocol is a single JSON exchange per call: the host starts the plugin with the
+// GCONFIG_PLUGIN environment variable set to the protocol version, writes one Request
+// on its standard input and reads one Response from its standard output. Anything the
+// plugin writes to standard error is passed through. Plugins written in Go only need to
+// call Serve from their main function:
+//
+//	func main() {
+//		plugin.Serve(myBackend{})
+//	}
+//
+// Services then use the plugin like any other source:
+//
+//	import _ "github.com/narup/gconfig/plugin"
+//
+//	cfg, err := gconfig.Load(gconfig.WithSourceURL("plugin:///usr/local/bin/gconfig-vault"))
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+
+	"github.com/narup/gconfig"
+	"github.com/pkg/errors"
+)
+
+// ProtocolVersion is the version of the protocol implemented by this package.
+const ProtocolVersion = 1
+
+// EnvVar is set, to the protocol version, in the environment of the plugin processes.
+const EnvVar = "GCONFIG_PLUGIN"
+
+const (
+	// MethodLoad asks for every key/value pair provided by the plugin.
+	MethodLoad = "load"
+	// MethodResolve asks for the value a reference points to, eg: a secret name.
+	MethodResolve = "resolve"
+)
+
+// Request is sent by the host to the plugin.
+type Request struct {
+	Version int    `json:"version"`
+	Method  string `json:"method"`
+	// Ref is the reference to resolve, for MethodResolve.
+	Ref string `json:"ref,omitempty"`
+}
+
+// Response is sent by the plugin to the host. Error is set when the call failed.
+type Response struct {
+	Values map[string]string `json:"values,omitempty"`
+	Value  string            `json:"value,omitempty"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Plugin is the host side of a plugin executable. It implements gconfig.Source.
+type Plugin struct {
+	path string
+	args []string
+}
+
+// New returns a Plugin running the executable at path with the given arguments.
+func New(path string, args ...string) *Plugin {
+	return &Plugin{path: path, args: args}
+}
+
+// Name identifies the plugin by its executable name.
+func (p *Plugin) Name() string {
+	return "plugin:" + filepath.Base(p.path)
+}
+
+// Load asks the plugin for its key/value pairs.
+func (p *Plugin) Load(ctx context.Context) (map[string]string, error) {
+	resp, err := p.call(ctx, Request{Method: MethodLoad})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Values, nil
+}
+
+// Resolve asks the plugin for the value ref points to.
+func (p *Plugin) Resolve(ctx context.Context, ref string) (string, error) {
+	resp, err := p.call(ctx, Request{Method: MethodResolve, Ref: ref})
+	if err != nil {
+		return "", err
+	}
+	return resp.Value, nil
+}
+
+func (p *Plugin) call(ctx context.Context, req Request) (*Response, error) {
+	req.Version = ProtocolVersion
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.path, p.args...)
+	cmd.Env = append(os.Environ(), EnvVar+"="+strconv.Itoa(ProtocolVersion))
+	cmd.Stdin = bytes.NewReader(in)
+	cmd.Stdout = &out
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "Error running plugin %s", p.path)
+	}
+
+	resp := new(Response)
+	if err := json.Unmarshal(out.Bytes(), resp); err != nil {
+		return nil, errors.Wrapf(err, "Invalid response from plugin %s", p.path)
+	}
+	if resp.Error != "" {
+		return nil, fmt.Errorf("Plugin %s failed: %s", p.path, resp.Error)
+	}
+	return resp, nil
+}
+
+// Handler implements a plugin.
+type Handler interface {
+	// Load returns every key/value pair provided by the plugin.
+	Load(ctx context.Context) (map[string]string, error)
+	// Resolve returns the value ref points to.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// Serve answers the request of the host with h and exits. It's meant to be the whole
+// main function of a plugin.
+func Serve(h Handler) {
+	if err := serve(context.Background(), h, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func serve(ctx context.Context, h Handler, r io.Reader, w io.Writer) error {
+	var req Request
+	if err := json.NewDecoder(r).Decode(&req); err != nil {
+		return errors.Wrap(err, "Invalid request")
+	}
+
+	var resp Response
+	var err error
+	switch {
+	case req.Version != ProtocolVersion:
+		err = fmt.Errorf("unsupported protocol version %d, expected %d", req.Version, ProtocolVersion)
+	case req.Method == MethodLoad:
+		resp.Values, err = h.Load(ctx)
+	case req.Method == MethodResolve:
+		resp.Value, err = h.Resolve(ctx, req.Ref)
+	default:
+		err = fmt.Errorf("unknown method %q", req.Method)
+	}
+	if err != nil {
+		resp = Response{Error: err.Error()}
+	}
+	return json.NewEncoder(w).Encode(resp)
+}
+
+func init() {
+	gconfig.RegisterSource("plugin", func(location string) (gconfig.Source, error) {
+		return New(location), nil
+	})
+}
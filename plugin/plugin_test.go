@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/narup/gconfig"
+)
+
+// testHandler is served by the test binary itself when started as a plugin.
+type testHandler struct{}
+
+func (testHandler) Load(ctx context.Context) (map[string]string, error) {
+	return map[string]string{"app.name": "from plugin", "db.password": "s3cret"}, nil
+}
+
+func (testHandler) Resolve(ctx context.Context, ref string) (string, error) {
+	if ref == "missing" {
+		return "", errors.New("no such secret")
+	}
+	return "resolved " + ref, nil
+}
+
+func TestMain(m *testing.M) {
+	if os.Getenv(EnvVar) != "" {
+		Serve(testHandler{})
+	}
+	os.Exit(m.Run())
+}
+
+func TestPlugin(t *testing.T) {
+	p := New(os.Args[0])
+
+	values, err := p.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["app.name"] != "from plugin" {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	v, err := p.Resolve(context.Background(), "db/password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "resolved db/password" {
+		t.Errorf("Unexpected resolved value %s", v)
+	}
+
+	if _, err := p.Resolve(context.Background(), "missing"); err == nil || !strings.Contains(err.Error(), "no such secret") {
+		t.Errorf("Plugin errors should be returned but got %v", err)
+	}
+}
+
+func TestPluginSource(t *testing.T) {
+	cfg, err := gconfig.Load(gconfig.EnvOnly("GCTEST"), gconfig.WithSourceURL("plugin://"+os.Args[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := cfg.GetString("app.name"); n != "from plugin" {
+		t.Errorf("Key app.name should come from the plugin but was %s", n)
+	}
+}
+
+func TestServeVersion(t *testing.T) {
+	var out bytes.Buffer
+	if err := serve(context.Background(), testHandler{}, strings.NewReader(`{"version":99,"method":"load"}`), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "unsupported protocol version") {
+		t.Errorf("Unsupported versions should be rejected but got %s", out.String())
+	}
+}
@@ -0,0 +1,321 @@
+package gconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	s "strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// property is a key/value pair read from a properties file, along with the line it
+// starts on.
+type property struct {
+	key, value string
+	line       int
+}
+
+// parseProperties reads the content of a properties file, following the format of
+// java.util.Properties:
+//
+//   - lines starting with # or ! are comments, blank lines are ignored
+//   - a line ending with an odd number of backslashes continues on the next line,
+//     whose leading whitespace is dropped
+//   - the key ends at the first unescaped '=' or ':', so values may contain both freely,
+//     except for a ':' in the condition of a key followed by an '=', eg: key@hostname:web-01=v
+//   - a # or ! following unescaped whitespace in a value starts an inline comment,
+//     eg: "port = 8080  # default"; escape it, eg: "\#", to keep it in the value.
+//     #{...} expressions are not comments
+//   - \t \n \r \f \uXXXX and \<char> escapes are decoded in keys and values, characters
+//     outside of the BMP being written as a pair of UTF-16 surrogates, eg: \uD83D\uDE00
+//   - a leading UTF-8 byte order mark is skipped
+//
+// Surrounding whitespace is trimmed from keys and values; escape it, eg: "\ ", to keep
+// it. Lines without a separator or with an empty key are skipped and returned as
+// warnings; malformed escapes fail the parse with a *ParseError.
+func parseProperties(r io.Reader) ([]property, []*ParseError, error) {
+	var props []property
+	var warnings []*ParseError
+
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	lineNo := 0
+	for sc.Scan() {
+		lineNo++
+		start := lineNo
+		text := sc.Text()
+		if lineNo == 1 {
+			text = s.TrimPrefix(text, byteOrderMark)
+		}
+		l := s.TrimLeft(text, " \t\f")
+		if l == "" || l[0] == '#' || l[0] == '!' {
+			continue
+		}
+
+		// join continuation lines into one logical line
+		for continues(l) && sc.Scan() {
+			lineNo++
+			l = l[:len(l)-1] + s.TrimLeft(sc.Text(), " \t\f")
+		}
+		if continues(l) {
+			l = l[:len(l)-1]
+		}
+		if s.TrimSpace(l) == "" {
+			//a blank logical line, eg: a lone backslash
+			continue
+		}
+
+		sep := separatorIndex(l)
+		if sep < 0 {
+			warnings = append(warnings, &ParseError{Line: start, Key: s.Fields(l)[0], Text: l, Msg: "is not a key=value pair"})
+			continue
+		}
+		rawKey := trimUnescaped(l[:sep])
+		key, err := unescapeProperty(rawKey)
+		if err != nil {
+			err.(*ParseError).Line, err.(*ParseError).Key = start, rawKey
+			return nil, nil, err
+		}
+		if key == "" {
+			warnings = append(warnings, &ParseError{Line: start, Text: l, Msg: "has an empty key"})
+			continue
+		}
+		value, err := unescapeProperty(trimUnescaped(stripComment(l[sep+1:])))
+		if err != nil {
+			err.(*ParseError).Line, err.(*ParseError).Key = start, key
+			return nil, nil, err
+		}
+		props = append(props, property{key: key, value: value, line: start})
+	}
+	return props, warnings, sc.Err()
+}
+
+// byteOrderMark is the UTF-8 encoding of U+FEFF, which editors on Windows write at the
+// start of UTF-8 files.
+const byteOrderMark = "\uFEFF"
+
+// decodeLatin1 returns the ISO-8859-1 content of a properties file, the encoding Java
+// reads them in, as UTF-8. Content starting with a UTF-8 byte order mark is returned
+// unchanged.
+func decodeLatin1(b []byte) string {
+	if s.HasPrefix(string(b), byteOrderMark) {
+		return string(b)
+	}
+	var sb s.Builder
+	sb.Grow(len(b))
+	for _, c := range b {
+		sb.WriteRune(rune(c))
+	}
+	return sb.String()
+}
+
+// propertiesFile is the content of a properties file read by parseDir.
+type propertiesFile struct {
+	name  string
+	props []property
+}
+
+// parseDir parses every properties file in dir, whatever the profile it belongs to,
+// without resolving imports. Files are returned sorted by name.
+func parseDir(dir string) ([]propertiesFile, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.properties"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]propertiesFile, 0, len(names))
+	for _, name := range names {
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		props, _, err := parseProperties(f)
+		f.Close()
+		if perr, ok := err.(*ParseError); ok {
+			perr.File = filepath.Base(name)
+			return nil, perr
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Error parsing %s", name)
+		}
+		files = append(files, propertiesFile{name: filepath.Base(name), props: props})
+	}
+	return files, nil
+}
+
+// ParseError reports a malformed line of a properties file.
+type ParseError struct {
+	File string
+	Line int
+	// Key is the key defined by the line, or its first word when the line is malformed.
+	Key string
+	// Text is the offending text, MaskedValue when Key is sensitive.
+	Text string
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.message())
+}
+
+// message describes the error without its location.
+func (e *ParseError) message() string {
+	if e.Text == "" {
+		return e.Msg
+	}
+	return fmt.Sprintf("%q %s", e.Text, e.Msg)
+}
+
+// continues reports whether a line ends with an odd number of backslashes.
+func continues(l string) bool {
+	n := 0
+	for i := len(l) - 1; i >= 0 && l[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// separatorIndex returns the index of the first unescaped '=' or ':' in l, or -1. A ':'
+// within the condition of a key, eg: key@hostname:web-01=value, isn't a separator.
+func separatorIndex(l string) int {
+	condition := false
+	for i := 0; i < len(l); i++ {
+		switch l[i] {
+		case '\\':
+			i++
+		case '@':
+			condition = true
+		case ' ', '\t', '\f':
+			condition = false
+		case ':':
+			if condition && i+1 < len(l) && !isSpace(l[i+1]) && l[i+1] != '=' && s.Contains(l[i+1:], "=") {
+				continue
+			}
+			return i
+		case '=':
+			return i
+		}
+	}
+	return -1
+}
+
+// stripComment removes an inline comment, a # or ! following unescaped whitespace
+// after the start of the value, from v. #{ starts an expression, not a comment.
+func stripComment(v string) string {
+	v = s.TrimLeft(v, " \t\f")
+	for i := 1; i < len(v); i++ {
+		switch v[i] {
+		case '\\':
+			i++
+		case '#', '!':
+			expr := v[i] == '#' && i+1 < len(v) && v[i+1] == '{'
+			if !expr && isSpace(v[i-1]) && !continues(v[:i-1]) {
+				return v[:i]
+			}
+		}
+	}
+	return v
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+// trimUnescaped trims surrounding whitespace, keeping trailing whitespace that is escaped.
+func trimUnescaped(v string) string {
+	v = s.TrimLeft(v, " \t\f")
+	end := len(v)
+	for end > 0 && isSpace(v[end-1]) && !continues(v[:end-1]) {
+		end--
+	}
+	return v[:end]
+}
+
+// unescapeProperty decodes the escape sequences of a key or value. Malformed escapes are
+// reported with a *ParseError.
+func unescapeProperty(v string) (string, error) {
+	if !s.Contains(v, "\\") {
+		return v, nil
+	}
+
+	var sb s.Builder
+	for i := 0; i < len(v); i++ {
+		if v[i] != '\\' || i == len(v)-1 {
+			sb.WriteByte(v[i])
+			continue
+		}
+		i++
+		switch v[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+5 > len(v) {
+				return "", &ParseError{Text: v[i-1:], Msg: "is a malformed \\uXXXX escape"}
+			}
+			r, err := strconv.ParseUint(v[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", &ParseError{Text: v[i-1 : i+5], Msg: "is a malformed \\uXXXX escape"}
+			}
+			i += 4
+			if utf16.IsSurrogate(rune(r)) && i+6 < len(v) && v[i+1:i+3] == `\u` {
+				if low, err := strconv.ParseUint(v[i+3:i+7], 16, 16); err == nil {
+					if pair := utf16.DecodeRune(rune(r), rune(low)); pair != unicode.ReplacementChar {
+						sb.WriteRune(pair)
+						i += 6
+						continue
+					}
+				}
+			}
+			sb.WriteRune(rune(r))
+		default:
+			sb.WriteByte(v[i])
+		}
+	}
+	return sb.String(), nil
+}
+
+// escapeProperty escapes a key or value so parseProperties reads it back unchanged.
+func escapeProperty(v string, isKey bool) string {
+	var sb s.Builder
+	for i, r := range v {
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '=', ':', '#', '!':
+			// in values only # and ! after a space need escaping, as they'd start a comment
+			if isKey || (r == '#' || r == '!') && i > 0 && v[i-1] == ' ' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		case ' ':
+			// surrounding whitespace would be trimmed on reading
+			if isKey || i == 0 || i == len(v)-1 {
+				sb.WriteByte('\\')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,187 @@
+package gconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	content := `# comment
+! also a comment
+   
+app.name = gconfig
+db.url=jdbc:postgresql://localhost/app?user=app&ssl=true
+blob=aGVsbG8=
+list=one, \
+     two, \
+     three
+escaped\=key=value
+tabs=a\tb\nc
+unicode=\u0041\u00e9
+trailing=value\ 
+backslash=C:\\temp\\
+server.port: 8080
+spring.url : http://localhost:8080/app?a=b#frag
+pool.size = 10   # inline comment
+color=#fff
+bang=wow ! comment
+kept=a \# b
+derived=#{upper(app.name)} on #{profile} # comment
+no separator line
+`
+	props, warnings, err := parseProperties(strings.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(warnings) != 1 || warnings[0].Text != "no separator line" {
+		t.Errorf("The line without a separator should be reported but got %v", warnings)
+	}
+
+	got := make(map[string]string)
+	lines := make(map[string]int)
+	for _, p := range props {
+		got[p.key] = p.value
+		lines[p.key] = p.line
+	}
+	expected := map[string]string{
+		"app.name":    "gconfig",
+		"db.url":      "jdbc:postgresql://localhost/app?user=app&ssl=true",
+		"blob":        "aGVsbG8=",
+		"list":        "one, two, three",
+		"escaped=key": "value",
+		"tabs":        "a\tb\nc",
+		"unicode":     "Aé",
+		"trailing":    "value ",
+		"backslash":   `C:\temp\`,
+		"server.port": "8080",
+		"spring.url":  "http://localhost:8080/app?a=b#frag",
+		"pool.size":   "10",
+		"color":       "#fff",
+		"bang":        "wow",
+		"kept":        "a # b",
+		"derived":     "#{upper(app.name)} on #{profile}",
+	}
+	if len(got) != len(expected) {
+		t.Errorf("Expected %d properties but got %v", len(expected), got)
+	}
+	for k, v := range expected {
+		if got[k] != v {
+			t.Errorf("Key %s should be %q but was %q", k, v, got[k])
+		}
+	}
+	if lines["list"] != 7 || lines["escaped=key"] != 10 {
+		t.Errorf("Properties should record the line they start on: %v", lines)
+	}
+}
+
+func TestParsePropertiesMalformedEscape(t *testing.T) {
+	_, _, err := parseProperties(strings.NewReader("a=ok\nb=\\u00zz\n"))
+	if perr, ok := err.(*ParseError); !ok || perr.Line != 2 {
+		t.Errorf("Malformed unicode escapes should be reported with their line but got %v", err)
+	}
+}
+
+func TestParsePropertiesBlankLogicalLines(t *testing.T) {
+	for _, tc := range []struct {
+		name, content string
+		keys          int
+	}{
+		{"lone backslash", "\\", 0},
+		{"trailing lone backslash", "a=1\n\\", 1},
+		{"backslash then blank line", "a=1\n\\\n\nb=2", 2},
+		{"whitespace only", "a=1\n\v\nb=2\n", 2},
+	} {
+		props, warnings, err := parseProperties(strings.NewReader(tc.content))
+		if err != nil || len(warnings) != 0 || len(props) != tc.keys {
+			t.Errorf("%s: expected %d properties but got %v, %v, %v", tc.name, tc.keys, props, warnings, err)
+		}
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	values := map[string]string{
+		"a key=with:specials#!": " leading and trailing ",
+		"multi":                 "line one\nline two\\",
+		"plain":                 "x=y",
+		"comment":               "a #b !c",
+	}
+
+	var buf bytes.Buffer
+	for k, v := range values {
+		writeProperty(&buf, k, v)
+	}
+	props, _, err := parseProperties(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range props {
+		if values[p.key] != p.value {
+			t.Errorf("Key %q should round trip to %q but was %q", p.key, values[p.key], p.value)
+		}
+	}
+	if len(props) != len(values) {
+		t.Errorf("Expected %d properties but read %d", len(values), len(props))
+	}
+}
+
+func TestParseStrict(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n\n# ports\nserver.port 8080\n")
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if w := gcg.ParseWarnings(); len(w) != 1 || w[0].File != StandardPropFileName || w[0].Line != 4 {
+		t.Errorf("Lenient loads should collect the skipped line as a warning but got %v", w)
+	}
+
+	_, err = Load(WithPath(dir), ParseStrict())
+	perr, ok := err.(*ParseError)
+	if !ok {
+		t.Fatalf("Strict loads should fail with a *ParseError but got %v", err)
+	}
+	if perr.File != StandardPropFileName || perr.Line != 4 {
+		t.Errorf("The error should name the file and line but was %v", perr)
+	}
+	if !strings.Contains(perr.Error(), "application.properties:4:") {
+		t.Errorf("Unexpected error message %s", perr)
+	}
+}
+
+func TestParsePropertiesEncoding(t *testing.T) {
+	props, _, err := parseProperties(strings.NewReader("\ufeffapp.name=caf\\u00e9\nemoji=\\uD83D\\uDE00 \\uD83D!\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if props[0].key != "app.name" || props[0].value != "café" {
+		t.Errorf("The byte order mark should be skipped but got %q=%q", props[0].key, props[0].value)
+	}
+	if props[1].value != "😀 \ufffd!" {
+		t.Errorf("Surrogate pairs should decode to one character but got %q", props[1].value)
+	}
+}
+
+func TestLatin1Encoding(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "greeting=Gr\xfc\xdfe \\u00e0 tous\nstra\xdfe=ok\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv(), Latin1Encoding())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := gcg.GetString("greeting"); v != "Grüße à tous" {
+		t.Errorf("Expected the Latin-1 value decoded but got %q", v)
+	}
+	if v := gcg.GetString("straße"); v != "ok" {
+		t.Errorf("Expected the Latin-1 key decoded but got %q", v)
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "\xef\xbb\xbfgreeting=Grüße\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if v := gcg.GetString("greeting"); v != "Grüße" {
+		t.Errorf("A file with a UTF-8 byte order mark should be read as UTF-8 but got %q", v)
+	}
+}
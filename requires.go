@@ -0,0 +1,79 @@
+package gconfig
+
+import (
+	"fmt"
+	"os"
+	s "strings"
+	"time"
+)
+
+// RequiresEnvKey lists, comma separated, the environment variables a configuration needs,
+// eg: gconfig.requires.env=DB_PASSWORD,API_KEY in application-prod.properties. The lists
+// of every file read add up, from the default file to the most specific profile file,
+// drop-ins and fragments included, and Load fails with a *MissingEnvError naming every
+// variable that isn't set, in the process environment or a dotenv file.
+const RequiresEnvKey = "gconfig.requires.env"
+
+// MissingEnvError is returned by Load when environment variables listed under
+// RequiresEnvKey aren't set.
+type MissingEnvError struct {
+	Profile string
+	Vars    []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("Missing environment variables required by profile '%s': %s", e.Profile, s.Join(e.Vars, ", "))
+}
+
+// RequiredEnv returns the environment variables listed under RequiresEnvKey by the
+// configuration, in order of declaration.
+func (c *GConfig) RequiredEnv() []string {
+	return splitEnvList(c.current().requiredEnv...)
+}
+
+// RequiredEnvInDir returns the environment variables listed under RequiresEnvKey by the
+// properties files of profile in dir, without loading the configuration, so deploy
+// tooling can check or generate a manifest before the application starts. dir is read as
+// Load reads WithPath, a pattern or a list of directories included, and the other
+// options select the files as they do for Load, eg: WithAppName or ProfileFragments.
+func RequiredEnvInDir(dir, profile string, opts ...Option) ([]string, error) {
+	o := newOptions(append([]Option{WithLogger(nil)}, append(opts, WithPath(dir))...))
+	c := &GConfig{Profile: s.ToLower(profile), opts: o, stats: new(stats)}
+	c.v = &values{overrides: make(map[string]string), sources: make(map[string]time.Time)}
+	if _, err := c.loadFiles(o); err != nil {
+		return nil, err
+	}
+	return c.RequiredEnv(), nil
+}
+
+// splitEnvList merges comma separated lists of variable names, dropping duplicates.
+func splitEnvList(lists ...string) []string {
+	var names []string
+	for _, l := range lists {
+		for _, name := range s.Split(l, ",") {
+			if name = s.TrimSpace(name); name != "" && !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// checkRequiredEnv fails with a *MissingEnvError if a variable listed under
+// RequiresEnvKey isn't set.
+func (c *GConfig) checkRequiredEnv(o *options) error {
+	var missing []string
+	for _, name := range c.RequiredEnv() {
+		if _, ok := os.LookupEnv(name); ok && !o.noEnv {
+			continue
+		}
+		if _, ok := c.v.dotEnvVars[name]; ok {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) > 0 {
+		return &MissingEnvError{Profile: c.Profile, Vars: missing}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package gconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRequiredEnv(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "gconfig.requires.env=GC_TEST_HOME\n")
+	writeConfig(t, dir, "application-prod.properties", "gconfig.requires.env=GC_TEST_DB_PASSWORD, GC_TEST_API_KEY,GC_TEST_HOME\n")
+	t.Setenv("GC_TEST_HOME", "/srv")
+
+	names, err := RequiredEnvInDir(dir, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"GC_TEST_HOME", "GC_TEST_DB_PASSWORD", "GC_TEST_API_KEY"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected the manifest %v but got %v", want, names)
+	}
+
+	_, err = Load(WithPath(dir), WithProfile("prod"), WithoutFlags())
+	merr, ok := err.(*MissingEnvError)
+	if !ok {
+		t.Fatalf("Expected a *MissingEnvError but got %v", err)
+	}
+	if want := []string{"GC_TEST_DB_PASSWORD", "GC_TEST_API_KEY"}; merr.Profile != "prod" || !reflect.DeepEqual(merr.Vars, want) {
+		t.Errorf("Expected %v missing for prod but got %v for %s", want, merr.Vars, merr.Profile)
+	}
+	if !permanent(err) {
+		t.Error("Missing environment variables should not be retried")
+	}
+
+	writeConfig(t, dir, ".env", "GC_TEST_API_KEY=key\n")
+	t.Setenv("GC_TEST_DB_PASSWORD", "secret")
+	gcg, err := Load(WithPath(dir), WithProfile("prod"), WithoutFlags(), DotEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if names := gcg.RequiredEnv(); len(names) != 3 {
+		t.Errorf("Expected 3 required variables but got %v", names)
+	}
+
+	if _, err := Load(WithPath(dir), WithoutFlags()); err != nil {
+		t.Errorf("The default profile only requires GC_TEST_HOME but got %v", err)
+	}
+}
+
+func TestRequiredEnvLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "gconfig.requires.env=GC_TEST_HOME\n")
+	writeConfig(t, dir, "application-prod.properties", "gconfig.requires.env=GC_TEST_DB_PASSWORD\n")
+	writeConfig(t, dir, "application-prod-eu.properties", "gconfig.requires.env=GC_TEST_EU_KEY\n")
+	if err := os.Mkdir(filepath.Join(dir, "application-prod.properties.d"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(dir, "application-prod.properties.d"), "10-api.properties", "gconfig.requires.env=GC_TEST_API_KEY\n")
+
+	want := []string{"GC_TEST_HOME", "GC_TEST_DB_PASSWORD", "GC_TEST_API_KEY", "GC_TEST_EU_KEY"}
+	for path, want := range map[string][]string{
+		dir: want,
+		// the drop-in directory doesn't match the pattern
+		filepath.Join(dir, "*.properties"): {"GC_TEST_HOME", "GC_TEST_DB_PASSWORD", "GC_TEST_EU_KEY"},
+	} {
+		names, err := RequiredEnvInDir(path, "prod-eu")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(names, want) {
+			t.Errorf("The lists of the files read from %s should add up to %v but got %v", path, want, names)
+		}
+	}
+
+	_, err := Load(WithPath(dir), WithProfile("prod-eu"), WithoutFlags(), WithoutEnv())
+	if merr, ok := err.(*MissingEnvError); !ok || !reflect.DeepEqual(merr.Vars, want) {
+		t.Errorf("Expected %v missing but got %v", want, err)
+	}
+}
@@ -0,0 +1,154 @@
+package gconfig
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	s "strings"
+)
+
+// placeholder matches a ${name}, ${name:-default} or ${name|default} reference inside a value.
+var placeholder = regexp.MustCompile(`\${[^}]+}`)
+
+// expression matches a #{expr} expression inside a value.
+var expression = regexp.MustCompile(`#{[^}]+}`)
+
+// replaceSysVars returns the value of key with every placeholder resolved. A placeholder
+// names either another configuration key, which makes the value derived from it, eg:
+//
+//	db.url=jdbc:postgresql://${db.host}:${db.port}/${db.name}
+//
+// or an environment variable, or calls a function, eg: ${hostname} or
+// ${random.int(1024,65535)}, see callFunction. Keys take precedence over environment
+// variables. When the
+// name resolves to an empty value the placeholder's default is used, written after ':-'
+// as in shells, eg: ${PORT:-8080}, or after '|', and then the value of the name.default
+// key, see DefaultKeySuffix. Derived values are computed on every read, so they stay
+// consistent with their inputs across reloads. Values can also embed #{expr}
+// expressions, see Eval, eg: pool.size=#{int(cpu.count * 2)}, or be a reference
+// resolved by a registered Resolver.
+//
+// A key whose value resolves to an empty string, or that isn't defined, takes the
+// value of its key.default entry, if any.
+func (c *GConfig) replaceSysVars(key string) string {
+	abs := c.prefix + key
+	raw, _ := c.current().get(abs, c.Profile).(string)
+	v := raw
+	//most values are plain, skip setting up their expansion
+	if s.IndexByte(raw, '{') >= 0 {
+		v = c.expand(abs, raw, map[string]bool{abs: true})
+	}
+	v = c.resolveRef(v)
+	if v == "" {
+		v = c.keyDefault(abs, map[string]bool{abs: true})
+	}
+	return v
+}
+
+// DefaultKeySuffix names the entry holding the default value of a key: db.url.default
+// is used when db.url is missing or resolves to an empty value, eg: because the
+// environment variable it references isn't set.
+const DefaultKeySuffix = ".default"
+
+// keyDefault returns the expanded value of the default entry of the absolute key.
+func (c *GConfig) keyDefault(key string, seen map[string]bool) string {
+	raw, ok := c.current().get(key+DefaultKeySuffix, c.Profile).(string)
+	if !ok || seen[key+DefaultKeySuffix] {
+		return ""
+	}
+	seen[key+DefaultKeySuffix] = true
+	defer delete(seen, key+DefaultKeySuffix)
+	return c.resolveRef(c.expand(key+DefaultKeySuffix, raw, seen))
+}
+
+// expand resolves the placeholders in value, the value of the absolute key. seen holds
+// the keys being expanded, to break reference cycles.
+func (c *GConfig) expand(key, value string, seen map[string]bool) string {
+	v, _ := c.expandCycle(key, value, seen)
+	return v
+}
+
+// expandCycle is expand, also returning the *cycleError of an expression of value that
+// refers back to a key being expanded, so the expression referencing key fails too.
+func (c *GConfig) expandCycle(key, value string, seen map[string]bool) (string, error) {
+	var cycle error
+	if s.Contains(value, "${") {
+		value = placeholder.ReplaceAllStringFunc(value, func(p string) string {
+			name, def := splitPlaceholder(p)
+			if v, ok := c.callFunction(key, name); ok && v != "" {
+				return v
+			}
+			if v := c.lookupRef(name, seen); v != "" {
+				return v
+			}
+			if def != "" {
+				return def
+			}
+			return c.keyDefault(name, seen)
+		})
+	}
+	if s.Contains(value, "#{") {
+		value = expression.ReplaceAllStringFunc(value, func(e string) string {
+			v, err := evalExpr(e[2:len(e)-1], c.exprVars(nil, seen), c.lookupEnv)
+			if _, ok := err.(*cycleError); ok && cycle == nil {
+				cycle = err
+			}
+			if err != nil {
+				// leave the expression in place so the mistake is visible
+				return e
+			}
+			return formatExprValue(v)
+		})
+	}
+	return value, cycle
+}
+
+// cycleError reports a key whose expressions refer back to itself, eg: a=#{b} and
+// b=#{a}.
+type cycleError struct {
+	key string
+}
+
+func (e *cycleError) Error() string {
+	return fmt.Sprintf("Reference cycle: %s refers to itself", e.key)
+}
+
+// lookupRef resolves the name of a placeholder against the configuration keys, using
+// absolute key names even on Sub views, and then against the environment.
+func (c *GConfig) lookupRef(name string, seen map[string]bool) string {
+	if raw, ok := c.current().get(name, c.Profile).(string); ok {
+		if seen[name] {
+			return ""
+		}
+		seen[name] = true
+		defer delete(seen, name)
+		return c.expand(name, raw, seen)
+	}
+	return c.lookupEnv(name)
+}
+
+// lookupEnv returns the value of an environment variable referenced by the configuration,
+// falling back to the dotenv files. The process environment is skipped if the
+// configuration was loaded WithoutEnv.
+func (c *GConfig) lookupEnv(name string) string {
+	if o := c.base().opts; o == nil || !o.noEnv {
+		if v, ok := os.LookupEnv(name); ok {
+			return v
+		}
+	}
+	return c.current().dotEnvVars[name]
+}
+
+// splitPlaceholder splits ${name:-default} or ${name|default} into its name and default
+// value, at whichever separator comes first.
+func splitPlaceholder(p string) (name, def string) {
+	p = s.TrimSuffix(s.TrimPrefix(p, "${"), "}")
+	i, j := s.Index(p, ":-"), s.Index(p, "|")
+	switch {
+	case i >= 0 && (j < 0 || i < j):
+		return p[:i], p[i+2:]
+	case j >= 0:
+		return p[:j], p[j+1:]
+	}
+	return p, ""
+}
@@ -0,0 +1,81 @@
+package gconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDerivedKeys(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `db.host=localhost
+db.port=5432
+db.name=${DB_NAME_TEST|app}
+db.url=jdbc:postgresql://${db.host}:${db.port}/${db.name}
+loop.a=${loop.b}
+loop.b=${loop.a|fallback}
+`)
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if u := gcg.GetString("db.url"); u != "jdbc:postgresql://localhost:5432/app" {
+		t.Errorf("Derived key db.url was %s", u)
+	}
+
+	os.Setenv("DB_NAME_TEST", "orders")
+	defer os.Unsetenv("DB_NAME_TEST")
+	gcg.Set("db.host", "db.internal")
+	if u := gcg.GetString("db.url"); u != "jdbc:postgresql://db.internal:5432/orders" {
+		t.Errorf("Derived key db.url should follow its inputs but was %s", u)
+	}
+
+	if u := gcg.Sub("db").GetString("url"); u != "jdbc:postgresql://db.internal:5432/orders" {
+		t.Errorf("References should be absolute in Sub views but db.url was %s", u)
+	}
+
+	if v := gcg.GetString("loop.a"); v != "fallback" {
+		t.Errorf("Reference cycles should resolve to the default but loop.a was %s", v)
+	}
+}
+
+func TestPlaceholderDefaults(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `api.url=${GCTEST_API_URL:-http://localhost:8080}
+api.both=${GCTEST_UNSET:-a|b}
+db.url=${GCTEST_DB_URL}
+db.url.default=postgres://localhost/${app.name}
+app.name=gconfig
+cache.size.default=64
+queue.url=${GCTEST_QUEUE_URL}
+`)
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := gcg.GetString("api.url"); v != "http://localhost:8080" {
+		t.Errorf("The :- default should apply when the variable isn't set but got %s", v)
+	}
+	if v := gcg.GetString("api.both"); v != "a|b" {
+		t.Errorf("The first separator should split the default but got %s", v)
+	}
+	if v := gcg.GetString("db.url"); v != "postgres://localhost/gconfig" {
+		t.Errorf("The key.default entry should apply when the value resolves empty but got %s", v)
+	}
+	if !gcg.Exists("cache.size") || gcg.GetInt("cache.size") != 64 {
+		t.Error("A key defined only by its default entry should exist and take its value")
+	}
+	if v := gcg.Sub("db").GetString("url"); v != "postgres://localhost/gconfig" {
+		t.Errorf("Default entries should apply on views but got %s", v)
+	}
+	if v := gcg.GetString("queue.url"); v != "" {
+		t.Errorf("Keys without default should stay empty but got %s", v)
+	}
+
+	os.Setenv("GCTEST_DB_URL", "postgres://db/app")
+	defer os.Unsetenv("GCTEST_DB_URL")
+	if v := gcg.GetString("db.url"); v != "postgres://db/app" {
+		t.Errorf("The environment should win over the default entry but got %s", v)
+	}
+}
@@ -0,0 +1,75 @@
+package gconfig
+
+import (
+	"context"
+	s "strings"
+	"sync"
+)
+
+// Resolver turns a reference into the value it points to. Values consisting of a single
+// reference whose scheme has a registered Resolver, eg: db.password=secretsmanager://myapp/db,
+// are resolved when read, after placeholders are expanded.
+type Resolver interface {
+	// Resolve returns the value of the reference, given without its "scheme://" prefix.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+// ResolverFunc adapts a function to the Resolver interface.
+type ResolverFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve calls f(ctx, ref).
+func (f ResolverFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	resolversMu sync.RWMutex
+	resolvers   = make(map[string]Resolver)
+)
+
+// RegisterResolver makes r resolve the values starting with "scheme://". It panics if the
+// scheme is registered twice or r is nil.
+func RegisterResolver(scheme string, r Resolver) {
+	resolversMu.Lock()
+	defer resolversMu.Unlock()
+	if r == nil {
+		panic("gconfig: RegisterResolver resolver is nil")
+	}
+	if _, dup := resolvers[scheme]; dup {
+		panic("gconfig: RegisterResolver called twice for scheme " + scheme)
+	}
+	resolvers[scheme] = r
+}
+
+// referenceScheme returns the scheme of v if it's a reference with a registered Resolver.
+func referenceScheme(v string) string {
+	i := s.Index(v, "://")
+	if i <= 0 {
+		return ""
+	}
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	if _, ok := resolvers[v[:i]]; !ok {
+		return ""
+	}
+	return v[:i]
+}
+
+// resolveRef resolves v if it's a reference with a registered scheme. References that
+// fail to resolve are logged and left in place so the mistake is visible.
+func (c *GConfig) resolveRef(v string) string {
+	scheme := referenceScheme(v)
+	if scheme == "" {
+		return v
+	}
+	resolversMu.RLock()
+	r := resolvers[scheme]
+	resolversMu.RUnlock()
+
+	resolved, err := r.Resolve(context.Background(), v[len(scheme)+3:])
+	if err != nil {
+		c.logf("Error resolving %s: %s\n", v, err)
+		return v
+	}
+	return resolved
+}
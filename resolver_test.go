@@ -0,0 +1,167 @@
+package gconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// registerResolver registers r for scheme until the end of the test.
+func registerResolver(t *testing.T, scheme string, r Resolver) {
+	RegisterResolver(scheme, r)
+	t.Cleanup(func() {
+		resolversMu.Lock()
+		delete(resolvers, scheme)
+		resolversMu.Unlock()
+	})
+}
+
+func TestResolver(t *testing.T) {
+	registerResolver(t, "gctest", ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		if ref == "broken" {
+			return "", errors.New("unreachable")
+		}
+		return "resolved:" + ref, nil
+	}))
+
+	gcg, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithDefaults(map[string]string{
+		"db.password": "gctest://db/${db.name}",
+		"db.name":     "app",
+		"broken":      "gctest://broken",
+		"url":         "https://example.com",
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := gcg.GetString("db.password"); p != "resolved:db/app" {
+		t.Errorf("The reference should be resolved after expansion but was %s", p)
+	}
+	if b := gcg.GetString("broken"); b != "gctest://broken" {
+		t.Errorf("Failed references should be left in place but got %s", b)
+	}
+	if u := gcg.GetString("url"); u != "https://example.com" {
+		t.Errorf("Values with unregistered schemes should be untouched but got %s", u)
+	}
+}
+
+func TestCachedResolver(t *testing.T) {
+	var mu sync.Mutex
+	calls, fail := 0, false
+	release := make(chan struct{})
+	rc := CachedResolver(ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		mu.Lock()
+		calls++
+		n, f := calls, fail
+		mu.Unlock()
+		<-release
+		if f {
+			return "", errors.New("unreachable")
+		}
+		return fmt.Sprintf("%s-%d", ref, n), nil
+	}), time.Minute)
+	now := time.Now()
+	rc.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = rc.Resolve(context.Background(), "db")
+		}(i)
+	}
+	for rc.Stats().Misses+rc.Stats().Shared < 5 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	for _, r := range results {
+		if r != "db-1" {
+			t.Fatalf("Concurrent lookups should share a single call but got %v", results)
+		}
+	}
+
+	if v, _ := rc.Resolve(context.Background(), "db"); v != "db-1" {
+		t.Errorf("A fresh value should be served from the cache but got %s", v)
+	}
+	now = now.Add(2 * time.Minute)
+	mu.Lock()
+	fail = true
+	mu.Unlock()
+	if v, err := rc.Resolve(context.Background(), "db"); err != nil || v != "db-1" {
+		t.Errorf("The expired value should be served when refreshing fails but got %s, %v", v, err)
+	}
+	if _, err := rc.Resolve(context.Background(), "other"); err == nil {
+		t.Error("Errors resolving an uncached reference should be returned")
+	}
+
+	st := rc.Stats()
+	if st.Misses != 3 || st.Shared != 4 || st.Hits != 1 || st.Errors != 2 || st.Stale != 1 || st.Entries != 1 {
+		t.Errorf("Unexpected stats %+v", st)
+	}
+	rc.Purge()
+	if rc.Stats().Entries != 0 {
+		t.Error("Purge should drop the cached values")
+	}
+
+	registerResolver(t, "gctestcached", rc)
+	if _, ok := ResolverCaches()["gctestcached"]; !ok {
+		t.Error("The stats of registered caches should be listed")
+	}
+}
+
+func TestCachedResolverContext(t *testing.T) {
+	release := make(chan struct{})
+	rc := CachedResolver(ResolverFunc(func(ctx context.Context, ref string) (string, error) {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-release:
+			return "resolved", nil
+		}
+	}), time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := rc.Resolve(ctx, "db")
+		first <- err
+	}()
+	for rc.Stats().Misses < 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	short, cancelShort := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancelShort()
+	if _, err := rc.Resolve(short, "db"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("A shared lookup should give up once its ctx is done but got %v", err)
+	}
+
+	type result struct {
+		value string
+		err   error
+	}
+	last := make(chan result, 1)
+	go func() {
+		v, err := rc.Resolve(context.Background(), "db")
+		last <- result{v, err}
+	}()
+	for rc.Stats().Shared < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("The cancelled lookup should fail but got %v", err)
+	}
+	for rc.Stats().Misses < 2 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	if r := <-last; r.err != nil || r.value != "resolved" {
+		t.Errorf("A lookup shouldn't get the context error of another but got %q, %v", r.value, r.err)
+	}
+}
@@ -0,0 +1,145 @@
+package gconfig
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ResolverCache is a Resolver caching the references resolved by another one, for
+// resolvers too expensive to call on every read, eg: running a command or calling a KMS.
+// Concurrent lookups of the same reference share a single call. When refreshing an
+// expired reference fails the cached value keeps being served. Create it with
+// CachedResolver.
+type ResolverCache struct {
+	r   Resolver
+	ttl time.Duration
+	now func() time.Time
+
+	mu       sync.Mutex // guards the fields below
+	entries  map[string]cachedRef
+	inflight map[string]*resolveCall
+	stats    ResolverCacheStats
+}
+
+// ResolverCacheStats counts the lookups of a ResolverCache.
+type ResolverCacheStats struct {
+	// Hits counts the lookups served from the cache, Misses those calling the resolver
+	// and Shared those waiting for the call of a concurrent lookup.
+	Hits, Misses, Shared uint64
+	// Errors counts the failed calls, Stale those whose error was hidden by serving the
+	// expired value.
+	Errors, Stale uint64
+	// Entries is the number of cached references.
+	Entries int
+}
+
+type cachedRef struct {
+	value   string
+	fetched time.Time
+}
+
+// resolveCall is a call to the resolver that concurrent lookups of its reference wait for.
+type resolveCall struct {
+	done  chan struct{}
+	value string
+	err   error
+	// cancelled is set when the call failed as the context of the lookup making it was
+	// done, an error the other lookups don't share
+	cancelled bool
+}
+
+// CachedResolver returns r with its results cached for ttl, eg:
+//
+//	gconfig.RegisterResolver("vault", gconfig.CachedResolver(vaultResolver, 5*time.Minute))
+func CachedResolver(r Resolver, ttl time.Duration) *ResolverCache {
+	return &ResolverCache{r: r, ttl: ttl, now: time.Now, entries: make(map[string]cachedRef), inflight: make(map[string]*resolveCall)}
+}
+
+// Resolve returns the cached value of ref while it's fresh and calls the wrapped
+// resolver otherwise. A lookup waiting for the call of a concurrent one gives up once its
+// own ctx is done, and makes the call again if it failed as the context of the other was.
+func (rc *ResolverCache) Resolve(ctx context.Context, ref string) (string, error) {
+	for {
+		now := rc.now()
+		rc.mu.Lock()
+		cached, ok := rc.entries[ref]
+		if ok && now.Sub(cached.fetched) < rc.ttl {
+			rc.stats.Hits++
+			rc.mu.Unlock()
+			return cached.value, nil
+		}
+		call, shared := rc.inflight[ref]
+		if !shared {
+			rc.stats.Misses++
+			call = &resolveCall{done: make(chan struct{})}
+			rc.inflight[ref] = call
+			rc.mu.Unlock()
+			rc.call(ctx, ref, call, cached, ok, now)
+			return call.value, call.err
+		}
+		rc.stats.Shared++
+		rc.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+		if !call.cancelled {
+			return call.value, call.err
+		}
+	}
+}
+
+// call calls the wrapped resolver for ref, falling back to the cached value, if any, when
+// it fails, and releases the lookups waiting for it.
+func (rc *ResolverCache) call(ctx context.Context, ref string, call *resolveCall, cached cachedRef, ok bool, now time.Time) {
+	call.value, call.err = rc.r.Resolve(ctx, ref)
+
+	rc.mu.Lock()
+	switch {
+	case call.err == nil:
+		rc.entries[ref] = cachedRef{value: call.value, fetched: now}
+	case ok:
+		rc.stats.Errors++
+		rc.stats.Stale++
+		call.value, call.err = cached.value, nil
+	default:
+		rc.stats.Errors++
+		call.cancelled = ctx.Err() != nil
+	}
+	delete(rc.inflight, ref)
+	rc.mu.Unlock()
+	close(call.done)
+}
+
+// Stats returns the lookup counters of the cache.
+func (rc *ResolverCache) Stats() ResolverCacheStats {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	st := rc.stats
+	st.Entries = len(rc.entries)
+	return st
+}
+
+// Purge drops every cached value, so the next lookups call the resolver.
+func (rc *ResolverCache) Purge() {
+	rc.mu.Lock()
+	rc.entries = make(map[string]cachedRef)
+	rc.mu.Unlock()
+}
+
+// ResolverCaches returns the stats of the registered resolvers that are a ResolverCache,
+// keyed by scheme, eg: to export them as metrics.
+func ResolverCaches() map[string]ResolverCacheStats {
+	resolversMu.RLock()
+	defer resolversMu.RUnlock()
+	stats := make(map[string]ResolverCacheStats)
+	for scheme, r := range resolvers {
+		if rc, ok := r.(*ResolverCache); ok {
+			stats[scheme] = rc.Stats()
+		}
+	}
+	return stats
+}
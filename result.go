@@ -0,0 +1,135 @@
+package gconfig
+
+import (
+	"fmt"
+	s "strings"
+)
+
+// WarningKind classifies the issues reported by LoadDetailed.
+type WarningKind int
+
+const (
+	// WarningIgnoredLine is a malformed properties line that was skipped, see ParseStrict
+	WarningIgnoredLine WarningKind = iota
+	// WarningEmptyConfig means no source defined any key
+	WarningEmptyConfig
+	// WarningMissingProfile means a profile is active but has no properties file
+	WarningMissingProfile
+	// WarningUnresolvedPlaceholder is a ${name} placeholder without a default naming
+	// neither a key nor an environment variable
+	WarningUnresolvedPlaceholder
+	// WarningInvalidExpression is a #{expr} expression that fails to evaluate
+	WarningInvalidExpression
+	// WarningKeyName is a key breaking the naming conventions, see WithLint
+	WarningKeyName
+	// WarningInvalidSchedule is an activateAt or deactivateAt entry that isn't an RFC 3339
+	// timestamp, see ActivateAtSuffix
+	WarningInvalidSchedule
+)
+
+func (k WarningKind) String() string {
+	switch k {
+	case WarningIgnoredLine:
+		return "ignored line"
+	case WarningEmptyConfig:
+		return "empty configuration"
+	case WarningMissingProfile:
+		return "missing profile"
+	case WarningUnresolvedPlaceholder:
+		return "unresolved placeholder"
+	case WarningInvalidExpression:
+		return "invalid expression"
+	case WarningKeyName:
+		return "key name"
+	case WarningInvalidSchedule:
+		return "invalid schedule"
+	}
+	return "unknown"
+}
+
+// Warning is an issue that didn't prevent the configuration from loading. File and Line
+// are set for warnings about a properties line, Key for warnings about a value.
+type Warning struct {
+	Kind    WarningKind
+	File    string
+	Line    int
+	Key     string
+	Message string
+}
+
+func (w Warning) String() string {
+	switch {
+	case w.File != "":
+		return fmt.Sprintf("%s: %s:%d: %s", w.Kind, w.File, w.Line, w.Message)
+	case w.Key != "":
+		return fmt.Sprintf("%s: %s: %s", w.Kind, w.Key, w.Message)
+	}
+	return fmt.Sprintf("%s: %s", w.Kind, w.Message)
+}
+
+// Result is the outcome of LoadDetailed.
+type Result struct {
+	Config   *GConfig
+	Warnings []Warning
+}
+
+// LoadDetailed is Load returning, along with the configuration, the issues that didn't
+// prevent it from loading, so applications can report them instead of scraping logs.
+func LoadDetailed(opts ...Option) (*Result, error) {
+	c, err := Load(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Config: c, Warnings: c.Warnings()}, nil
+}
+
+// Warnings returns the issues found in the loaded configuration, see LoadDetailed.
+func (c *GConfig) Warnings() []Warning {
+	var warnings []Warning
+	for _, pe := range c.ParseWarnings() {
+		warnings = append(warnings, Warning{Kind: WarningIgnoredLine, File: pe.File, Line: pe.Line, Message: pe.message()})
+	}
+
+	b := c.base()
+	vs := c.current()
+	if vs.isEmpty() {
+		warnings = append(warnings, Warning{Kind: WarningEmptyConfig, Message: fmt.Sprintf("no key is defined for profile '%s'", b.Profile)})
+	}
+	envOnly := b.opts != nil && b.opts.envOnly
+	if b.Profile != "" && vs.profileConfig.fileInfo == nil && !envOnly {
+		var names []string
+		for _, base := range b.opts.configBases() {
+			names = append(names, fmt.Sprintf("%s-%s.properties", base, b.Profile))
+		}
+		warnings = append(warnings, Warning{Kind: WarningMissingProfile, Message: fmt.Sprintf("%s not found", s.Join(names, " or "))})
+	}
+
+	if b.opts != nil && b.opts.lint != nil {
+		warnings = append(warnings, c.Lint(*b.opts.lint)...)
+	}
+
+	for _, k := range c.keys() {
+		raw, _ := c.getValue(k).(string)
+		if isScheduleKey(k) {
+			if _, ok := vs.scheduledAt(c.prefix + k); !ok {
+				warnings = append(warnings, Warning{Kind: WarningInvalidSchedule, Key: k, Message: fmt.Sprintf("%q is not an RFC 3339 timestamp", raw)})
+			}
+		}
+		for _, p := range placeholder.FindAllString(raw, -1) {
+			name, def := splitPlaceholder(p)
+			seen := map[string]bool{c.prefix + k: true}
+			if v, ok := c.callFunction(c.prefix+k, name); ok && v != "" {
+				continue
+			}
+			if def == "" && c.lookupRef(name, seen) == "" && c.keyDefault(name, seen) == "" {
+				warnings = append(warnings, Warning{Kind: WarningUnresolvedPlaceholder, Key: k, Message: fmt.Sprintf("%s resolves to an empty value", p)})
+			}
+		}
+		for _, e := range expression.FindAllString(raw, -1) {
+			if _, err := evalExpr(e[2:len(e)-1], c.exprVars(nil, map[string]bool{c.prefix + k: true}), c.lookupEnv); err != nil {
+				warnings = append(warnings, Warning{Kind: WarningInvalidExpression, Key: k, Message: fmt.Sprintf("%s: %s", e, err)})
+			}
+		}
+	}
+	return warnings
+}
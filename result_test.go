@@ -0,0 +1,54 @@
+package gconfig
+
+import (
+	"testing"
+)
+
+func TestLoadDetailed(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, `app.name=gconfig
+server.port 8080
+db.url=postgres://${GCTEST_UNSET_HOST}/app
+db.user=${GCTEST_UNSET_USER|app}
+db.name=${app.name}
+pool.size=#{int(}
+`)
+
+	res, err := LoadDetailed(WithPath(dir), WithProfile("qa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Config.GetString("app.name") != "gconfig" {
+		t.Error("The result should hold the loaded configuration")
+	}
+
+	kinds := make(map[WarningKind][]Warning)
+	for _, w := range res.Warnings {
+		kinds[w.Kind] = append(kinds[w.Kind], w)
+	}
+	if w := kinds[WarningIgnoredLine]; len(w) != 1 || w[0].Line != 2 || w[0].File != StandardPropFileName {
+		t.Errorf("The malformed line should be reported but got %v", w)
+	}
+	if w := kinds[WarningMissingProfile]; len(w) != 1 {
+		t.Errorf("The missing profile file should be reported but got %v", w)
+	}
+	if w := kinds[WarningUnresolvedPlaceholder]; len(w) != 1 || w[0].Key != "db.url" {
+		t.Errorf("Only the placeholder without default should be reported but got %v", w)
+	}
+	if w := kinds[WarningInvalidExpression]; len(w) != 1 || w[0].Key != "pool.size" {
+		t.Errorf("The invalid expression should be reported but got %v", w)
+	}
+	if len(kinds[WarningEmptyConfig]) != 0 {
+		t.Error("The configuration isn't empty")
+	}
+}
+
+func TestLoadDetailedEmpty(t *testing.T) {
+	res, err := LoadDetailed(WithPath(t.TempDir()), EnvOnly("GCTEST_NOTHING"), WithProfile("qa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Warnings) != 1 || res.Warnings[0].Kind != WarningEmptyConfig {
+		t.Errorf("An empty configuration should be reported but got %v", res.Warnings)
+	}
+}
@@ -0,0 +1,177 @@
+package gconfig
+
+import (
+	"bytes"
+	"crypto/tls"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// rotating holds the value parsed from one or more keys, parsing it again whenever they
+// change and keeping the previous value when the new one doesn't parse.
+type rotating[T any] struct {
+	parse func() (T, error)
+	equal func(a, b T) bool
+
+	mu  sync.RWMutex // guards cur, err and fns
+	cur T
+	err error
+	fns []func(T)
+
+	cancel func()
+}
+
+// newRotating parses the initial value and subscribes to the keys matching pattern.
+func newRotating[T any](c *GConfig, pattern string, parse func() (T, error), equal func(a, b T) bool) (*rotating[T], error) {
+	cur, err := parse()
+	if err != nil {
+		return nil, err
+	}
+	r := &rotating[T]{parse: parse, equal: equal, cur: cur}
+	r.cancel = c.Subscribe(pattern, func(Event) {
+		v, err := parse()
+		r.mu.Lock()
+		r.err = err
+		if err != nil {
+			r.mu.Unlock()
+			c.logf("Keeping the current value of %s, the new one is invalid: %s\n", pattern, err)
+			return
+		}
+		if equal(r.cur, v) {
+			r.mu.Unlock()
+			return
+		}
+		r.cur = v
+		fns := make([]func(T), len(r.fns))
+		copy(fns, r.fns)
+		r.mu.Unlock()
+		for _, fn := range fns {
+			fn(v)
+		}
+	})
+	return r, nil
+}
+
+func (r *rotating[T]) get() T {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cur
+}
+
+func (r *rotating[T]) onRotate(fn func(T)) {
+	r.mu.Lock()
+	r.fns = append(r.fns, fn)
+	r.mu.Unlock()
+}
+
+// RotatingPassword hands out the current value of a password key, eg: a database
+// password held by a secrets manager, as it rotates. Create it with NewRotatingDBPassword.
+type RotatingPassword struct {
+	r *rotating[string]
+}
+
+// NewRotatingDBPassword returns the password held by key, resolved and kept up to date as
+// Set, Reload or WatchSources change it. Consumers either read Password when opening a
+// connection or are called back with OnRotate to update a pool. It fails with a
+// *MissingKeysError if key isn't defined.
+func NewRotatingDBPassword(c *GConfig, key string) (*RotatingPassword, error) {
+	r, err := newRotating(c, key, func() (string, error) {
+		if !c.Exists(key) {
+			return "", &MissingKeysError{Keys: []string{key}}
+		}
+		return c.GetString(key), nil
+	}, func(a, b string) bool { return a == b })
+	if err != nil {
+		return nil, err
+	}
+	return &RotatingPassword{r: r}, nil
+}
+
+// Password returns the current password.
+func (p *RotatingPassword) Password() string {
+	return p.r.get()
+}
+
+// OnRotate calls fn with the new password every time it changes, on the goroutine
+// making the change.
+func (p *RotatingPassword) OnRotate(fn func(password string)) {
+	p.r.onRotate(fn)
+}
+
+// Close stops following the changes of the password.
+func (p *RotatingPassword) Close() {
+	p.r.cancel()
+}
+
+// RotatingCertificate hands out the current TLS certificate read from the PEM encoded
+// {prefix}.cert and {prefix}.key keys, eg: mounted from a Kubernetes Secret, as it
+// rotates. Create it with NewRotatingCertificate.
+type RotatingCertificate struct {
+	r *rotating[*tls.Certificate]
+}
+
+// NewRotatingCertificate returns the certificate and private key held by prefix.cert
+// and prefix.key, parsed again whenever either changes. A new pair that doesn't parse,
+// eg: while only one of the keys was updated, is logged and reported by Err, and the
+// previous certificate is kept. The initial pair must parse.
+func NewRotatingCertificate(c *GConfig, prefix string) (*RotatingCertificate, error) {
+	certKey, keyKey := prefix+".cert", prefix+".key"
+	r, err := newRotating(c, prefix, func() (*tls.Certificate, error) {
+		var missing []string
+		for _, k := range []string{certKey, keyKey} {
+			if !c.Exists(k) {
+				missing = append(missing, k)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, &MissingKeysError{Keys: missing}
+		}
+		cert, err := tls.X509KeyPair([]byte(c.GetString(certKey)), []byte(c.GetString(keyKey)))
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid certificate in keys %s and %s", certKey, keyKey)
+		}
+		return &cert, nil
+	}, func(a, b *tls.Certificate) bool {
+		return len(a.Certificate) > 0 && len(b.Certificate) > 0 && bytes.Equal(a.Certificate[0], b.Certificate[0])
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RotatingCertificate{r: r}, nil
+}
+
+// Certificate returns the current certificate.
+func (rc *RotatingCertificate) Certificate() *tls.Certificate {
+	return rc.r.get()
+}
+
+// GetCertificate returns the current certificate, for tls.Config.GetCertificate, so
+// servers present the new certificate to new connections as soon as it rotates.
+func (rc *RotatingCertificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return rc.r.get(), nil
+}
+
+// GetClientCertificate returns the current certificate, for
+// tls.Config.GetClientCertificate.
+func (rc *RotatingCertificate) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return rc.r.get(), nil
+}
+
+// OnRotate calls fn with the new certificate every time it changes, on the goroutine
+// making the change.
+func (rc *RotatingCertificate) OnRotate(fn func(*tls.Certificate)) {
+	rc.r.onRotate(fn)
+}
+
+// Err returns the error parsing the last change of the keys, nil if it was valid.
+func (rc *RotatingCertificate) Err() error {
+	rc.r.mu.RLock()
+	defer rc.r.mu.RUnlock()
+	return rc.r.err
+}
+
+// Close stops following the changes of the certificate.
+func (rc *RotatingCertificate) Close() {
+	rc.r.cancel()
+}
@@ -0,0 +1,103 @@
+package gconfig
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+)
+
+// testCertificate returns a PEM encoded self-signed certificate for name and its key.
+func testCertificate(t *testing.T, name string) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestRotatingDBPassword(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"db.password": "first"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRotatingDBPassword(gcg, "db.missing"); err == nil {
+		t.Error("A missing password key should fail")
+	}
+
+	p, err := NewRotatingDBPassword(gcg, "db.password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rotated []string
+	p.OnRotate(func(password string) { rotated = append(rotated, password) })
+
+	gcg.Set("db.password", "second")
+	gcg.Set("db.password", "second")
+	if pw := p.Password(); pw != "second" {
+		t.Errorf("Expected the rotated password but got %s", pw)
+	}
+	if len(rotated) != 1 || rotated[0] != "second" {
+		t.Errorf("OnRotate should be called once per change but got %v", rotated)
+	}
+
+	p.Close()
+	gcg.Set("db.password", "third")
+	if pw := p.Password(); pw != "second" {
+		t.Errorf("A closed password shouldn't rotate but got %s", pw)
+	}
+}
+
+func TestRotatingCertificate(t *testing.T) {
+	cert, key := testCertificate(t, "first")
+	gcg, err := NewFromMap(map[string]string{"server.tls.cert": cert, "server.tls.key": key}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, err := NewRotatingCertificate(gcg, "server.tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	first := rc.Certificate()
+	var rotations int
+	rc.OnRotate(func(*tls.Certificate) { rotations++ })
+
+	// only the certificate changed so far: the pair doesn't match and is kept
+	cert2, key2 := testCertificate(t, "second")
+	gcg.Set("server.tls.cert", cert2)
+	if rc.Err() == nil || rc.Certificate() != first {
+		t.Error("An invalid pair should be reported and the previous certificate kept")
+	}
+
+	gcg.Set("server.tls.key", key2)
+	c, _ := rc.GetCertificate(nil)
+	if rc.Err() != nil || c == first {
+		t.Errorf("The certificate should rotate once the pair is valid, error %v", rc.Err())
+	}
+	if rotations != 1 {
+		t.Errorf("OnRotate should be called once but was called %d times", rotations)
+	}
+}
@@ -0,0 +1,53 @@
+package gconfig
+
+import (
+	s "strings"
+	"time"
+)
+
+// Suffixes of the entries scheduling a key, holding RFC 3339 timestamps. A key with an
+// activateAt entry is undefined until then, and one with a deactivateAt entry is
+// undefined from then on, eg: to stage a new value ahead of a cutover, along with the
+// value served until then in its default entry, see DefaultKeySuffix:
+//
+//	db.url=postgres://db-new/app
+//	db.url.activateAt=2026-11-01T02:00:00Z
+//	db.url.default=postgres://db-old/app
+//
+// Schedules are checked on every read, so values switch at the exact moment, without a
+// reload. Subscribers of OnChange aren't notified of the switch. Malformed timestamps are
+// ignored and reported by Warnings.
+const (
+	ActivateAtSuffix   = ".activateAt"
+	DeactivateAtSuffix = ".deactivateAt"
+)
+
+// now is the clock schedules are checked against, replaced in tests.
+var now = time.Now
+
+// isScheduleKey reports whether key schedules another key.
+func isScheduleKey(key string) bool {
+	return s.HasSuffix(key, ActivateAtSuffix) || s.HasSuffix(key, DeactivateAtSuffix)
+}
+
+// active reports whether the schedule of key, if any, makes it defined at t.
+func (vs *values) active(key string, t time.Time) bool {
+	if at, ok := vs.scheduledAt(key + ActivateAtSuffix); ok && t.Before(at) {
+		return false
+	}
+	if at, ok := vs.scheduledAt(key + DeactivateAtSuffix); ok && !t.Before(at) {
+		return false
+	}
+	return true
+}
+
+// scheduledAt returns the timestamp held by the schedule entry key, if it's defined and
+// well formed.
+func (vs *values) scheduledAt(key string) (time.Time, bool) {
+	raw, ok := vs.lookup(key).(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, s.TrimSpace(raw))
+	return t, err == nil
+}
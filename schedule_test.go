@@ -0,0 +1,66 @@
+package gconfig
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	defer func(f func() time.Time) { now = f }(now)
+	cutover := time.Date(2026, 11, 1, 2, 0, 0, 0, time.UTC)
+	now = func() time.Time { return cutover.Add(-time.Second) }
+
+	gcg, err := NewFromMap(map[string]string{
+		"db.url":                   "postgres://db-new/app",
+		"db.url.activateAt":        "2026-11-01T02:00:00Z",
+		"db.url.default":           "postgres://db-old/app",
+		"promo.code":               "SUMMER",
+		"promo.code.deactivateAt":  "2026-11-01T02:00:00Z",
+		"banner.text":              "Maintenance tonight",
+		"banner.text.deactivateAt": "tomorrow",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if v := gcg.GetString("db.url"); v != "postgres://db-old/app" {
+		t.Errorf("Before the cutover db.url should read its default but got %s", v)
+	}
+	if !gcg.Exists("promo.code") || gcg.GetString("promo.code") != "SUMMER" {
+		t.Error("promo.code should be defined before it's deactivated")
+	}
+	if _, ok := gcg.AllSettings()["db.url"]; ok {
+		t.Error("A key that isn't active yet should not be listed")
+	}
+
+	now = func() time.Time { return cutover }
+	if v := gcg.GetString("db.url"); v != "postgres://db-new/app" {
+		t.Errorf("From the cutover db.url should read the staged value but got %s", v)
+	}
+	if gcg.Exists("promo.code") {
+		t.Error("promo.code should be undefined once deactivated")
+	}
+	if _, err := Get[string](gcg, "promo.code"); err == nil {
+		t.Error("Get should report a deactivated key as missing")
+	}
+	if _, ok := gcg.AllSettings()["db.url"]; !ok {
+		t.Error("An active key should be listed")
+	}
+
+	if v := gcg.GetString("banner.text"); v != "Maintenance tonight" {
+		t.Errorf("A malformed schedule should be ignored but got %q", v)
+	}
+	var found bool
+	for _, w := range gcg.Warnings() {
+		found = found || w.Kind == WarningInvalidSchedule && w.Key == "banner.text.deactivateAt"
+	}
+	if !found {
+		t.Error("The malformed schedule should be reported by Warnings")
+	}
+}
+
+func TestLintSchedule(t *testing.T) {
+	if w := DefaultLintRules().check("db.url.activateAt", position{}); len(w) != 0 {
+		t.Errorf("Schedule entries should not break the naming conventions but got %v", w)
+	}
+}
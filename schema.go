@@ -0,0 +1,246 @@
+package gconfig
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	s "strings"
+	"time"
+)
+
+// Type is the expected type of a configuration value in a Schema.
+type Type int
+
+const (
+	// TypeString accepts any value
+	TypeString Type = iota
+	// TypeInt accepts values parsed by strconv.Atoi
+	TypeInt
+	// TypeFloat accepts values parsed by strconv.ParseFloat
+	TypeFloat
+	// TypeBool accepts values parsed by strconv.ParseBool
+	TypeBool
+	// TypeDuration accepts values parsed by time.ParseDuration
+	TypeDuration
+	// TypeURL accepts absolute URLs with a scheme and host
+	TypeURL
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeInt:
+		return "int"
+	case TypeFloat:
+		return "float"
+	case TypeBool:
+		return "bool"
+	case TypeDuration:
+		return "duration"
+	case TypeURL:
+		return "url"
+	}
+	return "string"
+}
+
+// Rule describes the constraints a single key must satisfy. Min and Max bound the
+// numeric value of int and float keys and the length of string keys; leave them
+// nil for no bound.
+type Rule struct {
+	Type     Type
+	Required bool
+	Min, Max *float64
+	// Enum lists the allowed values, if not empty.
+	Enum []string
+	// Pattern is a regular expression the whole value must match, if not empty.
+	Pattern string
+	// Tags group keys for cross-cutting tooling, eg: "db" or "pii".
+	Tags []string
+	// Expr is an expression, see GConfig.Eval, that must evaluate to true. The value
+	// being checked is available as value, eg: "value >= pool.min && value % 2 == 0".
+	Expr string
+}
+
+// Schema maps configuration keys to the rule they must satisfy.
+type Schema map[string]Rule
+
+// KeysByTag returns the sorted schema keys carrying the given tag.
+func (c *GConfig) KeysByTag(tag string) []string {
+	var keys []string
+	for k, rule := range c.schema {
+		if rule.hasTag(tag) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// GetGroup returns the resolved values of the defined keys carrying the given tag.
+func (c *GConfig) GetGroup(tag string) map[string]string {
+	group := make(map[string]string)
+	for _, k := range c.KeysByTag(tag) {
+		if c.Exists(k) {
+			group[k] = c.getStringValue(k)
+		}
+	}
+	return group
+}
+
+func (r Rule) hasTag(tag string) bool {
+	for _, t := range r.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Bound is a helper for filling in Rule.Min and Rule.Max.
+func Bound(v float64) *float64 {
+	return &v
+}
+
+// Violation is a single key that doesn't satisfy its Rule. The value of sensitive keys
+// is masked, in Value and in Message.
+type Violation struct {
+	Key     string
+	Value   string
+	Message string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Key, v.Message)
+}
+
+// ValidationError lists every violation found by Validate.
+type ValidationError struct {
+	Violations []Violation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.String()
+	}
+	return fmt.Sprintf("Configuration is invalid: %s", s.Join(msgs, "; "))
+}
+
+// Validate checks the loaded configuration against the schema registered with
+// WithSchema and returns a *ValidationError reporting all the violations at once.
+func (c *GConfig) Validate() error {
+	return c.validateSchema(c.schema)
+}
+
+func (c *GConfig) validateSchema(schema Schema) error {
+	keys := make([]string, 0, len(schema))
+	for k := range schema {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var violations []Violation
+	for _, k := range keys {
+		rule := schema[k]
+		if !c.Exists(k) {
+			if rule.Required {
+				violations = append(violations, Violation{Key: k, Message: "is required"})
+			}
+			continue
+		}
+
+		v := c.replaceSysVars(k)
+		msg := rule.check(v)
+		if msg == "" && rule.Expr != "" {
+			msg = c.checkExpr(rule.Expr, v)
+		}
+		if msg != "" {
+			violations = append(violations, Violation{Key: k, Value: c.mask(k, v), Message: c.redactValue(k, v, msg)})
+		}
+	}
+
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// checkExpr evaluates a rule expression with value bound to v.
+func (c *GConfig) checkExpr(expr, v string) string {
+	res, err := evalExpr(expr, c.exprVars(map[string]interface{}{"value": v}, nil), c.lookupEnv)
+	if err != nil {
+		return fmt.Sprintf("invalid expression %q: %s", expr, err)
+	}
+	if ok, _ := res.(bool); !ok {
+		return fmt.Sprintf("%q does not satisfy %s", v, expr)
+	}
+	return ""
+}
+
+// check returns a description of why v breaks the rule, or an empty string if it doesn't.
+func (r Rule) check(v string) string {
+	var n float64
+	switch r.Type {
+	case TypeInt:
+		i, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Sprintf("%q is not an int", v)
+		}
+		n = float64(i)
+	case TypeFloat:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Sprintf("%q is not a float", v)
+		}
+		n = f
+	case TypeBool:
+		if _, err := strconv.ParseBool(v); err != nil {
+			return fmt.Sprintf("%q is not a bool", v)
+		}
+	case TypeDuration:
+		if _, err := time.ParseDuration(v); err != nil {
+			return fmt.Sprintf("%q is not a duration", v)
+		}
+	case TypeURL:
+		u, err := url.Parse(v)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Sprintf("%q is not an absolute URL", v)
+		}
+	default:
+		n = float64(len(v))
+	}
+
+	ranged := r.Type == TypeInt || r.Type == TypeFloat || r.Type == TypeString
+	if ranged && r.Min != nil && n < *r.Min {
+		return fmt.Sprintf("%q is below the minimum %v", v, *r.Min)
+	}
+	if ranged && r.Max != nil && n > *r.Max {
+		return fmt.Sprintf("%q is above the maximum %v", v, *r.Max)
+	}
+
+	if len(r.Enum) > 0 {
+		allowed := false
+		for _, e := range r.Enum {
+			if e == v {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("%q is not one of %s", v, s.Join(r.Enum, ", "))
+		}
+	}
+
+	if r.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + r.Pattern + ")$")
+		if err != nil {
+			return fmt.Sprintf("invalid pattern %q: %s", r.Pattern, err)
+		}
+		if !re.MatchString(v) {
+			return fmt.Sprintf("%q does not match %s", v, r.Pattern)
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,74 @@
+package gconfig
+
+import (
+	"os"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := Schema{
+		"app.url":               {Type: TypeURL, Required: true},
+		"app.version":           {Type: TypeFloat, Min: Bound(2)},
+		"connection_pool_count": {Type: TypeInt, Min: Bound(1), Max: Bound(10)},
+		"shipping_charge":       {Type: TypeInt},
+		"app.name":              {Enum: []string{"gconfig test", "gconfig dev profile"}},
+		"server.port":           {Type: TypeInt, Required: true},
+		"server.host":           {Pattern: `[a-z.]+`},
+	}
+	gcg, err := Load(WithPath(wd+"/config"), WithProfile("dev"), WithSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verr, ok := gcg.Validate().(*ValidationError)
+	if !ok {
+		t.Fatalf("Expected *ValidationError but got %v", gcg.Validate())
+	}
+
+	expected := map[string]bool{"app.version": true, "server.port": true, "shipping_charge": true}
+	if len(verr.Violations) != len(expected) {
+		t.Errorf("Expected %d violations but got %v", len(expected), verr.Violations)
+	}
+	for _, v := range verr.Violations {
+		if !expected[v.Key] {
+			t.Errorf("Unexpected violation %s", v)
+		}
+	}
+
+	_, err = Load(WithPath(wd+"/config"), WithProfile("dev"), WithSchema(schema), StrictMode())
+	if _, ok := err.(*ValidationError); !ok {
+		t.Errorf("Strict load should fail validation but got %v", err)
+	}
+}
+
+func TestKeysByTag(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := Schema{
+		"app.url":               {Type: TypeURL, Tags: []string{"web"}},
+		"connection_pool_count": {Type: TypeInt, Tags: []string{"db"}},
+		"db.password":           {Tags: []string{"db", "pii"}},
+	}
+	gcg, err := Load(WithPath(wd+"/config"), WithProfile("dev"), WithSchema(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys := gcg.KeysByTag("db")
+	if len(keys) != 2 || keys[0] != "connection_pool_count" || keys[1] != "db.password" {
+		t.Errorf("Unexpected keys for tag db: %v", keys)
+	}
+
+	group := gcg.GetGroup("db")
+	if len(group) != 1 || group["connection_pool_count"] != "3" {
+		t.Errorf("Unexpected group for tag db: %v", group)
+	}
+}
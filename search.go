@@ -0,0 +1,31 @@
+package gconfig
+
+import (
+	"regexp"
+	s "strings"
+)
+
+// FindKeys returns the sorted keys whose value contains valuePattern, either as
+// written in the properties file or after placeholders are resolved. It's meant for
+// answering "which keys point at this endpoint or credential?" across large configs.
+func (c *GConfig) FindKeys(valuePattern string) []string {
+	return c.matchValues(func(v string) bool {
+		return s.Contains(v, valuePattern)
+	})
+}
+
+// Grep returns the sorted keys whose raw or resolved value matches re.
+func (c *GConfig) Grep(re *regexp.Regexp) []string {
+	return c.matchValues(re.MatchString)
+}
+
+func (c *GConfig) matchValues(match func(string) bool) []string {
+	var found []string
+	for _, k := range c.keys() {
+		raw, _ := c.getValue(k).(string)
+		if match(raw) || match(c.replaceSysVars(k)) {
+			found = append(found, k)
+		}
+	}
+	return found
+}
@@ -0,0 +1,33 @@
+package gconfig
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFindKeys(t *testing.T) {
+	os.Setenv("DATA_DASHBOARD_ENDPOINT", "http://dataDashTest")
+	defer os.Unsetenv("DATA_DASHBOARD_ENDPOINT")
+	gcg := setup("dev", t)
+
+	keys := gcg.FindKeys("github.com/narup")
+	if strings.Join(keys, ",") != "app.url" {
+		t.Errorf("Unexpected keys for value github.com/narup: %v", keys)
+	}
+
+	keys = gcg.FindKeys("dataDashTest")
+	if strings.Join(keys, ",") != "myEnv.variable.listwithDefault" {
+		t.Errorf("Resolved values should be searched, got %v", keys)
+	}
+}
+
+func TestGrep(t *testing.T) {
+	gcg := setup("dev", t)
+
+	keys := gcg.Grep(regexp.MustCompile(`^\d+(\.\d+)?$`))
+	if strings.Join(keys, ",") != "app.version,connection_pool_count,shipping_charge" {
+		t.Errorf("Unexpected keys for numeric values: %v", keys)
+	}
+}
@@ -0,0 +1,33 @@
+package gconfig
+
+import "sync"
+
+// once caches the configuration loaded by LoadOnce for the life of the process.
+var once struct {
+	sync.Mutex
+	c *GConfig
+}
+
+// LoadOnce loads the configuration on its first successful call and returns the same
+// GConfig on every later call, ignoring their options. It's meant for serverless
+// functions: call it from the init phase, or the handler, and warm invocations reuse
+// the configuration loaded by the cold start instead of reading the files again.
+// Failed loads aren't cached, so the next call retries.
+//
+// Building with -tags gconfig_tiny keeps net/http out of the binary for smaller cold
+// starts: it leaves out the gconfig+http and gconfig+https sources, ServiceHandler,
+// Handler, ActuatorHandler, Middleware, Var and StatsVar.
+func LoadOnce(opts ...Option) (*GConfig, error) {
+	once.Lock()
+	defer once.Unlock()
+	if once.c != nil {
+		return once.c, nil
+	}
+
+	c, err := Load(opts...)
+	if err != nil {
+		return c, err
+	}
+	once.c = c
+	return c, nil
+}
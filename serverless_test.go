@@ -0,0 +1,31 @@
+package gconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOnce(t *testing.T) {
+	defer func() { once.c = nil }()
+
+	dir := t.TempDir()
+	if _, err := LoadOnce(WithPath(dir)); err == nil {
+		t.Fatalf("Loading an empty directory should fail")
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "app.name=cold start\n")
+	first, err := LoadOnce(WithPath(dir))
+	if err != nil {
+		t.Fatalf("Failed loads should not be cached: %s", err)
+	}
+
+	os.Remove(filepath.Join(dir, StandardPropFileName))
+	second, err := LoadOnce(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != second || second.GetString("app.name") != "cold start" {
+		t.Errorf("Warm calls should reuse the configuration loaded by the first call")
+	}
+}
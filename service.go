@@ -0,0 +1,101 @@
+package gconfig
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// SchemaVersionKey holds the schema version of a configuration served by ServiceHandler,
+// 1 when it isn't set. Bump it when keys are renamed or change meaning, and register a
+// Migration so services that haven't been redeployed keep reading the version they know.
+const SchemaVersionKey = "gconfig.schema.version"
+
+// Headers negotiating the schema version between ServiceHandler and ServiceSource. A
+// client sends the newest version it understands and the oldest it accepts; the server
+// answers with the version it served.
+const (
+	SchemaVersionHeader    = "Gconfig-Schema-Version"
+	MinSchemaVersionHeader = "Gconfig-Min-Schema-Version"
+)
+
+// Migration down-converts the settings of a schema version to the previous version, eg:
+// renaming db.url back to db.uri. It may modify settings in place.
+type Migration func(settings map[string]string) (map[string]string, error)
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = make(map[int]Migration)
+)
+
+// RegisterMigration registers the Migration down-converting settings of schema version to
+// version-1. It panics if the version is registered twice, lower than 2 or the migration
+// is nil.
+func RegisterMigration(version int, down Migration) {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	if down == nil || version < 2 {
+		panic(fmt.Sprintf("gconfig: RegisterMigration called with an invalid migration for version %d", version))
+	}
+	if _, dup := migrations[version]; dup {
+		panic(fmt.Sprintf("gconfig: RegisterMigration called twice for version %d", version))
+	}
+	migrations[version] = down
+}
+
+// ServedConfig is the document served by ServiceHandler.
+type ServedConfig struct {
+	Version  int               `json:"version"`
+	Profile  string            `json:"profile"`
+	Settings map[string]string `json:"settings"`
+}
+
+// SchemaVersion returns the schema version of the configuration, see SchemaVersionKey.
+func (c *GConfig) SchemaVersion() int {
+	if v, err := strconv.Atoi(c.replaceSysVars(SchemaVersionKey)); err == nil && v > 0 {
+		return v
+	}
+	return 1
+}
+
+// Serve returns the resolved settings of the configuration down-converted to schema
+// version, or to its own version when version is zero. Sensitive keys are left out, and
+// their values scrubbed from the values embedding them through placeholders, eg: a URL
+// built with ${db.password}: secrets are better distributed by a secrets manager than by
+// a configuration service.
+// It fails when the configuration is older than minVersion or when a migration down to
+// version is missing.
+func (c *GConfig) Serve(version, minVersion int) (ServedConfig, error) {
+	cur := c.SchemaVersion()
+	if cur < minVersion {
+		return ServedConfig{}, fmt.Errorf("Schema version %d is older than the minimum version %d requested", cur, minVersion)
+	}
+	if version <= 0 || version > cur {
+		version = cur
+	}
+	if version < minVersion {
+		return ServedConfig{}, fmt.Errorf("Requested schema version %d is older than the minimum version %d", version, minVersion)
+	}
+
+	settings := make(map[string]string)
+	for _, k := range c.keys() {
+		if k != SchemaVersionKey && !c.IsSensitive(k) {
+			settings[k] = c.redact(c.mask(k, c.replaceSysVars(k)))
+		}
+	}
+	for v := cur; v > version; v-- {
+		migrationsMu.RLock()
+		down, ok := migrations[v]
+		migrationsMu.RUnlock()
+		if !ok {
+			return ServedConfig{}, fmt.Errorf("No migration registered from schema version %d to %d", v, v-1)
+		}
+		var err error
+		if settings, err = down(settings); err != nil {
+			return ServedConfig{}, errors.Wrapf(err, "Error migrating from schema version %d to %d", v, v-1)
+		}
+	}
+	return ServedConfig{Version: version, Profile: c.Profile, Settings: settings}, nil
+}
@@ -0,0 +1,149 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// ServiceHandler returns an http.Handler serving the configuration to other services as
+// a JSON ServedConfig, see Serve. Clients pick the schema version with the
+// Gconfig-Schema-Version and Gconfig-Min-Schema-Version headers, so the configuration can
+// move to a new schema before every client is redeployed; a version that can't be served
+// is answered with 406 Not Acceptable. ServiceSource is the matching client.
+//
+// The handler doesn't authenticate its clients: even without secrets, the configuration
+// tells a lot about a deployment, so serve it behind authentication, eg: mutual TLS or a
+// middleware checking a token, or on an internal network only.
+//
+//	http.Handle("/config", cfg.ServiceHandler())
+func (c *GConfig) ServiceHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		version, err := headerInt(r, SchemaVersionHeader)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		minVersion, err := headerInt(r, MinSchemaVersionHeader)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		served, err := c.Serve(version, minVersion)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotAcceptable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(SchemaVersionHeader, strconv.Itoa(served.Version))
+		json.NewEncoder(w).Encode(served)
+	})
+}
+
+func headerInt(r *http.Request, name string) (int, error) {
+	h := r.Header.Get(name)
+	if h == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(h)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("Invalid %s header %q", name, h)
+	}
+	return v, nil
+}
+
+// ServiceSource is a Source reading the configuration served by the ServiceHandler of
+// another process. It's also registered for the gconfig+http and gconfig+https schemes,
+// taking the versions from the query, eg:
+//
+//	gconfig.WithSourceURL("gconfig+https://config.internal/config?version=3&min_version=2")
+//
+// When the server can't serve an acceptable version Load fails, so a Reload keeps the
+// values already loaded rather than switching to settings the client can't read.
+type ServiceSource struct {
+	URL string
+	// Version is the newest schema version the client understands, any when zero.
+	Version int
+	// MinVersion is the oldest schema version the client accepts.
+	MinVersion int
+	// Client is the HTTP client used, http.DefaultClient when nil.
+	Client *http.Client
+}
+
+// Name returns the URL of the service.
+func (src *ServiceSource) Name() string {
+	return src.URL
+}
+
+// Load fetches the settings of the negotiated schema version.
+func (src *ServiceSource) Load(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src.URL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if src.Version > 0 {
+		req.Header.Set(SchemaVersionHeader, strconv.Itoa(src.Version))
+	}
+	if src.MinVersion > 0 {
+		req.Header.Set(MinSchemaVersionHeader, strconv.Itoa(src.MinVersion))
+	}
+
+	client := src.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Configuration service %s answered %s", src.URL, resp.Status)
+	}
+
+	var served ServedConfig
+	if err := json.NewDecoder(resp.Body).Decode(&served); err != nil {
+		return nil, errors.Wrapf(err, "Error decoding the configuration served by %s", src.URL)
+	}
+	if served.Version < src.MinVersion {
+		return nil, fmt.Errorf("Configuration service %s served schema version %d, older than %d", src.URL, served.Version, src.MinVersion)
+	}
+	if src.Version > 0 && served.Version > src.Version {
+		return nil, fmt.Errorf("Configuration service %s served schema version %d, newer than %d", src.URL, served.Version, src.Version)
+	}
+	return served.Settings, nil
+}
+
+func init() {
+	for _, scheme := range []string{"http", "https"} {
+		scheme := scheme
+		RegisterSource("gconfig+"+scheme, func(location string) (Source, error) {
+			u, err := url.Parse(scheme + "://" + location)
+			if err != nil {
+				return nil, err
+			}
+			src := &ServiceSource{}
+			q := u.Query()
+			for name, v := range map[string]*int{"version": &src.Version, "min_version": &src.MinVersion} {
+				if q.Get(name) == "" {
+					continue
+				}
+				if *v, err = strconv.Atoi(q.Get(name)); err != nil {
+					return nil, fmt.Errorf("Invalid %s %q", name, q.Get(name))
+				}
+				q.Del(name)
+			}
+			u.RawQuery = q.Encode()
+			src.URL = u.String()
+			return src, nil
+		})
+	}
+}
@@ -0,0 +1,82 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServiceSchemaVersions(t *testing.T) {
+	defer func(m map[int]Migration) { migrations = m }(migrations)
+	migrations = map[int]Migration{
+		// version 3 renamed db.uri to db.url
+		3: func(settings map[string]string) (map[string]string, error) {
+			settings["db.uri"] = settings["db.url"]
+			delete(settings, "db.url")
+			return settings, nil
+		},
+	}
+
+	server, err := NewFromMap(map[string]string{
+		SchemaVersionKey: "3",
+		"db.url":         "postgres://db",
+		"db.password":    "hunter2",
+		"app.name":       "${app.base}-svc",
+		"app.base":       "gconfig",
+	}, "prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(server.ServiceHandler())
+	defer ts.Close()
+
+	latest, err := (&ServiceSource{URL: ts.URL}).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest["db.url"] != "postgres://db" || latest["app.name"] != "gconfig-svc" {
+		t.Errorf("Expected the resolved settings of version 3 but got %v", latest)
+	}
+	if _, ok := latest["db.password"]; ok {
+		t.Error("Sensitive keys should not be served")
+	}
+
+	old, err := (&ServiceSource{URL: ts.URL, Version: 2}).Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := old["db.url"]; old["db.uri"] != "postgres://db" || ok {
+		t.Errorf("Expected db.url down-converted to db.uri but got %v", old)
+	}
+
+	if _, err := (&ServiceSource{URL: ts.URL, Version: 1}).Load(context.Background()); err == nil || !strings.Contains(err.Error(), "406") {
+		t.Errorf("Expected 406 without a migration to version 1 but got %v", err)
+	}
+	if _, err := (&ServiceSource{URL: ts.URL, MinVersion: 4}).Load(context.Background()); err == nil {
+		t.Error("A server older than the minimum version should be rejected")
+	}
+
+	src, err := OpenSource("gconfig+" + ts.URL + "?version=2&min_version=2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := NewFromMap(nil, "", WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if client.GetString("db.uri") != "postgres://db" {
+		t.Errorf("Expected db.uri from the configuration service but got %q", client.GetString("db.uri"))
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(SchemaVersionHeader, "two")
+	rec := httptest.NewRecorder()
+	server.ServiceHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a malformed version but got %d", rec.Code)
+	}
+}
@@ -0,0 +1,39 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegisterMigrationTwice(t *testing.T) {
+	defer func(m map[int]Migration) { migrations = m }(migrations)
+	migrations = make(map[int]Migration)
+	down := func(settings map[string]string) (map[string]string, error) { return settings, nil }
+	RegisterMigration(2, down)
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a migration twice should panic")
+		}
+	}()
+	RegisterMigration(2, down)
+}
+
+func TestServeRedactsEmbeddedSecrets(t *testing.T) {
+	c, err := NewFromMap(map[string]string{
+		"db.password": "hunter22",
+		"db.url":      "postgres://app:${db.password}@db/app",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served, err := c.Serve(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := served.Settings["db.url"]; strings.Contains(u, "hunter22") || !strings.Contains(u, MaskedValue) {
+		t.Errorf("A secret embedded through a placeholder should be masked but got %s", u)
+	}
+	if _, ok := served.Settings["db.password"]; ok {
+		t.Error("Sensitive keys shouldn't be served")
+	}
+}
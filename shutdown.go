@@ -0,0 +1,210 @@
+package gconfig
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"reflect"
+	s "strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ShutdownPolicy is the shutdown policy read by NewShutdown.
+type ShutdownPolicy struct {
+	// Signals start the shutdown, SIGINT and SIGTERM by default.
+	Signals []os.Signal
+	// DrainTimeout is how long to wait once the shutdown started, while Draining is
+	// closed, before running the shutdown hooks, eg: for load balancers to stop routing
+	// requests to an instance failing its readiness probe. None by default.
+	DrainTimeout time.Duration
+	// GracePeriod is how long the shutdown hooks get to complete, 30s by default.
+	GracePeriod time.Duration
+}
+
+// signals maps the names accepted in {prefix}.signals to signals, completed with the
+// signals of the platform.
+var signals = map[string]os.Signal{
+	"INT":  os.Interrupt,
+	"TERM": syscall.SIGTERM,
+}
+
+// Shutdown coordinates the graceful shutdown of a service, following the policy held by
+// the keys under a prefix, eg: shutdown:
+//
+//	shutdown.signals=SIGTERM,SIGINT
+//	shutdown.drain-timeout=5s
+//	shutdown.grace-period=30s
+//
+// Changes to the keys apply to the next shutdown. Create it with NewShutdown.
+type Shutdown struct {
+	r *rotating[ShutdownPolicy]
+
+	sig      chan os.Signal
+	trigger  chan struct{}
+	draining chan struct{}
+	once     sync.Once
+
+	// run runs the shutdown once, whatever the number of calls to Wait, err being
+	// its result
+	run sync.Once
+	err error
+
+	mu      sync.Mutex // guards hooks and stopped
+	hooks   []func(ctx context.Context) error
+	stopped bool // signals are no longer listened to
+}
+
+// NewShutdown returns a Shutdown following the policy held by the keys under prefix. It
+// listens to the policy's signals right away. The initial policy must be valid: durations
+// parse and signals are known, INT, TERM and on Unix HUP, QUIT, USR1 and USR2, with or
+// without the SIG prefix.
+func NewShutdown(c *GConfig, prefix string) (*Shutdown, error) {
+	r, err := newRotating(c, prefix, func() (ShutdownPolicy, error) {
+		return readShutdownPolicy(c, prefix)
+	}, func(a, b ShutdownPolicy) bool { return reflect.DeepEqual(a, b) })
+	if err != nil {
+		return nil, err
+	}
+
+	sd := &Shutdown{r: r, sig: make(chan os.Signal, 1), trigger: make(chan struct{}), draining: make(chan struct{})}
+	signal.Notify(sd.sig, r.get().Signals...)
+	r.onRotate(func(p ShutdownPolicy) {
+		sd.mu.Lock()
+		defer sd.mu.Unlock()
+		if !sd.stopped {
+			signal.Stop(sd.sig)
+			signal.Notify(sd.sig, p.Signals...)
+		}
+	})
+	return sd, nil
+}
+
+func readShutdownPolicy(c *GConfig, prefix string) (ShutdownPolicy, error) {
+	p := ShutdownPolicy{Signals: []os.Signal{os.Interrupt, syscall.SIGTERM}, GracePeriod: 30 * time.Second}
+	for key, d := range map[string]*time.Duration{prefix + ".drain-timeout": &p.DrainTimeout, prefix + ".grace-period": &p.GracePeriod} {
+		if !c.Exists(key) {
+			continue
+		}
+		v, err := Get[time.Duration](c, key)
+		if err != nil {
+			return p, err
+		}
+		*d = v
+	}
+
+	key := prefix + ".signals"
+	if !c.Exists(key) {
+		return p, nil
+	}
+	p.Signals = nil
+	for _, name := range s.Split(c.GetString(key), ",") {
+		name = s.TrimPrefix(s.ToUpper(s.TrimSpace(name)), "SIG")
+		if name == "" {
+			continue
+		}
+		sig, ok := signals[name]
+		if !ok {
+			return p, &TypeError{Key: key, Type: "os.Signal", Err: fmt.Errorf("unknown signal %s", name)}
+		}
+		p.Signals = append(p.Signals, sig)
+	}
+	return p, nil
+}
+
+// Policy returns the current shutdown policy.
+func (sd *Shutdown) Policy() ShutdownPolicy {
+	return sd.r.get()
+}
+
+// OnShutdown registers a hook run by Wait. Hooks run one after the other, the last
+// registered first as deferred calls do, so a server registered after the database it
+// uses stops before the database is closed. ctx is done when the grace period is over.
+func (sd *Shutdown) OnShutdown(fn func(ctx context.Context) error) {
+	sd.mu.Lock()
+	sd.hooks = append(sd.hooks, fn)
+	sd.mu.Unlock()
+}
+
+// Draining returns a channel closed once the shutdown started, eg: to fail readiness
+// probes during the drain timeout.
+func (sd *Shutdown) Draining() <-chan struct{} {
+	return sd.draining
+}
+
+// Shutdown starts the shutdown as a signal would, eg: on a fatal error.
+func (sd *Shutdown) Shutdown() {
+	sd.once.Do(func() { close(sd.trigger) })
+}
+
+// Wait blocks until a signal of the policy is received or Shutdown is called, or ctx is
+// done, then closes Draining, waits for the drain timeout, cut short once ctx is done,
+// and runs the shutdown hooks. It returns the first error of a hook, or an error if they
+// didn't complete within the grace period, in which case the hooks still running are
+// abandoned. The shutdown runs once: other calls wait for it and return its result.
+func (sd *Shutdown) Wait(ctx context.Context) error {
+	select {
+	case <-sd.sig:
+	case <-sd.trigger:
+	case <-ctx.Done():
+	}
+	sd.stop()
+	sd.run.Do(func() {
+		close(sd.draining)
+		sd.err = sd.shutdown(ctx)
+	})
+	return sd.err
+}
+
+// shutdown waits for the drain timeout then runs the hooks.
+func (sd *Shutdown) shutdown(ctx context.Context) error {
+	p := sd.Policy()
+	drain := time.NewTimer(p.DrainTimeout)
+	select {
+	case <-drain.C:
+	case <-ctx.Done():
+		drain.Stop()
+	}
+
+	sd.mu.Lock()
+	hooks := make([]func(context.Context) error, len(sd.hooks))
+	copy(hooks, sd.hooks)
+	sd.mu.Unlock()
+
+	hctx, cancel := context.WithTimeout(context.Background(), p.GracePeriod)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		var first error
+		for i := len(hooks) - 1; i >= 0; i-- {
+			if err := hooks[i](hctx); err != nil && first == nil {
+				first = errors.Wrap(err, "Shutdown hook failed")
+			}
+		}
+		done <- first
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-hctx.Done():
+		return errors.Wrapf(hctx.Err(), "Shutdown didn't complete within the grace period of %s", p.GracePeriod)
+	}
+}
+
+// Close stops listening to the signals and following the changes of the policy.
+func (sd *Shutdown) Close() {
+	sd.stop()
+	sd.r.cancel()
+}
+
+// stop stops listening to the signals, for good.
+func (sd *Shutdown) stop() {
+	sd.mu.Lock()
+	sd.stopped = true
+	signal.Stop(sd.sig)
+	sd.mu.Unlock()
+}
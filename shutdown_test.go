@@ -0,0 +1,129 @@
+package gconfig
+
+import (
+	"context"
+	"errors"
+	"os"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"shutdown.signals": "SIGTERM", "shutdown.grace-period": "1s"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := NewShutdown(gcg, "shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sd.Close()
+	want := ShutdownPolicy{Signals: []os.Signal{syscall.SIGTERM}, GracePeriod: time.Second}
+	if p := sd.Policy(); !reflect.DeepEqual(p, want) {
+		t.Errorf("Expected the policy %+v but got %+v", want, p)
+	}
+
+	gcg.Set("shutdown.drain-timeout", "10ms")
+	if p := sd.Policy(); p.DrainTimeout != 10*time.Millisecond {
+		t.Errorf("The drain timeout should follow the configuration but got %s", p.DrainTimeout)
+	}
+	gcg.Set("shutdown.signals", "SIGWHAT")
+	if p := sd.Policy(); !reflect.DeepEqual(p.Signals, want.Signals) {
+		t.Errorf("An invalid change should keep the current signals but got %v", p.Signals)
+	}
+
+	var order []string
+	sd.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "db")
+		return nil
+	})
+	sd.OnShutdown(func(ctx context.Context) error {
+		select {
+		case <-sd.Draining():
+		default:
+			t.Error("Draining should be closed before the hooks run")
+		}
+		order = append(order, "server")
+		return errors.New("server stuck")
+	})
+
+	sd.Shutdown()
+	sd.Shutdown()
+	err = sd.Wait(context.Background())
+	if err == nil || err.Error() != "Shutdown hook failed: server stuck" {
+		t.Errorf("Expected the error of the server hook but got %v", err)
+	}
+	if !reflect.DeepEqual(order, []string{"server", "db"}) {
+		t.Errorf("Hooks should run in reverse order but ran %v", order)
+	}
+
+	if err2 := sd.Wait(context.Background()); err2 != err {
+		t.Errorf("Another Wait should return the result of the shutdown but got %v", err2)
+	}
+	if len(order) != 2 {
+		t.Errorf("Hooks should run once but ran %v", order)
+	}
+	gcg.Set("shutdown.signals", "SIGINT")
+	if !sd.stopped {
+		t.Error("Signals shouldn't be listened to again once the shutdown started")
+	}
+}
+
+func TestShutdownDrainCancelled(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"shutdown.drain-timeout": "1h"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := NewShutdown(gcg, "shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sd.Close()
+	ran := false
+	sd.OnShutdown(func(ctx context.Context) error {
+		ran = true
+		return nil
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- sd.Wait(ctx) }()
+	select {
+	case err := <-done:
+		if err != nil || !ran {
+			t.Errorf("The hooks should run once the drain is cut short but got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The drain timeout should be cut short once ctx is done")
+	}
+}
+
+func TestShutdownGracePeriod(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"app.shutdown.grace-period": "20ms"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := NewShutdown(gcg, "app.shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sd.Close()
+	sd.OnShutdown(func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := sd.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the grace period to be exceeded but got %v", err)
+	}
+
+	gcg.Set("app.shutdown.signals", "INT,BOGUS")
+	if _, err := NewShutdown(gcg, "app.shutdown"); !errors.Is(err, ErrTypeMismatch) {
+		t.Errorf("An unknown signal should be reported but got %v", err)
+	}
+}
@@ -0,0 +1,12 @@
+//go:build unix
+
+package gconfig
+
+import "syscall"
+
+func init() {
+	signals["HUP"] = syscall.SIGHUP
+	signals["QUIT"] = syscall.SIGQUIT
+	signals["USR1"] = syscall.SIGUSR1
+	signals["USR2"] = syscall.SIGUSR2
+}
@@ -0,0 +1,36 @@
+//go:build unix
+
+package gconfig
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownSignal(t *testing.T) {
+	gcg, err := NewFromMap(map[string]string{"shutdown.signals": "usr1"}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sd, err := NewShutdown(gcg, "shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sd.Close()
+	gcg.Set("shutdown.signals", "SIGUSR2")
+
+	done := make(chan error, 1)
+	go func() { done <- sd.Wait(context.Background()) }()
+	syscall.Kill(os.Getpid(), syscall.SIGUSR2)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("SIGUSR2 should start the shutdown")
+	}
+}
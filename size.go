@@ -0,0 +1,76 @@
+package gconfig
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	s "strings"
+)
+
+// ByteSize is a number of bytes read from a human friendly size, eg: 10KB or 256MiB. It
+// can be used with Get and Unmarshal.
+type ByteSize int64
+
+var sizeUnits = map[string]float64{
+	"":    1,
+	"b":   1,
+	"kb":  1e3,
+	"mb":  1e6,
+	"gb":  1e9,
+	"tb":  1e12,
+	"pb":  1e15,
+	"kib": 1 << 10,
+	"mib": 1 << 20,
+	"gib": 1 << 30,
+	"tib": 1 << 40,
+	"pib": 1 << 50,
+}
+
+// ParseByteSize parses a size made of a number, possibly decimal, and an optional unit:
+// B, the decimal KB, MB, GB, TB and PB, or the binary KiB, MiB, GiB, TiB and PiB. Units
+// are case insensitive and may follow a space, eg: "1.5GB", "256 MiB" or "512".
+func ParseByteSize(v string) (ByteSize, error) {
+	v = s.TrimSpace(v)
+	i := s.IndexFunc(v, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if i < 0 {
+		i = len(v)
+	}
+	num, unit := v[:i], s.ToLower(s.TrimSpace(v[i:]))
+
+	mult, ok := sizeUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("Invalid size %q: unknown unit %q, expected B, KB, MB, GB, TB, PB or KiB, MiB, GiB, TiB, PiB", v, v[i:])
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid size %q: expected a number followed by a unit, eg: 10MB", v)
+	}
+	bytes := math.Round(f * mult)
+	if bytes >= math.MaxInt64 {
+		return 0, fmt.Errorf("Invalid size %q: it overflows an int64", v)
+	}
+	return ByteSize(bytes), nil
+}
+
+// UnmarshalText parses text with ParseByteSize.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	v, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = v
+	return nil
+}
+
+// GetSizeInBytes returns the number of bytes of the size for the given key, see
+// ParseByteSize, or an error naming the key if it's missing or malformed.
+func (c *GConfig) GetSizeInBytes(key string) (int64, error) {
+	if !c.Exists(key) {
+		return 0, &MissingKeysError{Keys: []string{key}}
+	}
+	v, err := ParseByteSize(c.getStringValue(key))
+	if err != nil {
+		return 0, &TypeError{Key: key, Type: "gconfig.ByteSize", Err: err}
+	}
+	return int64(v), nil
+}
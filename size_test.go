@@ -0,0 +1,45 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseByteSize(t *testing.T) {
+	for v, expected := range map[string]ByteSize{
+		"512": 512, "10B": 10, "10KB": 10000, "10kb": 10000, "256MiB": 256 << 20, "1.5GB": 1500000000,
+		"2 GiB": 2 << 30, " 1TB ": 1e12, "0.5KiB": 512, "1PiB": 1 << 50,
+	} {
+		if b, err := ParseByteSize(v); err != nil || b != expected {
+			t.Errorf("Size %q should be %d bytes but got %d, %v", v, expected, b, err)
+		}
+	}
+
+	for _, v := range []string{"", "MB", "10XB", "1.2.3KB", "-1KB", "10000PB"} {
+		if _, err := ParseByteSize(v); err == nil {
+			t.Errorf("Size %q should be invalid", v)
+		}
+	}
+}
+
+func TestGetSizeInBytes(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "cache.max=${CACHE_MAX:-64MiB}\nupload.max=ten\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err := gcg.GetSizeInBytes("cache.max"); err != nil || b != 64<<20 {
+		t.Errorf("Expected 64MiB but got %d, %v", b, err)
+	}
+	if _, err := gcg.GetSizeInBytes("upload.max"); err == nil || !strings.Contains(err.Error(), "upload.max") {
+		t.Errorf("Malformed sizes should fail naming the key but got %v", err)
+	}
+	if _, err := gcg.GetSizeInBytes("missing"); err == nil {
+		t.Error("Missing keys should fail")
+	}
+	if b, err := Get[ByteSize](gcg, "cache.max"); err != nil || b != 64<<20 {
+		t.Errorf("Get should parse sizes but got %d, %v", b, err)
+	}
+}
@@ -0,0 +1,72 @@
+package gconfig
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// SaveSnapshot writes the effective configuration to path, for LoadLastKnownGood to fall
+// back on at the next startup. Call it at shutdown to capture the values set or reloaded
+// since the start. Values are written with their placeholders and references unresolved,
+// so secrets resolved at runtime aren't persisted, but the file may still hold sensitive
+// values read from the sources and is only readable by its owner. The file is replaced
+// atomically.
+func (c *GConfig) SaveSnapshot(path string) error {
+	var buf bytes.Buffer
+	if err := c.Bake(&buf, true); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return errors.Wrapf(err, "Error saving configuration snapshot %s", path)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		return errors.Wrapf(err, "Error saving configuration snapshot %s", path)
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrapf(err, "Error saving configuration snapshot %s", path)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadLastKnownGood loads the configuration like Load and saves a snapshot of it to path.
+// When loading fails, eg: because a remote source is unreachable, it logs the error and
+// loads the snapshot saved by the last successful start, or SaveSnapshot, instead. The
+// snapshot replaces the files, sources and dotenv files; the environment still applies.
+// The original error is returned if there is no usable snapshot. A configuration loaded
+// from the snapshot keeps reading it on Reload; restart to retry the sources.
+func LoadLastKnownGood(path string, opts ...Option) (*GConfig, error) {
+	c, err := Load(opts...)
+	if err == nil {
+		if serr := c.SaveSnapshot(path); serr != nil {
+			c.logf("Error saving last known good configuration: %s\n", serr)
+		}
+		return c, nil
+	}
+
+	o := newOptions(opts)
+	data, rerr := ioutil.ReadFile(path)
+	if rerr != nil {
+		return nil, err
+	}
+	o.logf("Error loading configuration, falling back to the last known good snapshot %s: %s\n", path, err)
+
+	fallback := append(opts[:len(opts):len(opts)], func(o *options) {
+		o.sources, o.sourceURLs = nil, nil
+		o.dotEnv, o.exportDotEnv, o.envOnly = false, false, false
+		o.fsys, o.path = nil, ""
+		o.fallback = true
+	}, WithContent(StandardPropFileName, data))
+	c, lerr := Load(fallback...)
+	if lerr != nil {
+		return nil, err
+	}
+	return c, nil
+}
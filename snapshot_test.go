@@ -0,0 +1,49 @@
+package gconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadLastKnownGood(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.url=postgres://${DB_HOST_TEST|localhost}/app\n")
+	snapshot := filepath.Join(t.TempDir(), "last-known-good.properties")
+
+	remote := &mapSource{name: "remote", values: map[string]string{"feature.enabled": "true"}}
+	gcg, err := LoadLastKnownGood(snapshot, WithPath(dir), WithSource(remote))
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcg.Set("app.name", "tuned")
+	if err := gcg.SaveSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if fi, err := os.Stat(snapshot); err != nil || fi.Mode().Perm() != 0600 {
+		t.Errorf("The snapshot should only be readable by its owner: %v %v", fi.Mode(), err)
+	}
+
+	remote.err = errors.New("unreachable")
+	gcg, err = LoadLastKnownGood(snapshot, WithPath(dir), WithSource(remote), WithLogger(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !gcg.GetBool("feature.enabled") {
+		t.Error("Values of the unreachable source should come from the snapshot")
+	}
+	if n := gcg.GetString("app.name"); n != "tuned" {
+		t.Errorf("The snapshot should hold the values saved at shutdown but app.name was %s", n)
+	}
+	os.Setenv("DB_HOST_TEST", "db")
+	defer os.Unsetenv("DB_HOST_TEST")
+	if u := gcg.GetString("db.url"); u != "postgres://db/app" {
+		t.Errorf("Placeholders should be kept in the snapshot but db.url was %s", u)
+	}
+
+	os.Remove(snapshot)
+	if _, err := LoadLastKnownGood(snapshot, WithPath(dir), WithSource(remote), WithLogger(nil)); err == nil {
+		t.Error("Without a snapshot the load error should be returned")
+	}
+}
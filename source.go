@@ -0,0 +1,207 @@
+package gconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	s "strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Source provides configuration values from somewhere other than the properties files,
+// eg: a secrets manager or a key/value store. Values from sources take precedence over
+// the files and are overridden by the environment and by Set; when several sources
+// define a key the one registered last wins.
+//
+// The core package doesn't implement any source that needs a third party dependency.
+// Those live in their own packages that register a SourceFactory from an init function,
+// so a binary only links the clients of the sources it imports:
+//
+//	import _ "example.com/gconfig-consul"
+//
+//	cfg, err := gconfig.Load(gconfig.WithSourceURL("consul://localhost:8500/myapp"))
+//
+// A source able to push its changes also implements Watcher, which WatchSources checks
+// for. Dir is the properties files loader as a Source, and a starting point for a
+// custom one.
+type Source interface {
+	// Name identifies the source in errors, logs and freshness checks.
+	Name() string
+	// Load returns every key/value pair currently provided by the source.
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// SourceFactory creates a Source from the location in a source URL, ie. everything
+// after "scheme://".
+type SourceFactory func(location string) (Source, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]SourceFactory)
+)
+
+// RegisterSource makes a source available to WithSourceURL under the given URL scheme.
+// It's meant to be called from the init function of the package implementing the source
+// and panics if the scheme is registered twice or the factory is nil.
+func RegisterSource(scheme string, factory SourceFactory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("gconfig: RegisterSource factory is nil")
+	}
+	if _, dup := factories[scheme]; dup {
+		panic("gconfig: RegisterSource called twice for scheme " + scheme)
+	}
+	factories[scheme] = factory
+}
+
+// RegisteredSources returns the sorted schemes of the registered sources.
+func RegisteredSources() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	schemes := make([]string, 0, len(factories))
+	for scheme := range factories {
+		schemes = append(schemes, scheme)
+	}
+	sort.Strings(schemes)
+	return schemes
+}
+
+// WithSource adds a source whose values are layered over the properties files.
+func WithSource(src Source) Option {
+	return func(o *options) {
+		o.sources = append(o.sources, src)
+	}
+}
+
+// WithSourceURL adds the source registered for the scheme of url, eg: "vault://secret/myapp".
+// Load fails if no package registered the scheme, which usually means its blank import
+// is missing.
+func WithSourceURL(url string) Option {
+	return func(o *options) {
+		o.sourceURLs = append(o.sourceURLs, url)
+	}
+}
+
+// OpenSource creates the source registered for the scheme of url, as WithSourceURL does,
+// for tools working with sources directly, eg: Sync.
+func OpenSource(url string) (Source, error) {
+	return newSource(url)
+}
+
+// newSource creates the source registered for the scheme of url. The // after the
+// scheme is optional, eg: dir:./config.
+func newSource(url string) (Source, error) {
+	i := s.Index(url, ":")
+	if i <= 0 {
+		return nil, fmt.Errorf("Invalid source URL %q, expected scheme://location", url)
+	}
+	scheme, location := url[:i], s.TrimPrefix(url[i+1:], "//")
+
+	factoriesMu.RLock()
+	factory, ok := factories[scheme]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("No source registered for scheme %q, is the package implementing it imported?", scheme)
+	}
+	return factory(location)
+}
+
+// loadSources reads every source of o, in order, into c. Sources added by URL are
+// created once and kept in o, so reloads and Set reuse them.
+func (c *GConfig) loadSources(ctx context.Context, o *options) error {
+	if len(o.sourceURLs) > 0 {
+		srcs := append([]Source(nil), o.sources...)
+		for _, url := range o.sourceURLs {
+			src, err := newSource(url)
+			if err != nil {
+				return err
+			}
+			srcs = append(srcs, src)
+		}
+		o.sources, o.sourceURLs = srcs, nil
+	}
+	srcs := o.sources
+	if len(srcs) == 0 {
+		return nil
+	}
+
+	c.v.sourced = make(map[string]string)
+	c.v.sourcedFrom = make(map[string]string)
+	for _, src := range srcs {
+		kv, err := loadSource(ctx, src, o.timeoutFor(src.Name()))
+		if err != nil {
+			return errors.Wrapf(err, "Error loading configuration source %s", src.Name())
+		}
+		for k, v := range kv {
+			c.v.sourced[k] = v
+			c.v.sourcedFrom[k] = src.Name()
+		}
+		c.v.sources[src.Name()] = time.Now()
+		o.logf("Loaded %d keys from source %s\n", len(kv), src.Name())
+	}
+	return nil
+}
+
+// loadSource loads src within timeout, if not zero. A source ignoring ctx is abandoned
+// once ctx is done, so a hanging source can't block the load.
+func loadSource(ctx context.Context, src Source, timeout time.Duration) (map[string]string, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if ctx.Done() == nil {
+		return src.Load(ctx)
+	}
+
+	type result struct {
+		kv  map[string]string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		kv, err := src.Load(ctx)
+		done <- result{kv, err}
+	}()
+	select {
+	case r := <-done:
+		return r.kv, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// WithSourceTimeout bounds the time each of the named sources, or every source when no
+// name is given, may take to load. A source exceeding it fails the load with
+// context.DeadlineExceeded. Later options win for the same source.
+func WithSourceTimeout(timeout time.Duration, names ...string) Option {
+	return func(o *options) {
+		if len(names) == 0 {
+			o.sourceTimeouts = append(o.sourceTimeouts, sourceTimeout{timeout: timeout})
+		}
+		for _, name := range names {
+			o.sourceTimeouts = append(o.sourceTimeouts, sourceTimeout{name: name, timeout: timeout})
+		}
+	}
+}
+
+// sourceTimeout bounds the loading of the source named name, or of every source if empty.
+type sourceTimeout struct {
+	name    string
+	timeout time.Duration
+}
+
+// timeoutFor returns the timeout applying to the source named name, 0 for none.
+func (o *options) timeoutFor(name string) time.Duration {
+	var d time.Duration
+	for _, st := range o.sourceTimeouts {
+		if st.name == "" || st.name == name {
+			d = st.timeout
+		}
+	}
+	return d
+}
@@ -0,0 +1,181 @@
+package gconfig
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// mapSource is a Source serving a fixed set of values.
+type mapSource struct {
+	name   string
+	values map[string]string
+	err    error
+}
+
+func (m *mapSource) Name() string { return m.name }
+
+func (m *mapSource) Load(ctx context.Context) (map[string]string, error) {
+	return m.values, m.err
+}
+
+func init() {
+	RegisterSource("gctest", func(location string) (Source, error) {
+		return &mapSource{name: "gctest", values: map[string]string{"source.location": location}}, nil
+	})
+}
+
+func TestWithSource(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=file\napp.port=8080\n")
+
+	first := &mapSource{name: "first", values: map[string]string{"app.name": "first", "app.region": "eu"}}
+	second := &mapSource{name: "second", values: map[string]string{"app.name": "second"}}
+	gcg, err := Load(WithPath(dir), WithSource(first), WithSource(second))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := gcg.GetString("app.name"); n != "second" {
+		t.Errorf("The last source should win over the files but app.name was %s", n)
+	}
+	if r := gcg.GetString("app.region"); r != "eu" {
+		t.Errorf("Key app.region should come from the first source but was %s", r)
+	}
+	if p := gcg.GetInt("app.port"); p != 8080 {
+		t.Errorf("Keys missing from the sources should come from the files but app.port was %d", p)
+	}
+	if _, ok := gcg.current().sources["second"]; !ok {
+		t.Error("Sources should be tracked for freshness")
+	}
+
+	second.values = map[string]string{"app.name": "reloaded"}
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "reloaded" {
+		t.Errorf("Reload should re-read the sources but app.name was %s", n)
+	}
+}
+
+func TestWithSourceError(t *testing.T) {
+	src := &mapSource{name: "broken", err: errors.New("connection refused")}
+	_, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSource(src))
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Load should fail naming the broken source but got %v", err)
+	}
+}
+
+func TestWithSourceURL(t *testing.T) {
+	gcg, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSourceURL("gctest://some/where"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := gcg.GetString("source.location"); l != "some/where" {
+		t.Errorf("The factory should receive the URL location but got %s", l)
+	}
+
+	_, err = Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSourceURL("unknown://x"))
+	if err == nil || !strings.Contains(err.Error(), "unknown") {
+		t.Errorf("Unregistered schemes should fail the load but got %v", err)
+	}
+}
+
+func TestRegisterSourceTwice(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a scheme twice should panic")
+		}
+	}()
+	RegisterSource("gctest", func(string) (Source, error) { return nil, nil })
+}
+
+// hangSource is a Source that never answers, ignoring its context.
+type hangSource struct{ name string }
+
+func (h hangSource) Name() string { return h.name }
+
+func (h hangSource) Load(ctx context.Context) (map[string]string, error) {
+	select {}
+}
+
+func TestWithSourceTimeout(t *testing.T) {
+	fast := &mapSource{name: "fast", values: map[string]string{"app.name": "fast"}}
+	_, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSource(fast), WithSource(hangSource{name: "slow"}),
+		WithSourceTimeout(time.Hour), WithSourceTimeout(10*time.Millisecond, "slow"))
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "slow") {
+		t.Errorf("A hanging source should fail the load once its timeout expires but got %v", err)
+	}
+
+	o := newOptions([]Option{WithSourceTimeout(time.Second), WithSourceTimeout(time.Minute, "vault")})
+	if d := o.timeoutFor("vault"); d != time.Minute {
+		t.Errorf("The named timeout should apply to vault but got %s", d)
+	}
+	if d := o.timeoutFor("consul"); d != time.Second {
+		t.Errorf("The default timeout should apply to other sources but got %s", d)
+	}
+}
+
+func TestLoadContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := LoadContext(ctx, WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSource(hangSource{name: "slow"}))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("LoadContext should give up once ctx is done but got %v", err)
+	}
+
+	gcg, err := LoadContext(context.Background(), WithPath(t.TempDir()), EnvOnly("GCTEST"),
+		WithSource(&mapSource{name: "fast", values: map[string]string{"app.name": "fast"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if err := gcg.ReloadContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ReloadContext should fail with a cancelled ctx but got %v", err)
+	}
+	if n := gcg.GetString("app.name"); n != "fast" {
+		t.Errorf("A failed reload should keep the values but app.name was %s", n)
+	}
+}
+
+// gateSource is a Source whose loads after the first wait for release, ignoring their
+// context.
+type gateSource struct {
+	calls   int32
+	release chan struct{}
+}
+
+func (g *gateSource) Name() string { return "gate" }
+
+func (g *gateSource) Load(ctx context.Context) (map[string]string, error) {
+	if atomic.AddInt32(&g.calls, 1) > 1 {
+		<-g.release
+	}
+	return map[string]string{"app.name": "gate"}, nil
+}
+
+func TestAbandonedReload(t *testing.T) {
+	gate := &gateSource{release: make(chan struct{})}
+	gcg, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithSourceURL("gctest://first"), WithSource(gate))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := gcg.ReloadContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("ReloadContext should give up once ctx is done but got %v", err)
+	}
+
+	// the abandoned reload finishes while another one runs
+	close(gate.release)
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if l := gcg.GetString("source.location"); l != "first" {
+		t.Errorf("The reload should keep the sources of the URLs but source.location was %q", l)
+	}
+}
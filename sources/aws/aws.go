@@ -0,0 +1,186 @@
+// Package aws reads configuration from AWS Systems Manager Parameter Store and resolves
+// secretsmanager:// references against AWS Secrets Manager, removing the need for a
+// sidecar rendering properties files.
+//
+// The package doesn't depend on the AWS SDK: it talks to the services through the small
+// SSM and SecretsManager interfaces, which applications implement on top of the SDK
+// version they already use, eg: with the SDK v2
+//
+//	type ssmClient struct{ *ssm.Client }
+//
+//	func (c ssmClient) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+//		params := make(map[string]string)
+//		p := ssm.NewGetParametersByPathPaginator(c.Client, &ssm.GetParametersByPathInput{
+//			Path: &path, Recursive: aws.Bool(true), WithDecryption: aws.Bool(true),
+//		})
+//		for p.HasMorePages() {
+//			out, err := p.NextPage(ctx)
+//			if err != nil {
+//				return nil, err
+//			}
+//			for _, prm := range out.Parameters {
+//				params[*prm.Name] = *prm.Value
+//			}
+//		}
+//		return params, nil
+//	}
+//
+// and then register them once at startup:
+//
+//	aws.Register(ssmClient{ssm.NewFromConfig(cfg)}, smClient{secretsmanager.NewFromConfig(cfg)}, 5*time.Minute)
+//	c, err := gconfig.Load(gconfig.WithSourceURL("ssm:///myapp/prod/"))
+//
+// Parameters are read when the configuration loads or reloads, see gconfig.NewReloader to
+// refresh them periodically; secrets are cached for the TTL given to Register.
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	s "strings"
+	"sync"
+	"time"
+
+	"github.com/narup/gconfig"
+)
+
+// SSM is the subset of the Parameter Store API used by the package.
+type SSM interface {
+	// GetParametersByPath returns the decrypted values of every parameter under path,
+	// recursively, keyed by their full name.
+	GetParametersByPath(ctx context.Context, path string) (map[string]string, error)
+}
+
+// SecretsManager is the subset of the Secrets Manager API used by the package.
+type SecretsManager interface {
+	// GetSecretValue returns the current SecretString of the secret with the given id,
+	// a name or an ARN.
+	GetSecretValue(ctx context.Context, id string) (string, error)
+}
+
+// Register registers the "ssm" source scheme, whose location is the parameter path, and
+// the "secretsmanager" resolver, caching secrets for ttl. Either client may be nil to
+// register only the other.
+func Register(params SSM, secrets SecretsManager, ttl time.Duration) {
+	if params != nil {
+		gconfig.RegisterSource("ssm", func(path string) (gconfig.Source, error) {
+			return ParameterStore(params, path), nil
+		})
+	}
+	if secrets != nil {
+		gconfig.RegisterResolver("secretsmanager", SecretsResolver(secrets, ttl))
+	}
+}
+
+// ParameterStore returns a Source reading the parameters under path. The path is
+// stripped from the parameter names and the remaining slashes become dots, so with the
+// path /myapp/prod/ the parameter /myapp/prod/db/url is the key db.url.
+func ParameterStore(client SSM, path string) gconfig.Source {
+	if !s.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return &parameterStore{client: client, path: path}
+}
+
+type parameterStore struct {
+	client SSM
+	path   string
+}
+
+func (p *parameterStore) Name() string {
+	return "ssm:" + p.path
+}
+
+func (p *parameterStore) Load(ctx context.Context) (map[string]string, error) {
+	params, err := p.client.GetParametersByPath(ctx, p.path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string, len(params))
+	for name, v := range params {
+		if !s.HasPrefix(name, p.path) {
+			continue
+		}
+		values[s.Replace(s.TrimPrefix(name, p.path), "/", ".", -1)] = v
+	}
+	return values, nil
+}
+
+// SecretsResolver returns a Resolver for secretsmanager://id references, caching each
+// secret for ttl. A reference can select a field of a JSON secret after a '#', eg:
+// secretsmanager://myapp/db#password. When refreshing an expired secret fails the cached
+// value keeps being served.
+func SecretsResolver(client SecretsManager, ttl time.Duration) gconfig.Resolver {
+	return &secretsResolver{client: client, ttl: ttl, cache: make(map[string]cachedSecret)}
+}
+
+type cachedSecret struct {
+	value   string
+	fetched time.Time
+}
+
+type secretsResolver struct {
+	client SecretsManager
+	ttl    time.Duration
+
+	mu    sync.Mutex
+	cache map[string]cachedSecret
+	now   func() time.Time
+}
+
+func (r *secretsResolver) Resolve(ctx context.Context, ref string) (string, error) {
+	id, field := ref, ""
+	if i := s.LastIndex(ref, "#"); i >= 0 {
+		id, field = ref[:i], ref[i+1:]
+	}
+
+	secret, err := r.secret(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if field == "" {
+		return secret, nil
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("secret %s is not a JSON object", id)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("secret %s has no field %s", id, field)
+	}
+	if str, ok := v.(string); ok {
+		return str, nil
+	}
+	b, _ := json.Marshal(v)
+	return string(b), nil
+}
+
+// secret returns the secret with the given id, from the cache while it's fresh.
+func (r *secretsResolver) secret(ctx context.Context, id string) (string, error) {
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+
+	r.mu.Lock()
+	cached, ok := r.cache[id]
+	r.mu.Unlock()
+	if ok && now.Sub(cached.fetched) < r.ttl {
+		return cached.value, nil
+	}
+
+	v, err := r.client.GetSecretValue(ctx, id)
+	if err != nil {
+		if ok {
+			return cached.value, nil
+		}
+		return "", err
+	}
+	r.mu.Lock()
+	r.cache[id] = cachedSecret{value: v, fetched: now}
+	r.mu.Unlock()
+	return v, nil
+}
@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/narup/gconfig"
+)
+
+type fakeSSM map[string]string
+
+func (f fakeSSM) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+	return f, nil
+}
+
+type fakeSecrets struct {
+	secrets map[string]string
+	calls   int
+	err     error
+}
+
+func (f *fakeSecrets) GetSecretValue(ctx context.Context, id string) (string, error) {
+	f.calls++
+	if f.err != nil {
+		return "", f.err
+	}
+	v, ok := f.secrets[id]
+	if !ok {
+		return "", errors.New("ResourceNotFoundException")
+	}
+	return v, nil
+}
+
+// registered holds the clients of the schemes registered by TestRegister, once as the
+// schemes can't be registered again.
+var registered struct {
+	once    sync.Once
+	params  fakeSSM
+	secrets *fakeSecrets
+}
+
+func TestRegister(t *testing.T) {
+	registered.once.Do(func() {
+		registered.params = fakeSSM{
+			"/myapp/prod/db/url":      "postgres://db/app",
+			"/myapp/prod/db/password": "secretsmanager://myapp/db#password",
+			"/myapp/prod/app/name":    "billing",
+		}
+		registered.secrets = &fakeSecrets{secrets: map[string]string{"myapp/db": `{"username":"app","password":"s3cret"}`}}
+		Register(registered.params, registered.secrets, time.Minute)
+	})
+	secrets := registered.secrets
+
+	c, err := gconfig.Load(gconfig.EnvOnly("GCTEST"), gconfig.WithSourceURL("ssm:///myapp/prod"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := c.GetString("db.url"); u != "postgres://db/app" {
+		t.Errorf("Key db.url should come from the parameter store but was %s", u)
+	}
+	if n := c.GetString("app.name"); n != "billing" {
+		t.Errorf("Key app.name should come from the parameter store but was %s", n)
+	}
+	if p := c.GetString("db.password"); p != "s3cret" {
+		t.Errorf("The secret reference should be resolved but db.password was %s", p)
+	}
+	c.GetString("db.password")
+	if secrets.calls > 1 {
+		t.Errorf("Secrets should be cached but were fetched %d times", secrets.calls)
+	}
+}
+
+func TestSecretsResolverTTL(t *testing.T) {
+	secrets := &fakeSecrets{secrets: map[string]string{"token": "v1"}}
+	r := SecretsResolver(secrets, time.Minute).(*secretsResolver)
+	now := time.Now()
+	r.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	if v, _ := r.Resolve(ctx, "token"); v != "v1" {
+		t.Fatalf("Unexpected secret %s", v)
+	}
+
+	secrets.secrets["token"] = "v2"
+	now = now.Add(30 * time.Second)
+	if v, _ := r.Resolve(ctx, "token"); v != "v1" {
+		t.Errorf("The cached secret should be served within the TTL but got %s", v)
+	}
+
+	now = now.Add(time.Minute)
+	if v, _ := r.Resolve(ctx, "token"); v != "v2" {
+		t.Errorf("The secret should be refreshed after the TTL but got %s", v)
+	}
+
+	secrets.err = errors.New("throttled")
+	now = now.Add(time.Hour)
+	if v, err := r.Resolve(ctx, "token"); err != nil || v != "v2" {
+		t.Errorf("The stale secret should be served when refreshing fails but got %s, %v", v, err)
+	}
+	if _, err := r.Resolve(ctx, "unknown"); err == nil {
+		t.Error("Unknown secrets should fail to resolve")
+	}
+	if _, err := r.Resolve(ctx, "token#field"); err == nil {
+		t.Error("Selecting a field of a non JSON secret should fail")
+	}
+}
@@ -0,0 +1,130 @@
+package gconfig
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats counts the key lookups and reloads of a configuration since it was loaded.
+type Stats struct {
+	// Lookups counts the values read through the Get methods, Get and Unmarshal, and
+	// Misses those of keys that aren't defined, by key in MissedKeys.
+	Lookups    uint64            `json:"lookups"`
+	Misses     uint64            `json:"misses"`
+	MissedKeys map[string]uint64 `json:"missedKeys,omitempty"`
+	// Reloads and ReloadFailures count the successful and failed calls to Reload.
+	Reloads        uint64 `json:"reloads"`
+	ReloadFailures uint64 `json:"reloadFailures"`
+	// LastReload is when Reload last succeeded, LastReloadError the error of the last
+	// failed Reload.
+	LastReload      time.Time `json:"lastReload"`
+	LastReloadError string    `json:"lastReloadError,omitempty"`
+}
+
+// stats holds the counters of a configuration, shared by all its views.
+type stats struct {
+	lookups, misses, reloads, reloadFailures uint64 // updated atomically
+
+	mu              sync.Mutex // guards the fields below
+	missedKeys      map[string]uint64
+	lastReload      time.Time
+	lastReloadError string
+}
+
+// Stats returns the lookup and reload counters of c, eg: to alert on a service reading
+// keys that don't exist or failing to refresh its configuration.
+func (c *GConfig) Stats() Stats {
+	st := c.base().stats
+	if st == nil {
+		return Stats{}
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	out := Stats{
+		Lookups:         atomic.LoadUint64(&st.lookups),
+		Misses:          atomic.LoadUint64(&st.misses),
+		Reloads:         atomic.LoadUint64(&st.reloads),
+		ReloadFailures:  atomic.LoadUint64(&st.reloadFailures),
+		LastReload:      st.lastReload,
+		LastReloadError: st.lastReloadError,
+	}
+	if len(st.missedKeys) > 0 {
+		out.MissedKeys = make(map[string]uint64, len(st.missedKeys))
+		for k, n := range st.missedKeys {
+			out.MissedKeys[k] = n
+		}
+	}
+	return out
+}
+
+// recordLookup counts a read of key, relative to c, missed if it isn't defined.
+func (c *GConfig) recordLookup(key string, missed bool) {
+	st := c.base().stats
+	if st == nil {
+		return
+	}
+	atomic.AddUint64(&st.lookups, 1)
+	if !missed {
+		return
+	}
+	atomic.AddUint64(&st.misses, 1)
+	st.mu.Lock()
+	if st.missedKeys == nil {
+		st.missedKeys = make(map[string]uint64)
+	}
+	st.missedKeys[c.prefix+key]++
+	st.mu.Unlock()
+}
+
+// recordReload counts a Reload ending with err.
+func (st *stats) recordReload(err error) {
+	if st == nil {
+		return
+	}
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if err != nil {
+		atomic.AddUint64(&st.reloadFailures, 1)
+		st.lastReloadError = err.Error()
+		return
+	}
+	atomic.AddUint64(&st.reloads, 1)
+	st.lastReload = time.Now()
+}
+
+// WritePrometheus writes the stats in the Prometheus text exposition format, for a
+// metrics endpoint or a custom collector, without gconfig depending on a client library:
+//
+//	http.HandleFunc("/metrics/config", func(w http.ResponseWriter, r *http.Request) {
+//		cfg.Stats().WritePrometheus(w)
+//	})
+func (st Stats) WritePrometheus(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	counter := func(name, help string, v uint64) {
+		fmt.Fprintf(bw, "# HELP %s %s\n# TYPE %s counter\n%s %d\n", name, help, name, name, v)
+	}
+	counter("gconfig_lookups_total", "Configuration keys read.", st.Lookups)
+	counter("gconfig_misses_total", "Configuration keys read but not defined.", st.Misses)
+	if len(st.MissedKeys) > 0 {
+		keys := make([]string, 0, len(st.MissedKeys))
+		for k := range st.MissedKeys {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(bw, "# HELP gconfig_key_misses_total Reads of an undefined configuration key.\n# TYPE gconfig_key_misses_total counter\n")
+		for _, k := range keys {
+			fmt.Fprintf(bw, "gconfig_key_misses_total{key=%q} %d\n", k, st.MissedKeys[k])
+		}
+	}
+	counter("gconfig_reloads_total", "Successful configuration reloads.", st.Reloads)
+	counter("gconfig_reload_failures_total", "Failed configuration reloads.", st.ReloadFailures)
+	if !st.LastReload.IsZero() {
+		fmt.Fprintf(bw, "# HELP gconfig_last_reload_timestamp_seconds Time of the last successful reload.\n# TYPE gconfig_last_reload_timestamp_seconds gauge\ngconfig_last_reload_timestamp_seconds %d\n", st.LastReload.Unix())
+	}
+	return bw.Flush()
+}
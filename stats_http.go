@@ -0,0 +1,13 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import "expvar"
+
+// StatsVar returns an expvar.Var reporting the Stats of c, eg:
+// expvar.Publish("config_stats", cfg.StatsVar()).
+func (c *GConfig) StatsVar() expvar.Var {
+	return expvar.Func(func() interface{} {
+		return c.Stats()
+	})
+}
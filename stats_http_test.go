@@ -0,0 +1,23 @@
+//go:build !gconfig_tiny
+
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStatsVar(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcg.GetString("app.name")
+	gcg.GetString("app.missing")
+	if !strings.Contains(gcg.StatsVar().String(), `"misses":1`) {
+		t.Errorf("Unexpected expvar %s", gcg.StatsVar().String())
+	}
+}
@@ -0,0 +1,47 @@
+package gconfig
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\napp.port=8080\n")
+	gcg, err := Load(WithPath(dir), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcg.GetString("app.name")
+	gcg.Sub("app").GetInt("port")
+	gcg.GetString("app.missing")
+	gcg.GetBool("app.missing")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	gcg.opts.parseStrict = true
+	writeConfig(t, dir, StandardPropFileName, "not a property\n")
+	if err := gcg.Reload(); err == nil {
+		t.Fatal("Reload should fail on a malformed file")
+	}
+
+	st := gcg.Stats()
+	if st.Lookups != 4 || st.Misses != 2 || st.MissedKeys["app.missing"] != 2 {
+		t.Errorf("Expected 4 lookups and 2 misses of app.missing but got %+v", st)
+	}
+	if st.Reloads != 1 || st.ReloadFailures != 1 || st.LastReload.IsZero() || st.LastReloadError == "" {
+		t.Errorf("Expected one successful and one failed reload but got %+v", st)
+	}
+
+	var buf bytes.Buffer
+	if err := st.WritePrometheus(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"gconfig_lookups_total 4", `gconfig_key_misses_total{key="app.missing"} 2`, "gconfig_reload_failures_total 1"} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("Expected %q in the metrics:\n%s", line, buf.String())
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package gconfig
+
+import s "strings"
+
+// Sub returns a view of the configuration scoped to the keys under prefix, with the
+// prefix stripped: cfg.Sub("database").GetString("url") reads database.url. The view
+// shares its data with cfg, so it sees every Reload and Set, and Set on the view
+// writes the prefixed key. Schema rules under the prefix carry over to the view.
+func (c *GConfig) Sub(prefix string) *GConfig {
+	p := s.TrimSuffix(prefix, ".") + "."
+	sub := &GConfig{
+		Profile: c.Profile,
+		opts:    c.opts,
+		root:    c.base(),
+		prefix:  c.prefix + p,
+		pinned:  c.pinned,
+	}
+	if c.schema != nil {
+		sub.schema = make(Schema)
+		for k, rule := range c.schema {
+			if s.HasPrefix(k, p) {
+				sub.schema[s.TrimPrefix(k, p)] = rule
+			}
+		}
+	}
+	return sub
+}
+
+// base returns the GConfig holding the data c reads from: c itself, or the root of a view.
+func (c *GConfig) base() *GConfig {
+	if c.root != nil {
+		return c.root
+	}
+	return c
+}
@@ -0,0 +1,30 @@
+package gconfig
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSub(t *testing.T) {
+	gcg := setup("dev", t)
+
+	app := gcg.Sub("app")
+	if n := app.GetString("name"); n != "gconfig dev profile" {
+		t.Errorf("Key name didn't match expected value but was %s", n)
+	}
+	if keys := strings.Join(app.keys(), ","); keys != "name,url,version" {
+		t.Errorf("Unexpected keys in app view: %s", keys)
+	}
+
+	env := gcg.Sub("myEnv").Sub("variable")
+	if v := env.GetStringOrDefault("withDefault"); v != gcg.GetStringOrDefault("myEnv.variable.withDefault") {
+		t.Errorf("Nested view should resolve myEnv.variable.withDefault but got %s", v)
+	}
+
+	if err := app.Set("name", "from view"); err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "from view" {
+		t.Errorf("Set on a view should write the prefixed key but app.name is %s", n)
+	}
+}
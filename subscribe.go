@@ -0,0 +1,116 @@
+package gconfig
+
+import (
+	"path"
+	"sort"
+	s "strings"
+)
+
+// Event describes the change of a single key. Old is empty when the key was added and
+// New is empty when it was removed. Values are expanded but references aren't resolved.
+type Event struct {
+	Key      string
+	Old, New string
+	// Added and Removed tell an added or removed key from one set to an empty value.
+	Added, Removed bool
+	// Seq is the sequence number of a change pushed by a Watcher, 0 otherwise.
+	Seq uint64
+}
+
+type subscription struct {
+	pattern string
+	prefix  string
+	fn      func(Event)
+}
+
+// Subscribe calls fn for every key matching pattern whose value changes through Set or
+// Reload, after the change is visible to readers. The pattern is a path.Match glob, eg:
+// "ratelimit.*", or a key that matches itself and every key under it, eg: "ratelimit".
+// Subscribing on a Sub view matches and reports keys relative to the view. fn runs on
+// the goroutine making the change and must not call Set. The returned function cancels
+// the subscription.
+func (c *GConfig) Subscribe(pattern string, fn func(Event)) (cancel func()) {
+	sub := &subscription{pattern: c.prefix + pattern, prefix: c.prefix, fn: fn}
+	b := c.base()
+	b.subMu.Lock()
+	b.subs = append(b.subs, sub)
+	b.subMu.Unlock()
+
+	return func() {
+		b.subMu.Lock()
+		defer b.subMu.Unlock()
+		for i, s := range b.subs {
+			if s == sub {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (sub *subscription) matches(key string) bool {
+	if ok, _ := path.Match(sub.pattern, key); ok {
+		return true
+	}
+	return key == sub.pattern || s.HasPrefix(key, sub.pattern+".")
+}
+
+// changed reports the differences between the old and new snapshots, published by op,
+// to the subscribers and the audit log. It must be called on the base configuration,
+// without holding mu.
+func (c *GConfig) changed(op string, old, nv *values) {
+	c.subMu.Lock()
+	subs := append([]*subscription(nil), c.subs...)
+	c.subMu.Unlock()
+	if len(subs) == 0 && (c.opts == nil || c.opts.audit == nil) {
+		return
+	}
+
+	events := c.changes(old, nv)
+	for _, e := range events {
+		for _, sub := range subs {
+			if sub.matches(e.Key) {
+				re := e
+				re.Key = s.TrimPrefix(e.Key, sub.prefix)
+				sub.fn(re)
+			}
+		}
+	}
+	c.audit(op, events)
+}
+
+// changes returns the keys that differ between the old and new snapshots, sorted.
+func (c *GConfig) changes(old, nv *values) []Event {
+	before, after := c.snapshot(old).expanded(), c.snapshot(nv).expanded()
+	var events []Event
+	for k, v := range after {
+		if ov, ok := before[k]; !ok {
+			events = append(events, Event{Key: k, New: v, Added: true})
+		} else if ov != v {
+			events = append(events, Event{Key: k, Old: ov, New: v})
+		}
+	}
+	for k, ov := range before {
+		if _, ok := after[k]; !ok {
+			events = append(events, Event{Key: k, Old: ov, Removed: true})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Key < events[j].Key })
+	return events
+}
+
+// snapshot returns a read-only configuration reading the given snapshot, sharing the
+// values generated by functions with c, see callFunction.
+func (c *GConfig) snapshot(vs *values) *GConfig {
+	return &GConfig{Profile: c.Profile, schema: c.schema, opts: c.opts, root: c.base(), pinned: vs}
+}
+
+// expanded returns every key with its placeholders and expressions expanded.
+func (c *GConfig) expanded() map[string]string {
+	values := make(map[string]string)
+	for _, k := range c.keys() {
+		raw, _ := c.getValue(k).(string)
+		values[k] = c.expand(k, raw, map[string]bool{k: true})
+	}
+	return values
+}
@@ -0,0 +1,76 @@
+package gconfig
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubscribe(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "ratelimit.rps=100\nratelimit.burst=10\nratelimit.url=http://rl:${ratelimit.port}\nratelimit.port=80\napp.name=gconfig\n")
+
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var events []Event
+	cancel := gcg.Subscribe("ratelimit.*", func(e Event) {
+		events = append(events, e)
+	})
+	var exact []Event
+	gcg.Subscribe("app.name", func(e Event) {
+		exact = append(exact, e)
+	})
+
+	gcg.Set("app.version", "1")
+	if len(events) != 0 || len(exact) != 0 {
+		t.Errorf("Changes to other keys shouldn't be reported but got %v and %v", events, exact)
+	}
+
+	writeConfig(t, dir, StandardPropFileName, "ratelimit.rps=200\nratelimit.url=http://rl:${ratelimit.port}\nratelimit.port=81\nratelimit.window=1s\napp.name=gconfig\n")
+	if err := gcg.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []Event{
+		{Key: "ratelimit.burst", Old: "10", Removed: true},
+		{Key: "ratelimit.port", Old: "80", New: "81"},
+		{Key: "ratelimit.rps", Old: "100", New: "200"},
+		{Key: "ratelimit.url", Old: "http://rl:80", New: "http://rl:81"},
+		{Key: "ratelimit.window", New: "1s", Added: true},
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("Expected events %v but got %v", expected, events)
+	}
+	if len(exact) != 0 {
+		t.Errorf("Unchanged keys shouldn't be reported but got %v", exact)
+	}
+
+	gcg.Set("app.name", "renamed")
+	if len(exact) != 1 || exact[0].Old != "gconfig" || exact[0].New != "renamed" {
+		t.Errorf("Set should be reported with the old and new values but got %v", exact)
+	}
+
+	cancel()
+	gcg.Set("ratelimit.rps", "300")
+	if len(events) != len(expected) {
+		t.Errorf("Cancelled subscriptions shouldn't be called but got %v", events[len(expected):])
+	}
+}
+
+func TestSubscribeSub(t *testing.T) {
+	gcg, err := Load(WithPath(t.TempDir()), EnvOnly("GCTEST"), WithDefaults(map[string]string{"db.url": "a"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var keys []string
+	gcg.Sub("db").Subscribe("url", func(e Event) {
+		keys = append(keys, e.Key)
+	})
+	gcg.Set("db.url", "b")
+	gcg.Set("url", "c")
+	if !reflect.DeepEqual(keys, []string{"url"}) {
+		t.Errorf("Sub subscriptions should see relative keys under the prefix only but got %v", keys)
+	}
+}
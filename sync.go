@@ -0,0 +1,157 @@
+package gconfig
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	s "strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Dir returns a Source serving the properties files of a configuration directory for a
+// profile, as Load reads them but with placeholders left unresolved, eg: to Sync them
+// into a key/value store or to layer a shared directory over the application's own. It's
+// registered as "dir", eg: dir://./config?profile=prod. It implements Watcher by reading
+// the files again every dirWatchInterval, so WatchSources picks up their edits.
+func Dir(path, profile string) Source {
+	return &dirSource{path: path, profile: profile}
+}
+
+type dirSource struct {
+	path, profile string
+}
+
+func (d *dirSource) Name() string {
+	if d.profile == "" {
+		return "dir:" + d.path
+	}
+	return "dir:" + d.path + "?profile=" + d.profile
+}
+
+func (d *dirSource) Load(ctx context.Context) (map[string]string, error) {
+	return d.read(ctx)
+}
+
+// read loads the files of the directory with the extra options.
+func (d *dirSource) read(ctx context.Context, opts ...Option) (map[string]string, error) {
+	opts = append([]Option{WithPath(d.path), WithProfile(d.profile), WithoutFlags(), WithoutEnv()}, opts...)
+	c, err := load(ctx, newOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	for _, k := range c.keys() {
+		values[k], _ = c.getValue(k).(string)
+	}
+	return values, nil
+}
+
+// dirWatchInterval is how often the Watch of a Dir source reads its files.
+var dirWatchInterval = 2 * time.Second
+
+// Watch sends an event for every key added, changed or removed in the files, reading
+// them every dirWatchInterval until ctx is done or they can't be read. The first read
+// reports every key as added, as the files may have changed since the source was loaded.
+func (d *dirSource) Watch(ctx context.Context, events chan<- Event) error {
+	t := time.NewTicker(dirWatchInterval)
+	defer t.Stop()
+	var prev map[string]string
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-t.C:
+		}
+		// polling every few seconds would flood the log
+		cur, err := d.read(ctx, WithLogger(nil))
+		if err != nil {
+			return err
+		}
+		for _, e := range diffValues(prev, cur) {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return nil
+			}
+		}
+		prev = cur
+	}
+}
+
+// diffValues returns the events turning old into cur, sorted by key.
+func diffValues(old, cur map[string]string) []Event {
+	var events []Event
+	for k, v := range cur {
+		if ov, ok := old[k]; !ok {
+			events = append(events, Event{Key: k, New: v, Added: true})
+		} else if ov != v {
+			events = append(events, Event{Key: k, Old: ov, New: v})
+		}
+	}
+	for k, v := range old {
+		if _, ok := cur[k]; !ok {
+			events = append(events, Event{Key: k, Old: v, Removed: true})
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Key < events[j].Key })
+	return events
+}
+
+// Sync copies every key of from into to, merging with what to already holds: keys only
+// to defines are kept. Each key is written with CompareAndSwap against the value read
+// beforehand, so a concurrent change in to fails the sync with a conflict. It returns
+// how from differs from to: Added and Changed list the keys written, Removed the keys
+// only to defines, which are left alone. With dryRun nothing is written.
+func Sync(ctx context.Context, from Source, to WritableSource, dryRun bool) (*ConfigDiff, error) {
+	src, err := from.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading configuration source %s", from.Name())
+	}
+	dst, err := to.Load(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Error loading configuration source %s", to.Name())
+	}
+
+	d := new(ConfigDiff)
+	for k, v := range src {
+		if cur, ok := dst[k]; !ok {
+			d.Added = append(d.Added, k)
+		} else if cur != v {
+			d.Changed = append(d.Changed, k)
+		}
+	}
+	for k := range dst {
+		if _, ok := src[k]; !ok {
+			d.Removed = append(d.Removed, k)
+		}
+	}
+	sort.Strings(d.Added)
+	sort.Strings(d.Removed)
+	sort.Strings(d.Changed)
+
+	if dryRun {
+		return d, nil
+	}
+	for _, k := range append(append([]string(nil), d.Added...), d.Changed...) {
+		if err := to.CompareAndSwap(ctx, k, dst[k], src[k]); err != nil {
+			return d, errors.Wrapf(err, "Error syncing %s to %s", k, to.Name())
+		}
+	}
+	return d, nil
+}
+
+func init() {
+	RegisterSource("dir", func(location string) (Source, error) {
+		path, query := location, ""
+		if i := s.Index(location, "?"); i >= 0 {
+			path, query = location[:i], location[i+1:]
+		}
+		q, err := url.ParseQuery(query)
+		if err != nil {
+			return nil, err
+		}
+		return Dir(path, q.Get("profile")), nil
+	})
+}
@@ -0,0 +1,79 @@
+package gconfig
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "db.host=localhost\ndb.url=pg://${db.host}\napp.name=gconfig\n")
+	writeConfig(t, dir, "application-prod.properties", "db.host=db.prod\n")
+	store := &kvStore{name: "kv", values: map[string]string{"app.name": "gconfig", "db.host": "old", "legacy.flag": "true"}}
+
+	from, err := OpenSource("dir:" + dir + "?profile=prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := Sync(context.Background(), from, store, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &ConfigDiff{Added: []string{"db.url"}, Removed: []string{"legacy.flag"}, Changed: []string{"db.host"}}
+	if !reflect.DeepEqual(d, expected) {
+		t.Errorf("Unexpected sync diff %v", d)
+	}
+	if store.values["db.host"] != "old" {
+		t.Error("A dry run shouldn't write anything")
+	}
+
+	if _, err := Sync(context.Background(), from, store, false); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"app.name": "gconfig", "db.host": "db.prod", "db.url": "pg://${db.host}", "legacy.flag": "true"}
+	if !reflect.DeepEqual(store.values, want) {
+		t.Errorf("Sync should merge the profile into the store with placeholders kept but it holds %v", store.values)
+	}
+
+	if d, _ := Sync(context.Background(), from, store, false); len(d.Added) > 0 || len(d.Changed) > 0 {
+		t.Errorf("A second sync should have nothing to write: %v", d)
+	}
+}
+
+func TestDirWatch(t *testing.T) {
+	defer func(d time.Duration) { dirWatchInterval = d }(dirWatchInterval)
+	dirWatchInterval = 10 * time.Millisecond
+
+	shared := t.TempDir()
+	writeConfig(t, shared, StandardPropFileName, "db.host=localhost\nlegacy.flag=true\n")
+	var _ Watcher = Dir(shared, "").(*dirSource)
+
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=app\n")
+	c, err := Load(WithPath(dir), WithSource(Dir(shared, "")), WithoutFlags(), WithoutEnv())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.WatchSources(ctx)
+
+	writeConfig(t, shared, StandardPropFileName, "db.host=db.stage\nlegacy.flag=true\n")
+	eventually(t, "the edited file should be watched", func() bool {
+		return c.GetString("db.host") == "db.stage"
+	})
+	writeConfig(t, shared, StandardPropFileName, "db.host=db.prod\napp.name=gconfig\n")
+	eventually(t, "keys removed from the file should be removed", func() bool {
+		return c.GetString("db.host") == "db.prod" && c.GetString("app.name") == "gconfig" && !c.Exists("legacy.flag")
+	})
+}
+
+func TestDiffValues(t *testing.T) {
+	events := diffValues(map[string]string{"a": "1", "b": "2"}, map[string]string{"b": "3", "c": "4"})
+	want := []Event{{Key: "a", Old: "1", Removed: true}, {Key: "b", Old: "2", New: "3"}, {Key: "c", New: "4", Added: true}}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("Expected %v, got %v", want, events)
+	}
+}
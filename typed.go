@@ -0,0 +1,118 @@
+package gconfig
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	s "strings"
+	"time"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Get returns the value of key converted to T, with placeholders resolved. T is a
+// string, bool, numeric type, time.Duration, a slice of those read from a comma separated
+// list or from indexed keys, see GetSlice, implements encoding.TextUnmarshaler or has a decoder, see RegisterDecoder. A
+// missing key yields a *MissingKeysError and a value that doesn't convert a *TypeError
+// naming the key.
+func Get[T any](c *GConfig, key string) (T, error) {
+	var v T
+	rv := reflect.ValueOf(&v).Elem()
+	if !c.Exists(key) {
+		if ok, err := c.decodeIndexed(key, rv); ok {
+			return v, err
+		}
+		return v, &MissingKeysError{Keys: []string{key}}
+	}
+	if err := decodeValue(c.GetString(key), rv); err != nil {
+		return v, typeError(key, rv.Type(), err)
+	}
+	return v, nil
+}
+
+// decodeIndexed sets the slice rv from the indexed keys under key, reporting whether
+// there were any.
+func (c *GConfig) decodeIndexed(key string, rv reflect.Value) (bool, error) {
+	if rv.Kind() != reflect.Slice || decodable(rv.Type()) {
+		return false, nil
+	}
+	keys := c.indexedKeys(key)
+	if keys == nil {
+		return false, nil
+	}
+	sl := reflect.MakeSlice(rv.Type(), len(keys), len(keys))
+	for i, k := range keys {
+		if err := decodeValue(c.GetString(k), sl.Index(i)); err != nil {
+			return true, typeError(k, sl.Index(i).Type(), err)
+		}
+	}
+	rv.Set(sl)
+	return true, nil
+}
+
+// decodeValue converts raw into the addressable value rv.
+func decodeValue(raw string, rv reflect.Value) error {
+	if dec := decoder(rv.Type()); dec != nil {
+		return dec(raw, rv)
+	}
+	if reflect.PtrTo(rv.Type()).Implements(textUnmarshalerType) {
+		return rv.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+	if rv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		rv.SetInt(int64(d))
+		return nil
+	}
+
+	switch rv.Kind() {
+	case reflect.String:
+		rv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		rv.SetFloat(f)
+	case reflect.Slice:
+		if s.TrimSpace(raw) == "" {
+			rv.Set(reflect.MakeSlice(rv.Type(), 0, 0))
+			return nil
+		}
+		items := s.Split(raw, ",")
+		sl := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeValue(s.TrimSpace(item), sl.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(sl)
+	default:
+		return fmt.Errorf("Unsupported type %s", rv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package gconfig
+
+import (
+	"errors"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.port=8080\napp.ratio=0.25\napp.debug=true\napp.timeout=1m30s\n"+
+		"app.hosts=a, b,c\napp.ports=80,443\napp.ip=10.0.0.1\napp.name=${app.host:-gconfig}\napp.small=300\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err := Get[int](gcg, "app.port"); err != nil || p != 8080 {
+		t.Errorf("Expected port 8080 but got %d, %v", p, err)
+	}
+	if r, err := Get[float64](gcg, "app.ratio"); err != nil || r != 0.25 {
+		t.Errorf("Expected ratio 0.25 but got %v, %v", r, err)
+	}
+	if d, err := Get[bool](gcg, "app.debug"); err != nil || !d {
+		t.Errorf("Expected debug to be true but got %v, %v", d, err)
+	}
+	if d, err := Get[time.Duration](gcg, "app.timeout"); err != nil || d != 90*time.Second {
+		t.Errorf("Expected a 1m30s timeout but got %s, %v", d, err)
+	}
+	if h, err := Get[[]string](gcg, "app.hosts"); err != nil || !reflect.DeepEqual(h, []string{"a", "b", "c"}) {
+		t.Errorf("Unexpected hosts %q, %v", h, err)
+	}
+	if p, err := Get[[]uint16](gcg, "app.ports"); err != nil || !reflect.DeepEqual(p, []uint16{80, 443}) {
+		t.Errorf("Unexpected ports %v, %v", p, err)
+	}
+	if ip, err := Get[net.IP](gcg, "app.ip"); err != nil || !ip.Equal(net.IPv4(10, 0, 0, 1)) {
+		t.Errorf("Text unmarshalers should be used but got %v, %v", ip, err)
+	}
+	if n, err := Get[string](gcg, "app.name"); err != nil || n != "gconfig" {
+		t.Errorf("Placeholders should be resolved but got %s, %v", n, err)
+	}
+
+	if _, err := Get[int8](gcg, "app.small"); err == nil {
+		t.Error("Values overflowing the type should fail")
+	}
+	if _, err := Get[int](gcg, "app.ratio"); err == nil {
+		t.Error("Values not converting to the type should fail")
+	}
+	var missing *MissingKeysError
+	if _, err := Get[int](gcg, "app.missing"); !errors.As(err, &missing) {
+		t.Errorf("Missing keys should fail with a *MissingKeysError but got %v", err)
+	}
+	if _, err := Get[struct{}](gcg, "app.port"); err == nil {
+		t.Error("Unsupported types should fail")
+	}
+
+	d := 5 * time.Second
+	if err := decodeValue("5 parsecs", reflect.ValueOf(&d).Elem()); err == nil || d != 5*time.Second {
+		t.Errorf("An invalid duration should fail and leave the value alone but got %s, %v", d, err)
+	}
+}
@@ -0,0 +1,197 @@
+package gconfig
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	s "strings"
+	"sync"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	decodersMu sync.RWMutex
+	decoders   = make(map[reflect.Type]func(raw string, rv reflect.Value) error)
+)
+
+// RegisterDecoder makes Get and Unmarshal decode values of type T with fn, eg:
+//
+//	gconfig.RegisterDecoder(func(v string) (*net.IPNet, error) {
+//		_, n, err := net.ParseCIDR(v)
+//		return n, err
+//	})
+//
+// A registered decoder takes precedence over the built-in conversions. It's meant to be
+// called from an init function and panics if T is registered twice or fn is nil.
+func RegisterDecoder[T any](fn func(string) (T, error)) {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	decodersMu.Lock()
+	defer decodersMu.Unlock()
+	if fn == nil {
+		panic("gconfig: RegisterDecoder decoder is nil")
+	}
+	if _, dup := decoders[t]; dup {
+		panic("gconfig: RegisterDecoder called twice for type " + t.String())
+	}
+	decoders[t] = func(raw string, rv reflect.Value) error {
+		v, err := fn(raw)
+		if err != nil {
+			return err
+		}
+		rv.Set(reflect.ValueOf(&v).Elem())
+		return nil
+	}
+}
+
+// decoder returns the decoder registered for t, or nil.
+func decoder(t reflect.Type) func(string, reflect.Value) error {
+	decodersMu.RLock()
+	defer decodersMu.RUnlock()
+	return decoders[t]
+}
+
+// Unmarshal sets the fields of the struct v points to from the configuration, converting
+// values as Get does. A field reads the key named by its gconfig tag, or its name in
+// lowercase with '-' between words, eg: MaxSize reads max-size; a tag of "-" skips it.
+// Nested structs read the keys under the field key, eg: DB.Host reads db.host, and
+// embedded ones the keys of the embedding struct. Slices of structs read the repeated
+// groups under the field key, as GetStringMapSlice does, eg: Endpoints[1].URL reads
+// endpoints[1].url, and elements implementing Validator are validated; errors name the
+// key of the element, index included. Fields whose key isn't defined are left untouched,
+// so v can hold the defaults.
+func (c *GConfig) Unmarshal(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("Unmarshal needs a pointer to a struct but got %T", v)
+	}
+	return c.unmarshalStruct("", rv.Elem())
+}
+
+// unmarshalStruct sets the fields of rv from the keys under prefix.
+func (c *GConfig) unmarshalStruct(prefix string, rv reflect.Value) error {
+	t := rv.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		name := f.Tag.Get("gconfig")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			if err := c.unmarshalStruct(prefix, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = fieldKey(f.Name)
+		}
+
+		key := prefix + name
+		if f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.Struct && !decodable(f.Type.Elem()) {
+			if err := c.unmarshalSlice(key, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.Type.Kind() == reflect.Struct && !decodable(f.Type) {
+			if err := c.unmarshalStruct(key+".", fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if !c.Exists(key) {
+			if _, err := c.decodeIndexed(key, fv); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := decodeValue(c.GetString(key), fv); err != nil {
+			return typeError(key, fv.Type(), err)
+		}
+	}
+	return nil
+}
+
+// Validator is implemented by the structs checking their own fields once Unmarshal set
+// them, eg: that a timeout is positive.
+type Validator interface {
+	Validate() error
+}
+
+// unmarshalSlice sets the slice of structs rv from the repeated groups under key, leaving
+// it untouched if there are none.
+func (c *GConfig) unmarshalSlice(key string, rv reflect.Value) error {
+	var groups []string
+	var indexes []int
+	seen := make(map[int]bool)
+	for _, k := range c.KeysWithPrefix(key) {
+		i, field, ok := splitIndex(s.TrimPrefix(k, key))
+		if !ok || seen[i] {
+			continue
+		}
+		seen[i] = true
+		groups = append(groups, k[:len(k)-len(field)])
+		indexes = append(indexes, i)
+	}
+	if len(groups) == 0 {
+		return nil
+	}
+	sort.Sort(byIndex{indexes, groups})
+
+	sl := reflect.MakeSlice(rv.Type(), len(groups), len(groups))
+	for n, group := range groups {
+		elem := sl.Index(n)
+		if err := c.unmarshalStruct(group, elem); err != nil {
+			return err
+		}
+		if v, ok := elem.Addr().Interface().(Validator); ok {
+			if err := v.Validate(); err != nil {
+				return errors.Wrapf(err, "Invalid element %s", s.TrimSuffix(group, "."))
+			}
+		}
+	}
+	rv.Set(sl)
+	return nil
+}
+
+// byIndex sorts the key prefixes of repeated groups by their index.
+type byIndex struct {
+	indexes []int
+	groups  []string
+}
+
+func (b byIndex) Len() int           { return len(b.indexes) }
+func (b byIndex) Less(i, j int) bool { return b.indexes[i] < b.indexes[j] }
+func (b byIndex) Swap(i, j int) {
+	b.indexes[i], b.indexes[j] = b.indexes[j], b.indexes[i]
+	b.groups[i], b.groups[j] = b.groups[j], b.groups[i]
+}
+
+// decodable reports whether a struct type t is decoded from a single value rather than
+// from the keys under its field.
+func decodable(t reflect.Type) bool {
+	return decoder(t) != nil || reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+// fieldKey converts a field name to a key: lowercase words separated by '-', keeping
+// acronyms together, eg: MaxPoolSize reads max-pool-size and DBHost db-host.
+func fieldKey(name string) string {
+	rs := []rune(name)
+	var b s.Builder
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) && (!unicode.IsUpper(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1])) {
+			b.WriteByte('-')
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,159 @@
+package gconfig
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// registerDecoders registers the decoders of *url.URL and *net.IPNet until the end of
+// the test.
+func registerDecoders(t *testing.T) {
+	RegisterDecoder(url.Parse)
+	RegisterDecoder(func(v string) (*net.IPNet, error) {
+		_, n, err := net.ParseCIDR(v)
+		return n, err
+	})
+	t.Cleanup(func() {
+		decodersMu.Lock()
+		delete(decoders, reflect.TypeOf((*url.URL)(nil)))
+		delete(decoders, reflect.TypeOf((*net.IPNet)(nil)))
+		decodersMu.Unlock()
+	})
+}
+
+type dbConfig struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+}
+
+type common struct {
+	Name string `gconfig:"app.name"`
+}
+
+type appConfig struct {
+	common
+	DB          dbConfig
+	MaxPoolSize int
+	Endpoint    *url.URL   `gconfig:"api.endpoint"`
+	Allowed     *net.IPNet `gconfig:"api.allowed"`
+	Tags        []string
+	Region      string
+	Ignored     string `gconfig:"-"`
+	internal    string
+}
+
+func TestUnmarshal(t *testing.T) {
+	registerDecoders(t)
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\ndb.host=localhost\ndb.port=5432\ndb.timeout=5s\n"+
+		"max-pool-size=10\napi.endpoint=https://example.com/v1\napi.allowed=10.0.0.0/8\ntags=a,b\nignored=x\n")
+	gcg, err := Load(WithPath(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := appConfig{Region: "eu"}
+	if err := gcg.Unmarshal(&cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "gconfig" || cfg.DB.Host != "localhost" || cfg.DB.Port != 5432 || cfg.DB.Timeout != 5*time.Second {
+		t.Errorf("Unexpected values %+v", cfg)
+	}
+	if cfg.MaxPoolSize != 10 || len(cfg.Tags) != 2 || cfg.Region != "eu" || cfg.Ignored != "" {
+		t.Errorf("Unexpected values %+v", cfg)
+	}
+	if cfg.Endpoint == nil || cfg.Endpoint.Host != "example.com" {
+		t.Errorf("The registered URL decoder should be used but got %v", cfg.Endpoint)
+	}
+	if cfg.Allowed == nil || !cfg.Allowed.Contains(net.IPv4(10, 1, 2, 3)) {
+		t.Errorf("The registered CIDR decoder should be used but got %v", cfg.Allowed)
+	}
+	if n, err := Get[*net.IPNet](gcg, "api.allowed"); err != nil || n.String() != "10.0.0.0/8" {
+		t.Errorf("Get should use registered decoders but got %v, %v", n, err)
+	}
+
+	gcg.Set("db.port", "nope")
+	if err := gcg.Unmarshal(&cfg); err == nil || !strings.Contains(err.Error(), "db.port") {
+		t.Errorf("Unmarshal should fail naming the malformed key but got %v", err)
+	}
+	gcg.Set("db.port", "1")
+	gcg.Set("api.allowed", "10.0.0.0")
+	if err := gcg.Unmarshal(&cfg); err == nil || !strings.Contains(err.Error(), "api.allowed") {
+		t.Errorf("Decoder errors should name the key but got %v", err)
+	}
+	if err := gcg.Unmarshal(cfg); err == nil {
+		t.Error("Unmarshal should need a pointer")
+	}
+}
+
+func TestRegisterDecoderTwice(t *testing.T) {
+	registerDecoders(t)
+	defer func() {
+		if recover() == nil {
+			t.Error("Registering a type twice should panic")
+		}
+	}()
+	RegisterDecoder(url.Parse)
+}
+
+func TestFieldKey(t *testing.T) {
+	for name, key := range map[string]string{"Host": "host", "MaxPoolSize": "max-pool-size", "DBHost": "db-host", "URL": "url", "TTLSeconds": "ttl-seconds", "Port2": "port2"} {
+		if k := fieldKey(name); k != key {
+			t.Errorf("Field %s should read %s but got %s", name, key, k)
+		}
+	}
+}
+
+type endpoint struct {
+	URL     string
+	Timeout time.Duration
+	TLS     struct{ Verify bool }
+}
+
+func (e *endpoint) Validate() error {
+	if e.Timeout <= 0 {
+		return errors.New("timeout must be positive")
+	}
+	return nil
+}
+
+type endpointsConfig struct {
+	Endpoints []endpoint
+	Fallbacks []endpoint
+}
+
+func TestUnmarshalSlice(t *testing.T) {
+	cfg, err := NewFromMap(map[string]string{
+		"endpoints[2].url": "https://b", "endpoints[2].timeout": "2s",
+		"endpoints[0].url": "https://a", "endpoints[0].timeout": "1s", "endpoints[0].tls.verify": "true",
+		"fallbacks.0.url": "https://c", "fallbacks.0.timeout": "3s",
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ec endpointsConfig
+	if err := cfg.Unmarshal(&ec); err != nil {
+		t.Fatal(err)
+	}
+	if len(ec.Endpoints) != 2 || ec.Endpoints[0].URL != "https://a" || !ec.Endpoints[0].TLS.Verify || ec.Endpoints[1].Timeout != 2*time.Second {
+		t.Errorf("Unexpected endpoints %+v", ec.Endpoints)
+	}
+	if len(ec.Fallbacks) != 1 || ec.Fallbacks[0].URL != "https://c" {
+		t.Errorf("Unexpected fallbacks %+v", ec.Fallbacks)
+	}
+
+	cfg.Set("endpoints[2].timeout", "soon")
+	if err := cfg.Unmarshal(&ec); err == nil || !strings.Contains(err.Error(), "endpoints[2].timeout") {
+		t.Errorf("Expected an error naming endpoints[2].timeout but got %v", err)
+	}
+	cfg.Set("endpoints[2].timeout", "0s")
+	if err := cfg.Unmarshal(&ec); err == nil || !strings.Contains(err.Error(), "Invalid element endpoints[2]: timeout must be positive") {
+		t.Errorf("Expected a validation error naming endpoints[2] but got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package gconfig
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// waitBackoff bounds the delay between the attempts of WaitForLoad.
+var waitBackoff = struct{ initial, max time.Duration }{500 * time.Millisecond, 30 * time.Second}
+
+// WaitForLoad calls Load until it succeeds or ctx is done, doubling the delay between
+// attempts up to 30 seconds and logging every failure. It's meant for startup in
+// environments where the configuration shows up late, such as a ConfigMap volume being
+// mounted or a remote source coming up, instead of crash-looping until it's there.
+// Errors that retrying can't fix, a *ParseError, *ValidationError, *MissingKeysError,
+// *MissingEnvError or *ChecksumError, are returned right away. When ctx is done the last
+// load error is returned, wrapped.
+func WaitForLoad(ctx context.Context, opts ...Option) (*GConfig, error) {
+	o := newOptions(opts)
+	delay := waitBackoff.initial
+	for attempt := 1; ; attempt++ {
+		c, err := LoadContext(ctx, opts...)
+		if err == nil {
+			return c, nil
+		}
+		if permanent(err) {
+			return nil, err
+		}
+
+		o.logf("Configuration not available yet (attempt %d), retrying in %s: %s\n", attempt, delay, err)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, errors.Wrapf(err, "Configuration still not available after %d attempts: %s", attempt, ctx.Err())
+		case <-t.C:
+		}
+
+		if delay *= 2; delay > waitBackoff.max {
+			delay = waitBackoff.max
+		}
+	}
+}
+
+// permanent reports whether a load error is about the content of the configuration
+// rather than its availability.
+func permanent(err error) bool {
+	switch errors.Cause(err).(type) {
+	case *ParseError, *ValidationError, *MissingKeysError, *MissingEnvError, *ChecksumError:
+		return true
+	}
+	return errors.Cause(err) == ErrInvalidSignature
+}
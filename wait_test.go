@@ -0,0 +1,62 @@
+package gconfig
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWaitForLoad(t *testing.T) {
+	defer func(b struct{ initial, max time.Duration }) { waitBackoff = b }(waitBackoff)
+	waitBackoff.initial, waitBackoff.max = 10*time.Millisecond, 20*time.Millisecond
+
+	dir := t.TempDir()
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		os.WriteFile(filepath.Join(dir, StandardPropFileName), []byte("app.name=mounted\n"), 0644)
+	}()
+
+	l := &recordingLogger{}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	gcg, err := WaitForLoad(ctx, WithPath(dir), WithLogger(l))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := gcg.GetString("app.name"); n != "mounted" {
+		t.Errorf("Key app.name should be read once the file appears but was %s", n)
+	}
+	if len(l.lines) < 2 {
+		t.Errorf("Failed attempts should be logged but got %v", l.lines)
+	}
+}
+
+func TestWaitForLoadDeadline(t *testing.T) {
+	defer func(b struct{ initial, max time.Duration }) { waitBackoff = b }(waitBackoff)
+	waitBackoff.initial, waitBackoff.max = 10*time.Millisecond, 20*time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := WaitForLoad(ctx, WithPath(t.TempDir()), WithLogger(nil))
+	if err == nil {
+		t.Fatal("WaitForLoad should give up when the context is done")
+	}
+}
+
+func TestWaitForLoadPermanent(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig(t, dir, StandardPropFileName, "app.name=gconfig\n")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	start := time.Now()
+	_, err := WaitForLoad(ctx, WithPath(dir), StrictMode("db.url"), WithLogger(nil))
+	if _, ok := err.(*MissingKeysError); !ok {
+		t.Errorf("Missing keys should be returned right away but got %v", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Error("Permanent errors shouldn't be retried")
+	}
+}
@@ -0,0 +1,234 @@
+package gconfig
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Watcher is implemented by sources that push their changes as they happen, eg: a
+// key/value store with a watch API, instead of waiting for Reload. See WatchSources.
+type Watcher interface {
+	// Watch sends every change of the keys of the source to events until ctx is done or
+	// the subscription breaks, eg: on a network error, which it returns. Sources
+	// numbering their changes set Event.Seq, starting over at any value on each call but
+	// increasing by one per change, so that missed changes are detected.
+	Watch(ctx context.Context, events chan<- Event) error
+}
+
+// watchBackoff bounds the delay between the resubscriptions of a broken watch.
+var watchBackoff = struct{ initial, max time.Duration }{500 * time.Millisecond, 30 * time.Second}
+
+// WatchStatus describes the watch of a source started by WatchSources.
+type WatchStatus struct {
+	Source string
+	// Watching is false while the watch is broken and waiting to resubscribe.
+	Watching bool
+	// Resubscriptions counts the times the watch broke and was set up again.
+	Resubscriptions int
+	// MissedUpdates counts the changes skipped by the source, detected from gaps in
+	// their sequence numbers.
+	MissedUpdates uint64
+	// LastEvent is when the last change was received and LastError the error that last
+	// broke the watch.
+	LastEvent time.Time
+	LastError error
+}
+
+// watchState tracks the watch of a source, guarded by mu.
+type watchState struct {
+	mu sync.Mutex
+	WatchStatus
+}
+
+func (st *watchState) update(fn func(*WatchStatus)) {
+	st.mu.Lock()
+	fn(&st.WatchStatus)
+	st.mu.Unlock()
+}
+
+// WatchSources watches every source implementing Watcher, applying the changes they
+// push to the configuration and its subscribers until ctx is done. A watch that breaks
+// is set up again, waiting twice as long after each consecutive failure up to 30
+// seconds, and the source is loaded in full before resubscribing, as it is when a gap in
+// the sequence numbers of its changes shows some were missed, so no change is lost
+// either way. Breaks and missed changes are logged and counted by WatchStatus. Every
+// change received counts as a load for MaxStaleness, so Health reports a source whose
+// watch stopped delivering. Keys removed by a source are only looked up in the sources
+// registered before it on the next Reload. Changes pushed once the configuration is
+// frozen are dropped.
+func (c *GConfig) WatchSources(ctx context.Context) {
+	c = c.base()
+	if c.opts == nil {
+		return
+	}
+	for _, src := range c.opts.sources {
+		w, ok := src.(Watcher)
+		if !ok {
+			continue
+		}
+		st := &watchState{WatchStatus: WatchStatus{Source: src.Name()}}
+		c.watchMu.Lock()
+		c.watches = append(c.watches, st)
+		c.watchMu.Unlock()
+		go c.watch(ctx, src, w, st)
+	}
+}
+
+// WatchStatus returns the status of the watches started by WatchSources, sorted by
+// source name.
+func (c *GConfig) WatchStatus() []WatchStatus {
+	c = c.base()
+	c.watchMu.Lock()
+	defer c.watchMu.Unlock()
+	statuses := make([]WatchStatus, len(c.watches))
+	for i, st := range c.watches {
+		st.mu.Lock()
+		statuses[i] = st.WatchStatus
+		st.mu.Unlock()
+	}
+	sort.SliceStable(statuses, func(i, j int) bool { return statuses[i].Source < statuses[j].Source })
+	return statuses
+}
+
+// watch runs the watch of src until ctx is done, resubscribing when it breaks.
+func (c *GConfig) watch(ctx context.Context, src Source, w Watcher, st *watchState) {
+	delay := watchBackoff.initial
+	for {
+		st.update(func(ws *WatchStatus) { ws.Watching = true })
+		received, err := c.watchOnce(ctx, src, w, st)
+		if ctx.Err() != nil {
+			st.update(func(ws *WatchStatus) { ws.Watching = false })
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("Watch ended")
+		}
+		if received {
+			delay = watchBackoff.initial
+		}
+		st.update(func(ws *WatchStatus) { ws.Watching, ws.LastError = false, err })
+		c.opts.logf("Watch of source %s broke, resubscribing in %s: %s\n", src.Name(), delay, err)
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return
+		case <-t.C:
+		}
+		if delay *= 2; delay > watchBackoff.max {
+			delay = watchBackoff.max
+		}
+
+		if err := c.resyncSource(ctx, src); err != nil {
+			c.opts.logf("Error loading source %s before resubscribing: %s\n", src.Name(), err)
+		}
+		st.update(func(ws *WatchStatus) { ws.Resubscriptions++ })
+	}
+}
+
+// watchOnce applies the changes pushed by a single call to w.Watch, returning its error
+// and whether any change was received.
+func (c *GConfig) watchOnce(ctx context.Context, src Source, w Watcher, st *watchState) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	events := make(chan Event)
+	done := make(chan error, 1)
+	go func() { done <- w.Watch(ctx, events) }()
+
+	var last uint64
+	received := false
+	for {
+		select {
+		case err := <-done:
+			return received, err
+		case <-ctx.Done():
+			return received, ctx.Err()
+		case e := <-events:
+			received = true
+			st.update(func(ws *WatchStatus) { ws.LastEvent = time.Now() })
+			if e.Seq != 0 && last != 0 {
+				if e.Seq <= last {
+					continue
+				}
+				if missed := e.Seq - last - 1; missed > 0 {
+					st.update(func(ws *WatchStatus) { ws.MissedUpdates += missed })
+					c.opts.logf("Source %s missed %d updates, loading it in full\n", src.Name(), missed)
+					last = e.Seq
+					if err := c.resyncSource(ctx, src); err != nil {
+						return received, err
+					}
+					continue
+				}
+			}
+			last = e.Seq
+			if e.Removed {
+				c.updateSource(src.Name(), nil, []string{e.Key}, false)
+			} else {
+				c.updateSource(src.Name(), map[string]string{e.Key: e.New}, nil, false)
+			}
+		}
+	}
+}
+
+// resyncSource loads src in full and publishes its values.
+func (c *GConfig) resyncSource(ctx context.Context, src Source) error {
+	kv, err := loadSource(ctx, src, c.opts.timeoutFor(src.Name()))
+	if err != nil {
+		return err
+	}
+	c.updateSource(src.Name(), kv, nil, true)
+	return nil
+}
+
+// updateSource publishes a snapshot where the source name defines the keys of kv and no
+// longer defines the removed keys, or, if full, only the keys of kv. Keys defined by a
+// source registered after name keep their value.
+func (c *GConfig) updateSource(name string, kv map[string]string, removed []string, full bool) {
+	c.mu.Lock()
+	old := c.v
+	if old.frozen {
+		c.mu.Unlock()
+		return
+	}
+	nv := old.clone()
+	nv.sourced = make(map[string]string, len(old.sourced)+len(kv))
+	nv.sourcedFrom = make(map[string]string, len(old.sourcedFrom)+len(kv))
+	for k, v := range old.sourced {
+		if from := old.sourcedFrom[k]; !full || from != name {
+			nv.sourced[k], nv.sourcedFrom[k] = v, from
+		}
+	}
+	for _, k := range removed {
+		if nv.sourcedFrom[k] == name {
+			delete(nv.sourced, k)
+			delete(nv.sourcedFrom, k)
+		}
+	}
+	rank := c.opts.sourceRank(name)
+	for k, v := range kv {
+		if from, ok := nv.sourcedFrom[k]; ok && c.opts.sourceRank(from) > rank {
+			continue
+		}
+		nv.sourced[k], nv.sourcedFrom[k] = v, name
+	}
+	nv.sources[name] = time.Now()
+	c.v = nv.seal()
+	c.mu.Unlock()
+
+	c.changed(AuditReload, old, nv)
+}
+
+// sourceRank returns the precedence of the source named name, the sources registered
+// later and the values of WithChaos ranking higher.
+func (o *options) sourceRank(name string) int {
+	for i, src := range o.sources {
+		if src.Name() == name {
+			return i
+		}
+	}
+	return len(o.sources)
+}
@@ -0,0 +1,160 @@
+package gconfig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchSource is a Source whose Watch calls each serve the next stream of streams,
+// returning an error once it's closed.
+type watchSource struct {
+	mu      sync.Mutex
+	values  map[string]string
+	loads   int
+	streams chan chan Event
+}
+
+func (w *watchSource) Name() string { return "watched" }
+
+func (w *watchSource) Load(ctx context.Context) (map[string]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.loads++
+	kv := make(map[string]string, len(w.values))
+	for k, v := range w.values {
+		kv[k] = v
+	}
+	return kv, nil
+}
+
+func (w *watchSource) set(key, value string) {
+	w.mu.Lock()
+	w.values[key] = value
+	w.mu.Unlock()
+}
+
+func (w *watchSource) Watch(ctx context.Context, events chan<- Event) error {
+	var stream chan Event
+	select {
+	case stream = <-w.streams:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	for {
+		select {
+		case e, ok := <-stream:
+			if !ok {
+				return errors.New("connection reset")
+			}
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// eventually fails the test if cond doesn't hold within a second.
+func eventually(t *testing.T, msg string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+	}
+}
+
+func TestWatchSources(t *testing.T) {
+	defer func(b struct{ initial, max time.Duration }) { watchBackoff = b }(watchBackoff)
+	watchBackoff.initial, watchBackoff.max = 10*time.Millisecond, 20*time.Millisecond
+
+	src := &watchSource{values: map[string]string{"pool.size": "4", "pool.idle": "2"}, streams: make(chan chan Event)}
+	gcg, err := Load(WithoutFlags(), WithoutEnv(), EnvOnly(""), WithSource(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gcg.WatchSources(ctx)
+
+	var mu sync.Mutex
+	var changes []Event
+	gcg.Subscribe("pool", func(e Event) {
+		mu.Lock()
+		changes = append(changes, e)
+		mu.Unlock()
+	})
+
+	stream := make(chan Event)
+	src.streams <- stream
+	stream <- Event{Key: "pool.size", New: "8", Seq: 1}
+	stream <- Event{Key: "pool.idle", Removed: true, Seq: 2}
+	eventually(t, "Watched changes should be applied", func() bool {
+		return gcg.GetString("pool.size") == "8" && !gcg.Exists("pool.idle")
+	})
+	mu.Lock()
+	if len(changes) != 2 {
+		t.Errorf("Subscribers should be notified of watched changes but got %v", changes)
+	}
+	mu.Unlock()
+
+	// a gap in the sequence numbers reloads the source in full
+	src.set("pool.size", "16")
+	src.set("pool.max", "32")
+	stream <- Event{Key: "pool.max", New: "32", Seq: 5}
+	eventually(t, "Missed changes should be recovered by loading the source", func() bool {
+		return gcg.GetString("pool.size") == "16"
+	})
+	if st := gcg.WatchStatus(); len(st) != 1 || st[0].MissedUpdates != 2 || !st[0].Watching {
+		t.Errorf("Expected 2 missed updates on an active watch but got %+v", st)
+	}
+
+	// a broken watch is resubscribed after loading the source again
+	src.set("pool.size", "64")
+	close(stream)
+	stream = make(chan Event)
+	src.streams <- stream
+	if s := gcg.GetString("pool.size"); s != "64" {
+		t.Errorf("Changes made while the watch was broken should be loaded but pool.size was %s", s)
+	}
+	stream <- Event{Key: "pool.size", New: "128", Seq: 1}
+	eventually(t, "Changes should be applied after resubscribing", func() bool {
+		return gcg.GetString("pool.size") == "128"
+	})
+	st := gcg.WatchStatus()[0]
+	if st.Resubscriptions != 1 || st.LastError == nil || st.MissedUpdates != 2 {
+		t.Errorf("Expected one resubscription after an error but got %+v", st)
+	}
+
+	cancel()
+	eventually(t, "The watch should stop with its context", func() bool {
+		return !gcg.WatchStatus()[0].Watching
+	})
+}
+
+func TestWatchSourcesPrecedence(t *testing.T) {
+	src := &watchSource{values: map[string]string{}, streams: make(chan chan Event)}
+	last := &mapSource{name: "last", values: map[string]string{"app.name": "last"}}
+	gcg, err := Load(WithoutFlags(), WithoutEnv(), EnvOnly(""), WithSource(src), WithSource(last))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	gcg.WatchSources(ctx)
+
+	stream := make(chan Event)
+	src.streams <- stream
+	stream <- Event{Key: "app.name", New: "watched"}
+	stream <- Event{Key: "app.port", New: "8080"}
+	eventually(t, "Watched changes should be applied", func() bool { return gcg.GetInt("app.port") == 8080 })
+	if n := gcg.GetString("app.name"); n != "last" {
+		t.Errorf("A source registered later should win over watched changes but app.name was %s", n)
+	}
+}